@@ -2,13 +2,14 @@
 // Licensed under the GNU Affero General Public License (AGPL).
 // See License-AGPL.txt in the project root for license information.
 
-//go:generate ./generate-mock.sh
+//go:generate go run ./generator
 
 package protocol
 
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -22,188 +23,11 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// APIInterface wraps the
-type APIInterface interface {
-	AdminBlockUser(ctx context.Context, req *AdminBlockUserRequest) (err error)
-	GetLoggedInUser(ctx context.Context) (res *User, err error)
-	UpdateLoggedInUser(ctx context.Context, user *User) (res *User, err error)
-	GetAuthProviders(ctx context.Context) (res []*AuthProviderInfo, err error)
-	GetOwnAuthProviders(ctx context.Context) (res []*AuthProviderEntry, err error)
-	UpdateOwnAuthProvider(ctx context.Context, params *UpdateOwnAuthProviderParams) (err error)
-	DeleteOwnAuthProvider(ctx context.Context, params *DeleteOwnAuthProviderParams) (err error)
-	GetBranding(ctx context.Context) (res *Branding, err error)
-	GetConfiguration(ctx context.Context) (res *Configuration, err error)
-	GetBhojpurTokenScopes(ctx context.Context, tokenHash string) (res []string, err error)
-	GetToken(ctx context.Context, query *GetTokenSearchOptions) (res *Token, err error)
-	GetPortAuthenticationToken(ctx context.Context, applicationID string) (res *Token, err error)
-	DeleteAccount(ctx context.Context) (err error)
-	GetClientRegion(ctx context.Context) (res string, err error)
-	HasPermission(ctx context.Context, permission *PermissionName) (res bool, err error)
-	GetApplications(ctx context.Context, options *GetApplicationsOptions) (res []*ApplicationInfo, err error)
-	GetApplicationOwner(ctx context.Context, applicationID string) (res *UserInfo, err error)
-	GetApplicationUsers(ctx context.Context, applicationID string) (res []*ApplicationInstanceUser, err error)
-	GetFeaturedRepositories(ctx context.Context) (res []*WhitelistedRepository, err error)
-	GetApplication(ctx context.Context, id string) (res *ApplicationInfo, err error)
-	IsApplicationOwner(ctx context.Context, applicationID string) (res bool, err error)
-	CreateApplication(ctx context.Context, options *CreateApplicationOptions) (res *ApplicationCreationResult, err error)
-	StartApplication(ctx context.Context, id string, options *StartApplicationOptions) (res *StartApplicationResult, err error)
-	StopApplication(ctx context.Context, id string) (err error)
-	DeleteApplication(ctx context.Context, id string) (err error)
-	SetApplicationDescription(ctx context.Context, id string, desc string) (err error)
-	ControlAdmission(ctx context.Context, id string, level *AdmissionLevel) (err error)
-	UpdateApplicationUserPin(ctx context.Context, id string, action *PinAction) (err error)
-	SendHeartBeat(ctx context.Context, options *SendHeartBeatOptions) (err error)
-	WatchApplicationImageBuildLogs(ctx context.Context, applicationID string) (err error)
-	IsPrebuildDone(ctx context.Context, pwsid string) (res bool, err error)
-	SetApplicationTimeout(ctx context.Context, applicationID string, duration *ApplicationTimeoutDuration) (res *SetApplicationTimeoutResult, err error)
-	GetApplicationTimeout(ctx context.Context, applicationID string) (res *GetApplicationTimeoutResult, err error)
-	GetOpenPorts(ctx context.Context, applicationID string) (res []*ApplicationInstancePort, err error)
-	OpenPort(ctx context.Context, applicationID string, port *ApplicationInstancePort) (res *ApplicationInstancePort, err error)
-	ClosePort(ctx context.Context, applicationID string, port float32) (err error)
-	GetUserStorageResource(ctx context.Context, options *GetUserStorageResourceOptions) (res string, err error)
-	UpdateUserStorageResource(ctx context.Context, options *UpdateUserStorageResourceOptions) (err error)
-	GetEnvVars(ctx context.Context) (res []*UserEnvVarValue, err error)
-	SetEnvVar(ctx context.Context, variable *UserEnvVarValue) (err error)
-	DeleteEnvVar(ctx context.Context, variable *UserEnvVarValue) (err error)
-	GetContentBlobUploadURL(ctx context.Context, name string) (url string, err error)
-	GetContentBlobDownloadURL(ctx context.Context, name string) (url string, err error)
-	GetBhojpurTokens(ctx context.Context) (res []*APIToken, err error)
-	GenerateNewBhojpurToken(ctx context.Context, options *GenerateNewBhojpurTokenOptions) (res string, err error)
-	DeleteBhojpurToken(ctx context.Context, tokenHash string) (err error)
-	SendFeedback(ctx context.Context, feedback string) (res string, err error)
-	RegisterGithubApp(ctx context.Context, installationID string) (err error)
-	TakeSnapshot(ctx context.Context, options *TakeSnapshotOptions) (res string, err error)
-	WaitForSnapshot(ctx context.Context, snapshotId string) (err error)
-	GetSnapshots(ctx context.Context, applicationID string) (res []*string, err error)
-	StoreLayout(ctx context.Context, applicationID string, layoutData string) (err error)
-	GetLayout(ctx context.Context, applicationID string) (res string, err error)
-	PreparePluginUpload(ctx context.Context, params *PreparePluginUploadParams) (res string, err error)
-	ResolvePlugins(ctx context.Context, applicationID string, params *ResolvePluginsParams) (res *ResolvedPlugins, err error)
-	InstallUserPlugins(ctx context.Context, params *InstallPluginsParams) (res bool, err error)
-	UninstallUserPlugin(ctx context.Context, params *UninstallPluginParams) (res bool, err error)
-	GuessGitTokenScopes(ctx context.Context, params *GuessGitTokenScopesParams) (res *GuessedGitTokenScopes, err error)
-
-	InstanceUpdates(ctx context.Context, instanceID string) (<-chan *ApplicationInstance, error)
-}
-
-// FunctionName is the name of an RPC function
-type FunctionName string
-
 const (
-	// FunctionAdminBlockUser is the name of the adminBlockUser function
-	FunctionAdminBlockUser FunctionName = "adminBlockUser"
-	// FunctionGetLoggedInUser is the name of the getLoggedInUser function
-	FunctionGetLoggedInUser FunctionName = "getLoggedInUser"
-	// FunctionUpdateLoggedInUser is the name of the updateLoggedInUser function
-	FunctionUpdateLoggedInUser FunctionName = "updateLoggedInUser"
-	// FunctionGetAuthProviders is the name of the getAuthProviders function
-	FunctionGetAuthProviders FunctionName = "getAuthProviders"
-	// FunctionGetOwnAuthProviders is the name of the getOwnAuthProviders function
-	FunctionGetOwnAuthProviders FunctionName = "getOwnAuthProviders"
-	// FunctionUpdateOwnAuthProvider is the name of the updateOwnAuthProvider function
-	FunctionUpdateOwnAuthProvider FunctionName = "updateOwnAuthProvider"
-	// FunctionDeleteOwnAuthProvider is the name of the deleteOwnAuthProvider function
-	FunctionDeleteOwnAuthProvider FunctionName = "deleteOwnAuthProvider"
-	// FunctionGetBranding is the name of the getBranding function
-	FunctionGetBranding FunctionName = "getBranding"
-	// FunctionGetConfiguration is the name of the getConfiguration function
-	FunctionGetConfiguration FunctionName = "getConfiguration"
-	// FunctionGetBhojpurTokenScopes is the name of the GetBhojpurTokenScopes function
-	FunctionGetBhojpurTokenScopes FunctionName = "getBhojpurTokenScopes"
-	// FunctionGetToken is the name of the getToken function
-	FunctionGetToken FunctionName = "getToken"
-	// FunctionGetPortAuthenticationToken is the name of the getPortAuthenticationToken function
-	FunctionGetPortAuthenticationToken FunctionName = "getPortAuthenticationToken"
-	// FunctionDeleteAccount is the name of the deleteAccount function
-	FunctionDeleteAccount FunctionName = "deleteAccount"
-	// FunctionGetClientRegion is the name of the getClientRegion function
-	FunctionGetClientRegion FunctionName = "getClientRegion"
-	// FunctionHasPermission is the name of the hasPermission function
-	FunctionHasPermission FunctionName = "hasPermission"
-	// FunctionGetApplications is the name of the getApplications function
-	FunctionGetApplications FunctionName = "getApplications"
-	// FunctionGetApplicationOwner is the name of the getApplicationOwner function
-	FunctionGetApplicationOwner FunctionName = "getApplicationOwner"
-	// FunctionGetApplicationUsers is the name of the getApplicationUsers function
-	FunctionGetApplicationUsers FunctionName = "getApplicationUsers"
-	// FunctionGetFeaturedRepositories is the name of the getFeaturedRepositories function
-	FunctionGetFeaturedRepositories FunctionName = "getFeaturedRepositories"
-	// FunctionGetApplication is the name of the getApplication function
-	FunctionGetApplication FunctionName = "getApplication"
-	// FunctionIsApplicationOwner is the name of the isApplicationOwner function
-	FunctionIsApplicationOwner FunctionName = "isApplicationOwner"
-	// FunctionCreateApplication is the name of the createApplication function
-	FunctionCreateApplication FunctionName = "createApplication"
-	// FunctionStartApplication is the name of the startApplication function
-	FunctionStartApplication FunctionName = "startApplication"
-	// FunctionStopApplication is the name of the stopApplication function
-	FunctionStopApplication FunctionName = "stopApplication"
-	// FunctionDeleteApplication is the name of the deleteApplication function
-	FunctionDeleteApplication FunctionName = "deleteApplication"
-	// FunctionSetApplicationDescription is the name of the setApplicationDescription function
-	FunctionSetApplicationDescription FunctionName = "setApplicationDescription"
-	// FunctionControlAdmission is the name of the controlAdmission function
-	FunctionControlAdmission FunctionName = "controlAdmission"
-	// FunctionUpdateApplicationUserPin is the name of the updateApplicationUserPin function
-	FunctionUpdateApplicationUserPin FunctionName = "updateApplicationUserPin"
-	// FunctionSendHeartBeat is the name of the sendHeartBeat function
-	FunctionSendHeartBeat FunctionName = "sendHeartBeat"
-	// FunctionWatchApplicationImageBuildLogs is the name of the watchApplicationImageBuildLogs function
-	FunctionWatchApplicationImageBuildLogs FunctionName = "watchApplicationImageBuildLogs"
-	// FunctionIsPrebuildDone is the name of the isPrebuildDone function
-	FunctionIsPrebuildDone FunctionName = "isPrebuildDone"
-	// FunctionSetApplicationTimeout is the name of the setApplicationTimeout function
-	FunctionSetApplicationTimeout FunctionName = "setApplicationTimeout"
-	// FunctionGetApplicationTimeout is the name of the getApplicationTimeout function
-	FunctionGetApplicationTimeout FunctionName = "getApplicationTimeout"
-	// FunctionGetOpenPorts is the name of the getOpenPorts function
-	FunctionGetOpenPorts FunctionName = "getOpenPorts"
-	// FunctionOpenPort is the name of the openPort function
-	FunctionOpenPort FunctionName = "openPort"
-	// FunctionClosePort is the name of the closePort function
-	FunctionClosePort FunctionName = "closePort"
-	// FunctionGetUserStorageResource is the name of the getUserStorageResource function
-	FunctionGetUserStorageResource FunctionName = "getUserStorageResource"
-	// FunctionUpdateUserStorageResource is the name of the updateUserStorageResource function
-	FunctionUpdateUserStorageResource FunctionName = "updateUserStorageResource"
-	// FunctionGetEnvVars is the name of the getEnvVars function
-	FunctionGetEnvVars FunctionName = "getEnvVars"
-	// FunctionSetEnvVar is the name of the setEnvVar function
-	FunctionSetEnvVar FunctionName = "setEnvVar"
-	// FunctionDeleteEnvVar is the name of the deleteEnvVar function
-	FunctionDeleteEnvVar FunctionName = "deleteEnvVar"
-	// FunctionGetContentBlobUploadURL is the name fo the getContentBlobUploadUrl function
-	FunctionGetContentBlobUploadURL FunctionName = "getContentBlobUploadUrl"
-	// FunctionGetContentBlobDownloadURL is the name fo the getContentBlobDownloadUrl function
-	FunctionGetContentBlobDownloadURL FunctionName = "getContentBlobDownloadUrl"
-	// FunctionGetBhojpurTokens is the name of the getBhojpurTokens function
-	FunctionGetBhojpurTokens FunctionName = "getBhojpurTokens"
-	// FunctionGenerateNewBhojpurToken is the name of the generateNewBhojpurToken function
-	FunctionGenerateNewBhojpurToken FunctionName = "generateNewBhojpurToken"
-	// FunctionDeleteBhojpurToken is the name of the deleteBhojpurToken function
-	FunctionDeleteBhojpurToken FunctionName = "deleteBhojpurToken"
-	// FunctionSendFeedback is the name of the sendFeedback function
-	FunctionSendFeedback FunctionName = "sendFeedback"
-	// FunctionRegisterGithubApp is the name of the registerGithubApp function
-	FunctionRegisterGithubApp FunctionName = "registerGithubApp"
-	// FunctionTakeSnapshot is the name of the takeSnapshot function
-	FunctionTakeSnapshot FunctionName = "takeSnapshot"
-	// FunctionGetSnapshots is the name of the getSnapshots function
-	FunctionGetSnapshots FunctionName = "getSnapshots"
-	// FunctionStoreLayout is the name of the storeLayout function
-	FunctionStoreLayout FunctionName = "storeLayout"
-	// FunctionGetLayout is the name of the getLayout function
-	FunctionGetLayout FunctionName = "getLayout"
-	// FunctionPreparePluginUpload is the name of the preparePluginUpload function
-	FunctionPreparePluginUpload FunctionName = "preparePluginUpload"
-	// FunctionResolvePlugins is the name of the resolvePlugins function
-	FunctionResolvePlugins FunctionName = "resolvePlugins"
-	// FunctionInstallUserPlugins is the name of the installUserPlugins function
-	FunctionInstallUserPlugins FunctionName = "installUserPlugins"
-	// FunctionUninstallUserPlugin is the name of the uninstallUserPlugin function
-	FunctionUninstallUserPlugin FunctionName = "uninstallUserPlugin"
-	// FunctionGuessGitTokenScopes is the name of the guessGitTokenScopes function
-	FunctionGuessGitTokenScope FunctionName = "guessGitTokenScopes"
+	// FunctionGuessGitTokenScope is the name of the guessGitTokenScopes function
+	//
+	// Deprecated: use FunctionGuessGitTokenScopes
+	FunctionGuessGitTokenScope = FunctionGuessGitTokenScopes
 
 	// FunctionOnInstanceUpdate is the name of the onInstanceUpdate callback function
 	FunctionOnInstanceUpdate = "onInstanceUpdate"
@@ -211,17 +35,36 @@ const (
 
 var errNotConnected = errors.New("not connected to Bhojpur server")
 
+// instanceUpdatesBuffer is the number of updates InstanceUpdates buffers for slow readers
+const instanceUpdatesBuffer = 16
+
 // ConnectToServerOpts configures the server connection
 type ConnectToServerOpts struct {
-	Context             context.Context
-	Token               string
-	Log                 *logrus.Entry
+	Context context.Context
+	Token   string
+	// Credentials provide the token on every (re)connect. Token is used as a static token if not set.
+	Credentials CredentialProvider
+	Log         *logrus.Entry
+	// ReconnectionHandler is called after a reconnect, once the subscriptions are restored
 	ReconnectionHandler func()
 	CloseHandler        func(error)
 	ExtraHeaders        map[string]string
-}
-
-// ConnectToServer establishes a new websocket connection to the server
+	// Interceptors wrap every call to the server, the first one is the outermost
+	Interceptors []Interceptor
+	// Transport opens the connections to the server. Defaults to a websocket
+	// connection configured by TLSConfig and Proxy.
+	Transport Transport
+	// TLSConfig configures TLS for wss endpoints, e.g. a CA bundle or client certificates, see LoadTLSConfig
+	TLSConfig *tls.Config
+	// Proxy returns the proxy for a request. Defaults to the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy func(*http.Request) (*url.URL, error)
+	// ReconnectPolicy configures the delays between connection attempts, zero fields take their default
+	ReconnectPolicy ReconnectPolicy
+	// Websocket configures the ping/pong intervals and message size limits, zero fields take their default
+	Websocket WebsocketOptions
+}
+
+// ConnectToServer establishes a new connection to the server
 func ConnectToServer(endpoint string, opts ConnectToServerOpts) (*APIoverJSONRPC, error) {
 	if opts.Context == nil {
 		opts.Context = context.Background()
@@ -232,24 +75,33 @@ func ConnectToServer(endpoint string, opts ConnectToServerOpts) (*APIoverJSONRPC
 		return nil, xerrors.Errorf("invalid endpoint URL: %w", err)
 	}
 
-	var protocol string
-	if epURL.Scheme == "wss:" {
-		protocol = "https"
-	} else {
-		protocol = "http"
-	}
-	origin := fmt.Sprintf("%s://%s/", protocol, epURL.Hostname())
-
 	reqHeader := http.Header{}
-	reqHeader.Set("Origin", origin)
+	reqHeader.Set("Origin", originForEndpoint(epURL))
 	for k, v := range opts.ExtraHeaders {
 		reqHeader.Set(k, v)
 	}
-	if opts.Token != "" {
-		reqHeader.Set("Authorization", "Bearer "+opts.Token)
+	if opts.Credentials == nil && opts.Token != "" {
+		opts.Credentials = StaticToken(opts.Token)
 	}
+	var res APIoverJSONRPC
+	res.log = opts.Log
+
 	ws := NewReconnectingWebsocket(endpoint, reqHeader, opts.Log)
-	ws.ReconnectionHandler = opts.ReconnectionHandler
+	ws.Transport = opts.Transport
+	ws.Credentials = opts.Credentials
+	ws.Policy = opts.ReconnectPolicy
+	ws.ConnectionOptions = opts.Websocket
+	res.ws = ws
+	if ws.Transport == nil {
+		ws.Transport = NewWebsocketTransport(opts.TLSConfig, opts.Proxy)
+	}
+	ws.ReconnectionHandler = func() {
+		res.onReconnect()
+		res.resubscribe(opts.Context)
+		if opts.ReconnectionHandler != nil {
+			opts.ReconnectionHandler()
+		}
+	}
 	go func() {
 		err := ws.Dial(opts.Context)
 		if opts.CloseHandler != nil {
@@ -257,9 +109,10 @@ func ConnectToServer(endpoint string, opts ConnectToServerOpts) (*APIoverJSONRPC
 		}
 	}()
 
-	var res APIoverJSONRPC
-	res.log = opts.Log
 	res.C = jsonrpc2.NewConn(opts.Context, ws, jsonrpc2.HandlerWithError(res.handler))
+	if len(opts.Interceptors) > 0 {
+		res.invoker = chainInterceptors(res.invoke, opts.Interceptors)
+	}
 	return &res, nil
 }
 
@@ -267,9 +120,21 @@ func ConnectToServer(endpoint string, opts ConnectToServerOpts) (*APIoverJSONRPC
 type APIoverJSONRPC struct {
 	C   jsonrpc2.JSONRPC2
 	log *logrus.Entry
+	// ws is the connection underneath C, if we opened it
+	ws *ReconnectingWebsocket
 
-	mu   sync.RWMutex
-	subs map[string]map[chan *ApplicationInstance]struct{}
+	// invoker calls the server through the interceptors, if there are any
+	invoker Invoker
+
+	mu          sync.RWMutex
+	subs        map[string]map[*subscription]struct{}
+	watcher     *ApplicationWatcher
+	reconnected chan struct{}
+
+	// instanceApps are the applications of the instances we received updates for
+	instanceApps map[string]string
+	// listeners are the calls which registered listeners on the server
+	listeners map[string]listener
 }
 
 // Close closes the connection
@@ -285,22 +150,48 @@ func (bp *APIoverJSONRPC) Close() (err error) {
 	return nil
 }
 
+// ConnectionState returns the current state of the connection to the server. Clients
+// created without ConnectToServer always report being connected.
+func (bp *APIoverJSONRPC) ConnectionState() ConnectionStatus {
+	if bp.ws == nil {
+		return ConnectionStatus{State: StateConnected}
+	}
+	return bp.ws.State()
+}
+
+// ConnectionStates streams the state of the connection to the server, e.g. for health checks.
+// Slow readers only get the latest state. The channel is closed once the connection is closed
+// or ctx is done.
+func (bp *APIoverJSONRPC) ConnectionStates(ctx context.Context) <-chan ConnectionStatus {
+	if bp.ws == nil {
+		res := make(chan ConnectionStatus, 1)
+		res <- ConnectionStatus{State: StateConnected}
+		go func() {
+			<-ctx.Done()
+			close(res)
+		}()
+		return res
+	}
+	return bp.ws.States(ctx)
+}
+
 // InstanceUpdates subscribes to application instance updates until the context is canceled or the application
-// instance is stopped.
+// instance is stopped. Updates are dropped if the reader falls more than instanceUpdatesBuffer updates behind,
+// use the Watcher for updates which must not be missed. The snapshots sent after a reconnect are never dropped.
 func (bp *APIoverJSONRPC) InstanceUpdates(ctx context.Context, instanceID string) (<-chan *ApplicationInstance, error) {
 	if bp == nil {
 		return nil, errNotConnected
 	}
-	chn := make(chan *ApplicationInstance)
+	sub := newSubscription(ctx)
 
 	bp.mu.Lock()
 	if bp.subs == nil {
-		bp.subs = make(map[string]map[chan *ApplicationInstance]struct{})
+		bp.subs = make(map[string]map[*subscription]struct{})
 	}
-	if sub, ok := bp.subs[instanceID]; ok {
-		sub[chn] = struct{}{}
+	if subs, ok := bp.subs[instanceID]; ok {
+		subs[sub] = struct{}{}
 	} else {
-		bp.subs[instanceID] = map[chan *ApplicationInstance]struct{}{chn: {}}
+		bp.subs[instanceID] = map[*subscription]struct{}{sub: {}}
 	}
 	bp.mu.Unlock()
 
@@ -308,12 +199,11 @@ func (bp *APIoverJSONRPC) InstanceUpdates(ctx context.Context, instanceID string
 		<-ctx.Done()
 
 		bp.mu.Lock()
-		delete(bp.subs[instanceID], chn)
-		close(chn)
+		delete(bp.subs[instanceID], sub)
 		bp.mu.Unlock()
 	}()
 
-	return chn, nil
+	return sub.chn, nil
 }
 
 func (bp *APIoverJSONRPC) handler(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result interface{}, err error) {
@@ -332,1129 +222,53 @@ func (bp *APIoverJSONRPC) handler(ctx context.Context, conn *jsonrpc2.Conn, req
 		return
 	}
 
-	bp.mu.RLock()
-	defer bp.mu.RUnlock()
-	for chn := range bp.subs[instance.ID] {
-		select {
-		case chn <- &instance:
-		default:
-		}
-	}
-	for chn := range bp.subs[""] {
-		select {
-		case chn <- &instance:
-		default:
-		}
-	}
-
-	return
-}
-
-// AdminBlockUser calls adminBlockUser on the server
-func (bp *APIoverJSONRPC) AdminBlockUser(ctx context.Context, message *AdminBlockUserRequest) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-	_params = append(_params, message)
-
-	var _result interface{}
-	err = bp.C.Call(ctx, "adminBlockUser", _params, &_result)
-	if err != nil {
-		return err
-	}
-	return
-}
-
-// GetLoggedInUser calls getLoggedInUser on the server
-func (bp *APIoverJSONRPC) GetLoggedInUser(ctx context.Context) (res *User, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	var result User
-	err = bp.C.Call(ctx, "getLoggedInUser", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
-}
-
-// UpdateLoggedInUser calls updateLoggedInUser on the server
-func (bp *APIoverJSONRPC) UpdateLoggedInUser(ctx context.Context, user *User) (res *User, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, user)
-
-	var result User
-	err = bp.C.Call(ctx, "updateLoggedInUser", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
-}
-
-// GetAuthProviders calls getAuthProviders on the server
-func (bp *APIoverJSONRPC) GetAuthProviders(ctx context.Context) (res []*AuthProviderInfo, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	var result []*AuthProviderInfo
-	err = bp.C.Call(ctx, "getAuthProviders", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// GetOwnAuthProviders calls getOwnAuthProviders on the server
-func (bp *APIoverJSONRPC) GetOwnAuthProviders(ctx context.Context) (res []*AuthProviderEntry, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	var result []*AuthProviderEntry
-	err = bp.C.Call(ctx, "getOwnAuthProviders", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// UpdateOwnAuthProvider calls updateOwnAuthProvider on the server
-func (bp *APIoverJSONRPC) UpdateOwnAuthProvider(ctx context.Context, params *UpdateOwnAuthProviderParams) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, params)
-
-	err = bp.C.Call(ctx, "updateOwnAuthProvider", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// DeleteOwnAuthProvider calls deleteOwnAuthProvider on the server
-func (bp *APIoverJSONRPC) DeleteOwnAuthProvider(ctx context.Context, params *DeleteOwnAuthProviderParams) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, params)
-
-	err = bp.C.Call(ctx, "deleteOwnAuthProvider", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// GetBranding calls getBranding on the server
-func (bp *APIoverJSONRPC) GetBranding(ctx context.Context) (res *Branding, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	var result Branding
-	err = bp.C.Call(ctx, "getBranding", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
-}
-
-// GetConfiguration calls getConfiguration on the server
-func (bp *APIoverJSONRPC) GetConfiguration(ctx context.Context) (res *Configuration, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	var result Configuration
-	err = bp.C.Call(ctx, "getConfiguration", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
-}
-
-// GetBhojpurTokenScopes calls getBhojpurTokenScopes on the server
-func (bp *APIoverJSONRPC) GetBhojpurTokenScopes(ctx context.Context, tokenHash string) (res []string, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, tokenHash)
-
-	var result []string
-	err = bp.C.Call(ctx, "getBhojpurTokenScopes", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// GetToken calls getToken on the server
-func (bp *APIoverJSONRPC) GetToken(ctx context.Context, query *GetTokenSearchOptions) (res *Token, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, query)
-
-	var result Token
-	err = bp.C.Call(ctx, "getToken", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
-}
-
-// GetPortAuthenticationToken calls getPortAuthenticationToken on the server
-func (bp *APIoverJSONRPC) GetPortAuthenticationToken(ctx context.Context, applicationID string) (res *Token, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-
-	var result Token
-	err = bp.C.Call(ctx, "getPortAuthenticationToken", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
-}
-
-// DeleteAccount calls deleteAccount on the server
-func (bp *APIoverJSONRPC) DeleteAccount(ctx context.Context) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	err = bp.C.Call(ctx, "deleteAccount", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// GetClientRegion calls getClientRegion on the server
-func (bp *APIoverJSONRPC) GetClientRegion(ctx context.Context) (res string, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	var result string
-	err = bp.C.Call(ctx, "getClientRegion", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
+	bp.dispatch(&instance, false)
 	return
 }
 
-// HasPermission calls hasPermission on the server
-func (bp *APIoverJSONRPC) HasPermission(ctx context.Context, permission *PermissionName) (res bool, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, permission)
-
-	var result bool
-	err = bp.C.Call(ctx, "hasPermission", _params, &result)
-	if err != nil {
-		return
+// dispatch passes an instance update on to the watcher and the subscribers of the instance
+func (bp *APIoverJSONRPC) dispatch(instance *ApplicationInstance, snapshot bool) {
+	bp.mu.Lock()
+	if bp.instanceApps == nil {
+		bp.instanceApps = make(map[string]string)
 	}
-	res = result
-
-	return
-}
-
-// GetApplications calls getApplications on the server
-func (bp *APIoverJSONRPC) GetApplications(ctx context.Context, options *GetApplicationsOptions) (res []*ApplicationInfo, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
+	if instance.ApplicationID != "" {
+		bp.instanceApps[instance.ID] = instance.ApplicationID
 	}
-	var _params []interface{}
-
-	_params = append(_params, options)
+	bp.endListeners(instance)
+	bp.mu.Unlock()
 
-	var result []*ApplicationInfo
-	err = bp.C.Call(ctx, "getApplications", _params, &result)
-	if err != nil {
-		return
+	bp.mu.RLock()
+	defer bp.mu.RUnlock()
+	if bp.watcher != nil {
+		bp.watcher.update(instance)
 	}
-	res = result
-
-	return
-}
-
-// GetApplicationOwner calls getApplicationOwner on the server
-func (bp *APIoverJSONRPC) GetApplicationOwner(ctx context.Context, applicationID string) (res *UserInfo, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
+	for sub := range bp.subs[instance.ID] {
+		sub.push(instance, snapshot)
 	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-
-	var result UserInfo
-	err = bp.C.Call(ctx, "getApplicationOwner", _params, &result)
-	if err != nil {
-		return
+	for sub := range bp.subs[""] {
+		sub.push(instance, snapshot)
 	}
-	res = &result
-
-	return
 }
 
-// GetApplicationUsers calls getApplicationUsers on the server
-func (bp *APIoverJSONRPC) GetApplicationUsers(ctx context.Context, applicationID string) (res []*ApplicationInstanceUser, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-
-	var result []*ApplicationInstanceUser
-	err = bp.C.Call(ctx, "getApplicationUsers", _params, &result)
-	if err != nil {
-		return
+// onReconnect notifies everyone waiting on reconnectedChan
+func (bp *APIoverJSONRPC) onReconnect() {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	if bp.reconnected != nil {
+		close(bp.reconnected)
 	}
-	res = result
-
-	return
+	bp.reconnected = make(chan struct{})
 }
 
-// GetFeaturedRepositories calls getFeaturedRepositories on the server
-func (bp *APIoverJSONRPC) GetFeaturedRepositories(ctx context.Context) (res []*WhitelistedRepository, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
+// reconnectedChan returns a channel which is closed on the next reconnect
+func (bp *APIoverJSONRPC) reconnectedChan() <-chan struct{} {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	if bp.reconnected == nil {
+		bp.reconnected = make(chan struct{})
 	}
-	var _params []interface{}
-
-	var result []*WhitelistedRepository
-	err = bp.C.Call(ctx, "getFeaturedRepositories", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// GetApplication calls getApplication on the server
-func (bp *APIoverJSONRPC) GetApplication(ctx context.Context, id string) (res *ApplicationInfo, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, id)
-
-	var result ApplicationInfo
-	err = bp.C.Call(ctx, "getApplication", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
-}
-
-// IsApplicationOwner calls isApplicationOwner on the server
-func (bp *APIoverJSONRPC) IsApplicationOwner(ctx context.Context, applicationID string) (res bool, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-
-	var result bool
-	err = bp.C.Call(ctx, "isApplicationOwner", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// CreateApplication calls createApplication on the server
-func (bp *APIoverJSONRPC) CreateApplication(ctx context.Context, options *CreateApplicationOptions) (res *ApplicationCreationResult, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, options)
-
-	var result ApplicationCreationResult
-	err = bp.C.Call(ctx, "createApplication", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
-}
-
-// StartApplication calls startApplication on the server
-func (bp *APIoverJSONRPC) StartApplication(ctx context.Context, id string, options *StartApplicationOptions) (res *StartApplicationResult, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, id)
-	_params = append(_params, options)
-
-	var result StartApplicationResult
-	err = bp.C.Call(ctx, "startApplication", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
-}
-
-// StopApplication calls stopApplication on the server
-func (bp *APIoverJSONRPC) StopApplication(ctx context.Context, id string) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, id)
-
-	err = bp.C.Call(ctx, "stopApplication", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// DeleteApplication calls deleteApplication on the server
-func (bp *APIoverJSONRPC) DeleteApplication(ctx context.Context, id string) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, id)
-
-	err = bp.C.Call(ctx, "deleteApplication", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// SetApplicationDescription calls setApplicationDescription on the server
-func (bp *APIoverJSONRPC) SetApplicationDescription(ctx context.Context, id string, desc string) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, id)
-	_params = append(_params, desc)
-
-	err = bp.C.Call(ctx, "setApplicationDescription", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// ControlAdmission calls controlAdmission on the server
-func (bp *APIoverJSONRPC) ControlAdmission(ctx context.Context, id string, level *AdmissionLevel) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, id)
-	_params = append(_params, level)
-
-	err = bp.C.Call(ctx, "controlAdmission", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// WatchApplicationImageBuildLogs calls watchApplicationImageBuildLogs on the server
-func (bp *APIoverJSONRPC) WatchApplicationImageBuildLogs(ctx context.Context, applicationID string) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-
-	err = bp.C.Call(ctx, "watchApplicationImageBuildLogs", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// IsPrebuildDone calls isPrebuildDone on the server
-func (bp *APIoverJSONRPC) IsPrebuildDone(ctx context.Context, pwsid string) (res bool, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, pwsid)
-
-	var result bool
-	err = bp.C.Call(ctx, "isPrebuildDone", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// SetApplicationTimeout calls setApplicationTimeout on the server
-func (bp *APIoverJSONRPC) SetApplicationTimeout(ctx context.Context, applicationID string, duration *ApplicationTimeoutDuration) (res *SetApplicationTimeoutResult, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-	_params = append(_params, duration)
-
-	var result SetApplicationTimeoutResult
-	err = bp.C.Call(ctx, "setApplicationTimeout", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
-}
-
-// GetApplicationTimeout calls getApplicationTimeout on the server
-func (bp *APIoverJSONRPC) GetApplicationTimeout(ctx context.Context, ApplicationID string) (res *GetApplicationTimeoutResult, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-
-	var result GetApplicationTimeoutResult
-	err = bp.C.Call(ctx, "getApplicationTimeout", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
-}
-
-// SendHeartBeat calls sendHeartBeat on the server
-func (bp *APIoverJSONRPC) SendHeartBeat(ctx context.Context, options *SendHeartBeatOptions) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, options)
-
-	err = bp.C.Call(ctx, "sendHeartBeat", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// UpdateApplicationUserPin calls updateApplicationUserPin on the server
-func (bp *APIoverJSONRPC) UpdateApplicationUserPin(ctx context.Context, id string, action *PinAction) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, id)
-	_params = append(_params, action)
-
-	err = bp.C.Call(ctx, "updateApplicationUserPin", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// GetOpenPorts calls getOpenPorts on the server
-func (bp *APIoverJSONRPC) GetOpenPorts(ctx context.Context, applicationID string) (res []*ApplicationInstancePort, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-
-	var result []*ApplicationInstancePort
-	err = bp.C.Call(ctx, "getOpenPorts", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// OpenPort calls openPort on the server
-func (bp *APIoverJSONRPC) OpenPort(ctx context.Context, applicationID string, port *ApplicationInstancePort) (res *ApplicationInstancePort, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-	_params = append(_params, port)
-
-	var result ApplicationInstancePort
-	err = bp.C.Call(ctx, "openPort", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
-}
-
-// ClosePort calls closePort on the server
-func (bp *APIoverJSONRPC) ClosePort(ctx context.Context, applicationID string, port float32) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-	_params = append(_params, port)
-
-	err = bp.C.Call(ctx, "closePort", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// GetUserStorageResource calls getUserStorageResource on the server
-func (bp *APIoverJSONRPC) GetUserStorageResource(ctx context.Context, options *GetUserStorageResourceOptions) (res string, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, options)
-
-	var result string
-	err = bp.C.Call(ctx, "getUserStorageResource", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// UpdateUserStorageResource calls updateUserStorageResource on the server
-func (bp *APIoverJSONRPC) UpdateUserStorageResource(ctx context.Context, options *UpdateUserStorageResourceOptions) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, options)
-
-	err = bp.C.Call(ctx, "updateUserStorageResource", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// GetEnvVars calls getEnvVars on the server
-func (bp *APIoverJSONRPC) GetEnvVars(ctx context.Context) (res []*UserEnvVarValue, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	var result []*UserEnvVarValue
-	err = bp.C.Call(ctx, "getEnvVars", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// SetEnvVar calls setEnvVar on the server
-func (bp *APIoverJSONRPC) SetEnvVar(ctx context.Context, variable *UserEnvVarValue) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, variable)
-
-	err = bp.C.Call(ctx, "setEnvVar", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// DeleteEnvVar calls deleteEnvVar on the server
-func (bp *APIoverJSONRPC) DeleteEnvVar(ctx context.Context, variable *UserEnvVarValue) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, variable)
-
-	err = bp.C.Call(ctx, "deleteEnvVar", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// GetContentBlobUploadURL calls getContentBlobUploadUrl on the server
-func (bp *APIoverJSONRPC) GetContentBlobUploadURL(ctx context.Context, name string) (url string, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, name)
-
-	var result string
-	err = bp.C.Call(ctx, string(FunctionGetContentBlobUploadURL), _params, &result)
-	if err != nil {
-		return
-	}
-	url = result
-
-	return
-}
-
-// GetContentBlobDownloadURL calls getContentBlobDownloadUrl on the server
-func (bp *APIoverJSONRPC) GetContentBlobDownloadURL(ctx context.Context, name string) (url string, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, name)
-
-	var result string
-	err = bp.C.Call(ctx, string(FunctionGetContentBlobDownloadURL), _params, &result)
-	if err != nil {
-		return
-	}
-	url = result
-
-	return
-}
-
-// GetBhojpurTokens calls getBhojpurTokens on the server
-func (bp *APIoverJSONRPC) GetBhojpurTokens(ctx context.Context) (res []*APIToken, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	var result []*APIToken
-	err = bp.C.Call(ctx, "getBhojpurTokens", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// GenerateNewBhojpurToken calls generateNewBhojpurToken on the server
-func (bp *APIoverJSONRPC) GenerateNewBhojpurToken(ctx context.Context, options *GenerateNewBhojpurTokenOptions) (res string, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, options)
-
-	var result string
-	err = bp.C.Call(ctx, "generateNewBhojpurToken", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// DeleteBhojpurToken calls deleteBhojpurToken on the server
-func (bp *APIoverJSONRPC) DeleteBhojpurToken(ctx context.Context, tokenHash string) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, tokenHash)
-
-	err = bp.C.Call(ctx, "deleteBhojpurToken", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// SendFeedback calls sendFeedback on the server
-func (bp *APIoverJSONRPC) SendFeedback(ctx context.Context, feedback string) (res string, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, feedback)
-
-	var result string
-	err = bp.C.Call(ctx, "sendFeedback", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// RegisterGithubApp calls registerGithubApp on the server
-func (bp *APIoverJSONRPC) RegisterGithubApp(ctx context.Context, installationID string) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, installationID)
-
-	err = bp.C.Call(ctx, "registerGithubApp", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// TakeSnapshot calls takeSnapshot on the server
-func (bp *APIoverJSONRPC) TakeSnapshot(ctx context.Context, options *TakeSnapshotOptions) (res string, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, options)
-
-	var result string
-	err = bp.C.Call(ctx, "takeSnapshot", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// WaitForSnapshot calls waitForSnapshot on the server
-func (bp *APIoverJSONRPC) WaitForSnapshot(ctx context.Context, snapshotId string) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, snapshotId)
-
-	var result string
-	err = bp.C.Call(ctx, "waitForSnapshot", _params, &result)
-	return
-}
-
-// GetSnapshots calls getSnapshots on the server
-func (bp *APIoverJSONRPC) GetSnapshots(ctx context.Context, applicationID string) (res []*string, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-
-	var result []*string
-	err = bp.C.Call(ctx, "getSnapshots", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// StoreLayout calls storeLayout on the server
-func (bp *APIoverJSONRPC) StoreLayout(ctx context.Context, applicationID string, layoutData string) (err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-	_params = append(_params, layoutData)
-
-	err = bp.C.Call(ctx, "storeLayout", _params, nil)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
-// GetLayout calls getLayout on the server
-func (bp *APIoverJSONRPC) GetLayout(ctx context.Context, applicationID string) (res string, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-
-	var result string
-	err = bp.C.Call(ctx, "getLayout", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// PreparePluginUpload calls preparePluginUpload on the server
-func (bp *APIoverJSONRPC) PreparePluginUpload(ctx context.Context, params *PreparePluginUploadParams) (res string, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, params)
-
-	var result string
-	err = bp.C.Call(ctx, "preparePluginUpload", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// ResolvePlugins calls resolvePlugins on the server
-func (bp *APIoverJSONRPC) ResolvePlugins(ctx context.Context, applicationID string, params *ResolvePluginsParams) (res *ResolvedPlugins, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, applicationID)
-	_params = append(_params, params)
-
-	var result ResolvedPlugins
-	err = bp.C.Call(ctx, "resolvePlugins", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
-}
-
-// InstallUserPlugins calls installUserPlugins on the server
-func (bp *APIoverJSONRPC) InstallUserPlugins(ctx context.Context, params *InstallPluginsParams) (res bool, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, params)
-
-	var result bool
-	err = bp.C.Call(ctx, "installUserPlugins", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// UninstallUserPlugin calls uninstallUserPlugin on the server
-func (bp *APIoverJSONRPC) UninstallUserPlugin(ctx context.Context, params *UninstallPluginParams) (res bool, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, params)
-
-	var result bool
-	err = bp.C.Call(ctx, "uninstallUserPlugin", _params, &result)
-	if err != nil {
-		return
-	}
-	res = result
-
-	return
-}
-
-// GuessGitTokenScopes calls GuessGitTokenScopes on the server
-func (bp *APIoverJSONRPC) GuessGitTokenScopes(ctx context.Context, params *GuessGitTokenScopesParams) (res *GuessedGitTokenScopes, err error) {
-	if bp == nil {
-		err = errNotConnected
-		return
-	}
-	var _params []interface{}
-
-	_params = append(_params, params)
-
-	var result GuessedGitTokenScopes
-	err = bp.C.Call(ctx, "guessGitTokenScopes", _params, &result)
-	if err != nil {
-		return
-	}
-	res = &result
-
-	return
+	return bp.reconnected
 }
 
 // PermissionName is the name of a permission
@@ -1670,8 +484,8 @@ type Application struct {
 
 	// Marks the time when the application was marked as softDeleted. The actual deletion of the
 	// application content happens after a configurable period
+	SoftDeletedTime string `json:"softDeletedTime,omitempty"`
 
-	// SoftDeletedTime string `json:"softDeletedTime,omitempty"`           string is the            string message type
 	Type string `json:"type,omitempty"`
 }
 
@@ -1943,7 +757,7 @@ type GenerateNewBhojpurTokenOptions struct {
 type TakeSnapshotOptions struct {
 	LayoutData  string `json:"layoutData,omitempty"`
 	ApplicationID string `json:"applicationId,omitempty"`
-	DontWait    bool   `json:"dontWait",omitempty`
+	DontWait    bool   `json:"dontWait,omitempty"`
 }
 
 // PreparePluginUploadParams is the PreparePluginUploadParams message type
@@ -0,0 +1,1312 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+// Code generated by go run ./generator. DO NOT EDIT.
+// Source: api.json
+
+package protocol
+
+import (
+	"context"
+)
+
+// APIInterface wraps the Bhojpur server API
+type APIInterface interface {
+	AdminBlockUser(ctx context.Context, message *AdminBlockUserRequest) (err error)
+	GetLoggedInUser(ctx context.Context) (res *User, err error)
+	UpdateLoggedInUser(ctx context.Context, user *User) (res *User, err error)
+	GetAuthProviders(ctx context.Context) (res []*AuthProviderInfo, err error)
+	GetOwnAuthProviders(ctx context.Context) (res []*AuthProviderEntry, err error)
+	UpdateOwnAuthProvider(ctx context.Context, params *UpdateOwnAuthProviderParams) (err error)
+	DeleteOwnAuthProvider(ctx context.Context, params *DeleteOwnAuthProviderParams) (err error)
+	GetBranding(ctx context.Context) (res *Branding, err error)
+	GetConfiguration(ctx context.Context) (res *Configuration, err error)
+	GetBhojpurTokenScopes(ctx context.Context, tokenHash string) (res []string, err error)
+	GetToken(ctx context.Context, query *GetTokenSearchOptions) (res *Token, err error)
+	GetPortAuthenticationToken(ctx context.Context, applicationID string) (res *Token, err error)
+	DeleteAccount(ctx context.Context) (err error)
+	GetClientRegion(ctx context.Context) (res string, err error)
+	HasPermission(ctx context.Context, permission *PermissionName) (res bool, err error)
+	GetApplications(ctx context.Context, options *GetApplicationsOptions) (res []*ApplicationInfo, err error)
+	GetApplicationOwner(ctx context.Context, applicationID string) (res *UserInfo, err error)
+	GetApplicationUsers(ctx context.Context, applicationID string) (res []*ApplicationInstanceUser, err error)
+	GetFeaturedRepositories(ctx context.Context) (res []*WhitelistedRepository, err error)
+	GetApplication(ctx context.Context, id string) (res *ApplicationInfo, err error)
+	IsApplicationOwner(ctx context.Context, applicationID string) (res bool, err error)
+	CreateApplication(ctx context.Context, options *CreateApplicationOptions) (res *ApplicationCreationResult, err error)
+	StartApplication(ctx context.Context, id string, options *StartApplicationOptions) (res *StartApplicationResult, err error)
+	StopApplication(ctx context.Context, id string) (err error)
+	DeleteApplication(ctx context.Context, id string) (err error)
+	SetApplicationDescription(ctx context.Context, id string, desc string) (err error)
+	ControlAdmission(ctx context.Context, id string, level *AdmissionLevel) (err error)
+	UpdateApplicationUserPin(ctx context.Context, id string, action *PinAction) (err error)
+	SendHeartBeat(ctx context.Context, options *SendHeartBeatOptions) (err error)
+	WatchApplicationImageBuildLogs(ctx context.Context, applicationID string) (err error)
+	IsPrebuildDone(ctx context.Context, pwsid string) (res bool, err error)
+	SetApplicationTimeout(ctx context.Context, applicationID string, duration *ApplicationTimeoutDuration) (res *SetApplicationTimeoutResult, err error)
+	GetApplicationTimeout(ctx context.Context, applicationID string) (res *GetApplicationTimeoutResult, err error)
+	GetOpenPorts(ctx context.Context, applicationID string) (res []*ApplicationInstancePort, err error)
+	OpenPort(ctx context.Context, applicationID string, port *ApplicationInstancePort) (res *ApplicationInstancePort, err error)
+	ClosePort(ctx context.Context, applicationID string, port float32) (err error)
+	GetUserStorageResource(ctx context.Context, options *GetUserStorageResourceOptions) (res string, err error)
+	UpdateUserStorageResource(ctx context.Context, options *UpdateUserStorageResourceOptions) (err error)
+	GetEnvVars(ctx context.Context) (res []*UserEnvVarValue, err error)
+	SetEnvVar(ctx context.Context, variable *UserEnvVarValue) (err error)
+	DeleteEnvVar(ctx context.Context, variable *UserEnvVarValue) (err error)
+	GetContentBlobUploadURL(ctx context.Context, name string) (url string, err error)
+	GetContentBlobDownloadURL(ctx context.Context, name string) (url string, err error)
+	GetBhojpurTokens(ctx context.Context) (res []*APIToken, err error)
+	GenerateNewBhojpurToken(ctx context.Context, options *GenerateNewBhojpurTokenOptions) (res string, err error)
+	DeleteBhojpurToken(ctx context.Context, tokenHash string) (err error)
+	SendFeedback(ctx context.Context, feedback string) (res string, err error)
+	RegisterGithubApp(ctx context.Context, installationID string) (err error)
+	TakeSnapshot(ctx context.Context, options *TakeSnapshotOptions) (res string, err error)
+	WaitForSnapshot(ctx context.Context, snapshotId string) (err error)
+	GetSnapshots(ctx context.Context, applicationID string) (res []*string, err error)
+	StoreLayout(ctx context.Context, applicationID string, layoutData string) (err error)
+	GetLayout(ctx context.Context, applicationID string) (res string, err error)
+	PreparePluginUpload(ctx context.Context, params *PreparePluginUploadParams) (res string, err error)
+	ResolvePlugins(ctx context.Context, applicationID string, params *ResolvePluginsParams) (res *ResolvedPlugins, err error)
+	InstallUserPlugins(ctx context.Context, params *InstallPluginsParams) (res bool, err error)
+	UninstallUserPlugin(ctx context.Context, params *UninstallPluginParams) (res bool, err error)
+	GuessGitTokenScopes(ctx context.Context, params *GuessGitTokenScopesParams) (res *GuessedGitTokenScopes, err error)
+
+	InstanceUpdates(ctx context.Context, instanceID string) (<-chan *ApplicationInstance, error)
+}
+
+var _ APIInterface = &APIoverJSONRPC{}
+
+// FunctionName is the name of an RPC function
+type FunctionName string
+
+const (
+	// FunctionAdminBlockUser is the name of the adminBlockUser function
+	FunctionAdminBlockUser FunctionName = "adminBlockUser"
+	// FunctionGetLoggedInUser is the name of the getLoggedInUser function
+	FunctionGetLoggedInUser FunctionName = "getLoggedInUser"
+	// FunctionUpdateLoggedInUser is the name of the updateLoggedInUser function
+	FunctionUpdateLoggedInUser FunctionName = "updateLoggedInUser"
+	// FunctionGetAuthProviders is the name of the getAuthProviders function
+	FunctionGetAuthProviders FunctionName = "getAuthProviders"
+	// FunctionGetOwnAuthProviders is the name of the getOwnAuthProviders function
+	FunctionGetOwnAuthProviders FunctionName = "getOwnAuthProviders"
+	// FunctionUpdateOwnAuthProvider is the name of the updateOwnAuthProvider function
+	FunctionUpdateOwnAuthProvider FunctionName = "updateOwnAuthProvider"
+	// FunctionDeleteOwnAuthProvider is the name of the deleteOwnAuthProvider function
+	FunctionDeleteOwnAuthProvider FunctionName = "deleteOwnAuthProvider"
+	// FunctionGetBranding is the name of the getBranding function
+	FunctionGetBranding FunctionName = "getBranding"
+	// FunctionGetConfiguration is the name of the getConfiguration function
+	FunctionGetConfiguration FunctionName = "getConfiguration"
+	// FunctionGetBhojpurTokenScopes is the name of the getBhojpurTokenScopes function
+	FunctionGetBhojpurTokenScopes FunctionName = "getBhojpurTokenScopes"
+	// FunctionGetToken is the name of the getToken function
+	FunctionGetToken FunctionName = "getToken"
+	// FunctionGetPortAuthenticationToken is the name of the getPortAuthenticationToken function
+	FunctionGetPortAuthenticationToken FunctionName = "getPortAuthenticationToken"
+	// FunctionDeleteAccount is the name of the deleteAccount function
+	FunctionDeleteAccount FunctionName = "deleteAccount"
+	// FunctionGetClientRegion is the name of the getClientRegion function
+	FunctionGetClientRegion FunctionName = "getClientRegion"
+	// FunctionHasPermission is the name of the hasPermission function
+	FunctionHasPermission FunctionName = "hasPermission"
+	// FunctionGetApplications is the name of the getApplications function
+	FunctionGetApplications FunctionName = "getApplications"
+	// FunctionGetApplicationOwner is the name of the getApplicationOwner function
+	FunctionGetApplicationOwner FunctionName = "getApplicationOwner"
+	// FunctionGetApplicationUsers is the name of the getApplicationUsers function
+	FunctionGetApplicationUsers FunctionName = "getApplicationUsers"
+	// FunctionGetFeaturedRepositories is the name of the getFeaturedRepositories function
+	FunctionGetFeaturedRepositories FunctionName = "getFeaturedRepositories"
+	// FunctionGetApplication is the name of the getApplication function
+	FunctionGetApplication FunctionName = "getApplication"
+	// FunctionIsApplicationOwner is the name of the isApplicationOwner function
+	FunctionIsApplicationOwner FunctionName = "isApplicationOwner"
+	// FunctionCreateApplication is the name of the createApplication function
+	FunctionCreateApplication FunctionName = "createApplication"
+	// FunctionStartApplication is the name of the startApplication function
+	FunctionStartApplication FunctionName = "startApplication"
+	// FunctionStopApplication is the name of the stopApplication function
+	FunctionStopApplication FunctionName = "stopApplication"
+	// FunctionDeleteApplication is the name of the deleteApplication function
+	FunctionDeleteApplication FunctionName = "deleteApplication"
+	// FunctionSetApplicationDescription is the name of the setApplicationDescription function
+	FunctionSetApplicationDescription FunctionName = "setApplicationDescription"
+	// FunctionControlAdmission is the name of the controlAdmission function
+	FunctionControlAdmission FunctionName = "controlAdmission"
+	// FunctionUpdateApplicationUserPin is the name of the updateApplicationUserPin function
+	FunctionUpdateApplicationUserPin FunctionName = "updateApplicationUserPin"
+	// FunctionSendHeartBeat is the name of the sendHeartBeat function
+	FunctionSendHeartBeat FunctionName = "sendHeartBeat"
+	// FunctionWatchApplicationImageBuildLogs is the name of the watchApplicationImageBuildLogs function
+	FunctionWatchApplicationImageBuildLogs FunctionName = "watchApplicationImageBuildLogs"
+	// FunctionIsPrebuildDone is the name of the isPrebuildDone function
+	FunctionIsPrebuildDone FunctionName = "isPrebuildDone"
+	// FunctionSetApplicationTimeout is the name of the setApplicationTimeout function
+	FunctionSetApplicationTimeout FunctionName = "setApplicationTimeout"
+	// FunctionGetApplicationTimeout is the name of the getApplicationTimeout function
+	FunctionGetApplicationTimeout FunctionName = "getApplicationTimeout"
+	// FunctionGetOpenPorts is the name of the getOpenPorts function
+	FunctionGetOpenPorts FunctionName = "getOpenPorts"
+	// FunctionOpenPort is the name of the openPort function
+	FunctionOpenPort FunctionName = "openPort"
+	// FunctionClosePort is the name of the closePort function
+	FunctionClosePort FunctionName = "closePort"
+	// FunctionGetUserStorageResource is the name of the getUserStorageResource function
+	FunctionGetUserStorageResource FunctionName = "getUserStorageResource"
+	// FunctionUpdateUserStorageResource is the name of the updateUserStorageResource function
+	FunctionUpdateUserStorageResource FunctionName = "updateUserStorageResource"
+	// FunctionGetEnvVars is the name of the getEnvVars function
+	FunctionGetEnvVars FunctionName = "getEnvVars"
+	// FunctionSetEnvVar is the name of the setEnvVar function
+	FunctionSetEnvVar FunctionName = "setEnvVar"
+	// FunctionDeleteEnvVar is the name of the deleteEnvVar function
+	FunctionDeleteEnvVar FunctionName = "deleteEnvVar"
+	// FunctionGetContentBlobUploadURL is the name of the getContentBlobUploadUrl function
+	FunctionGetContentBlobUploadURL FunctionName = "getContentBlobUploadUrl"
+	// FunctionGetContentBlobDownloadURL is the name of the getContentBlobDownloadUrl function
+	FunctionGetContentBlobDownloadURL FunctionName = "getContentBlobDownloadUrl"
+	// FunctionGetBhojpurTokens is the name of the getBhojpurTokens function
+	FunctionGetBhojpurTokens FunctionName = "getBhojpurTokens"
+	// FunctionGenerateNewBhojpurToken is the name of the generateNewBhojpurToken function
+	FunctionGenerateNewBhojpurToken FunctionName = "generateNewBhojpurToken"
+	// FunctionDeleteBhojpurToken is the name of the deleteBhojpurToken function
+	FunctionDeleteBhojpurToken FunctionName = "deleteBhojpurToken"
+	// FunctionSendFeedback is the name of the sendFeedback function
+	FunctionSendFeedback FunctionName = "sendFeedback"
+	// FunctionRegisterGithubApp is the name of the registerGithubApp function
+	FunctionRegisterGithubApp FunctionName = "registerGithubApp"
+	// FunctionTakeSnapshot is the name of the takeSnapshot function
+	FunctionTakeSnapshot FunctionName = "takeSnapshot"
+	// FunctionWaitForSnapshot is the name of the waitForSnapshot function
+	FunctionWaitForSnapshot FunctionName = "waitForSnapshot"
+	// FunctionGetSnapshots is the name of the getSnapshots function
+	FunctionGetSnapshots FunctionName = "getSnapshots"
+	// FunctionStoreLayout is the name of the storeLayout function
+	FunctionStoreLayout FunctionName = "storeLayout"
+	// FunctionGetLayout is the name of the getLayout function
+	FunctionGetLayout FunctionName = "getLayout"
+	// FunctionPreparePluginUpload is the name of the preparePluginUpload function
+	FunctionPreparePluginUpload FunctionName = "preparePluginUpload"
+	// FunctionResolvePlugins is the name of the resolvePlugins function
+	FunctionResolvePlugins FunctionName = "resolvePlugins"
+	// FunctionInstallUserPlugins is the name of the installUserPlugins function
+	FunctionInstallUserPlugins FunctionName = "installUserPlugins"
+	// FunctionUninstallUserPlugin is the name of the uninstallUserPlugin function
+	FunctionUninstallUserPlugin FunctionName = "uninstallUserPlugin"
+	// FunctionGuessGitTokenScopes is the name of the guessGitTokenScopes function
+	FunctionGuessGitTokenScopes FunctionName = "guessGitTokenScopes"
+)
+
+// AdminBlockUser calls adminBlockUser on the server
+func (bp *APIoverJSONRPC) AdminBlockUser(ctx context.Context, message *AdminBlockUserRequest) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, message)
+
+	err = bp.call(ctx, string(FunctionAdminBlockUser), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// GetLoggedInUser calls getLoggedInUser on the server
+func (bp *APIoverJSONRPC) GetLoggedInUser(ctx context.Context) (res *User, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	var result User
+	err = bp.call(ctx, string(FunctionGetLoggedInUser), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// UpdateLoggedInUser calls updateLoggedInUser on the server
+func (bp *APIoverJSONRPC) UpdateLoggedInUser(ctx context.Context, user *User) (res *User, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, user)
+
+	var result User
+	err = bp.call(ctx, string(FunctionUpdateLoggedInUser), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// GetAuthProviders calls getAuthProviders on the server
+func (bp *APIoverJSONRPC) GetAuthProviders(ctx context.Context) (res []*AuthProviderInfo, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	var result []*AuthProviderInfo
+	err = bp.call(ctx, string(FunctionGetAuthProviders), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// GetOwnAuthProviders calls getOwnAuthProviders on the server
+func (bp *APIoverJSONRPC) GetOwnAuthProviders(ctx context.Context) (res []*AuthProviderEntry, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	var result []*AuthProviderEntry
+	err = bp.call(ctx, string(FunctionGetOwnAuthProviders), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// UpdateOwnAuthProvider calls updateOwnAuthProvider on the server
+func (bp *APIoverJSONRPC) UpdateOwnAuthProvider(ctx context.Context, params *UpdateOwnAuthProviderParams) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, params)
+
+	err = bp.call(ctx, string(FunctionUpdateOwnAuthProvider), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// DeleteOwnAuthProvider calls deleteOwnAuthProvider on the server
+func (bp *APIoverJSONRPC) DeleteOwnAuthProvider(ctx context.Context, params *DeleteOwnAuthProviderParams) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, params)
+
+	err = bp.call(ctx, string(FunctionDeleteOwnAuthProvider), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// GetBranding calls getBranding on the server
+func (bp *APIoverJSONRPC) GetBranding(ctx context.Context) (res *Branding, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	var result Branding
+	err = bp.call(ctx, string(FunctionGetBranding), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// GetConfiguration calls getConfiguration on the server
+func (bp *APIoverJSONRPC) GetConfiguration(ctx context.Context) (res *Configuration, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	var result Configuration
+	err = bp.call(ctx, string(FunctionGetConfiguration), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// GetBhojpurTokenScopes calls getBhojpurTokenScopes on the server
+func (bp *APIoverJSONRPC) GetBhojpurTokenScopes(ctx context.Context, tokenHash string) (res []string, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, tokenHash)
+
+	var result []string
+	err = bp.call(ctx, string(FunctionGetBhojpurTokenScopes), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// GetToken calls getToken on the server
+func (bp *APIoverJSONRPC) GetToken(ctx context.Context, query *GetTokenSearchOptions) (res *Token, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, query)
+
+	var result Token
+	err = bp.call(ctx, string(FunctionGetToken), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// GetPortAuthenticationToken calls getPortAuthenticationToken on the server
+func (bp *APIoverJSONRPC) GetPortAuthenticationToken(ctx context.Context, applicationID string) (res *Token, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+
+	var result Token
+	err = bp.call(ctx, string(FunctionGetPortAuthenticationToken), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// DeleteAccount calls deleteAccount on the server
+func (bp *APIoverJSONRPC) DeleteAccount(ctx context.Context) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	err = bp.call(ctx, string(FunctionDeleteAccount), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// GetClientRegion calls getClientRegion on the server
+func (bp *APIoverJSONRPC) GetClientRegion(ctx context.Context) (res string, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	var result string
+	err = bp.call(ctx, string(FunctionGetClientRegion), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// HasPermission calls hasPermission on the server
+func (bp *APIoverJSONRPC) HasPermission(ctx context.Context, permission *PermissionName) (res bool, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, permission)
+
+	var result bool
+	err = bp.call(ctx, string(FunctionHasPermission), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// GetApplications calls getApplications on the server
+func (bp *APIoverJSONRPC) GetApplications(ctx context.Context, options *GetApplicationsOptions) (res []*ApplicationInfo, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, options)
+
+	var result []*ApplicationInfo
+	err = bp.call(ctx, string(FunctionGetApplications), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// GetApplicationOwner calls getApplicationOwner on the server
+func (bp *APIoverJSONRPC) GetApplicationOwner(ctx context.Context, applicationID string) (res *UserInfo, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+
+	var result UserInfo
+	err = bp.call(ctx, string(FunctionGetApplicationOwner), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// GetApplicationUsers calls getApplicationUsers on the server
+func (bp *APIoverJSONRPC) GetApplicationUsers(ctx context.Context, applicationID string) (res []*ApplicationInstanceUser, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+
+	var result []*ApplicationInstanceUser
+	err = bp.call(ctx, string(FunctionGetApplicationUsers), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// GetFeaturedRepositories calls getFeaturedRepositories on the server
+func (bp *APIoverJSONRPC) GetFeaturedRepositories(ctx context.Context) (res []*WhitelistedRepository, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	var result []*WhitelistedRepository
+	err = bp.call(ctx, string(FunctionGetFeaturedRepositories), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// GetApplication calls getApplication on the server
+func (bp *APIoverJSONRPC) GetApplication(ctx context.Context, id string) (res *ApplicationInfo, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, id)
+
+	var result ApplicationInfo
+	err = bp.call(ctx, string(FunctionGetApplication), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// IsApplicationOwner calls isApplicationOwner on the server
+func (bp *APIoverJSONRPC) IsApplicationOwner(ctx context.Context, applicationID string) (res bool, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+
+	var result bool
+	err = bp.call(ctx, string(FunctionIsApplicationOwner), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// CreateApplication calls createApplication on the server
+func (bp *APIoverJSONRPC) CreateApplication(ctx context.Context, options *CreateApplicationOptions) (res *ApplicationCreationResult, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, options)
+
+	var result ApplicationCreationResult
+	err = bp.call(ctx, string(FunctionCreateApplication), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// StartApplication calls startApplication on the server
+func (bp *APIoverJSONRPC) StartApplication(ctx context.Context, id string, options *StartApplicationOptions) (res *StartApplicationResult, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, id)
+	_params = append(_params, options)
+
+	var result StartApplicationResult
+	err = bp.call(ctx, string(FunctionStartApplication), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// StopApplication calls stopApplication on the server
+func (bp *APIoverJSONRPC) StopApplication(ctx context.Context, id string) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, id)
+
+	err = bp.call(ctx, string(FunctionStopApplication), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// DeleteApplication calls deleteApplication on the server
+func (bp *APIoverJSONRPC) DeleteApplication(ctx context.Context, id string) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, id)
+
+	err = bp.call(ctx, string(FunctionDeleteApplication), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// SetApplicationDescription calls setApplicationDescription on the server
+func (bp *APIoverJSONRPC) SetApplicationDescription(ctx context.Context, id string, desc string) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, id)
+	_params = append(_params, desc)
+
+	err = bp.call(ctx, string(FunctionSetApplicationDescription), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// ControlAdmission calls controlAdmission on the server
+func (bp *APIoverJSONRPC) ControlAdmission(ctx context.Context, id string, level *AdmissionLevel) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, id)
+	_params = append(_params, level)
+
+	err = bp.call(ctx, string(FunctionControlAdmission), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// UpdateApplicationUserPin calls updateApplicationUserPin on the server
+func (bp *APIoverJSONRPC) UpdateApplicationUserPin(ctx context.Context, id string, action *PinAction) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, id)
+	_params = append(_params, action)
+
+	err = bp.call(ctx, string(FunctionUpdateApplicationUserPin), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// SendHeartBeat calls sendHeartBeat on the server
+func (bp *APIoverJSONRPC) SendHeartBeat(ctx context.Context, options *SendHeartBeatOptions) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, options)
+
+	err = bp.call(ctx, string(FunctionSendHeartBeat), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// WatchApplicationImageBuildLogs calls watchApplicationImageBuildLogs on the server
+func (bp *APIoverJSONRPC) WatchApplicationImageBuildLogs(ctx context.Context, applicationID string) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+
+	err = bp.call(ctx, string(FunctionWatchApplicationImageBuildLogs), _params, nil)
+	if err != nil {
+		return
+	}
+	bp.addListener(FunctionWatchApplicationImageBuildLogs, _params)
+
+	return
+}
+
+// IsPrebuildDone calls isPrebuildDone on the server
+func (bp *APIoverJSONRPC) IsPrebuildDone(ctx context.Context, pwsid string) (res bool, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, pwsid)
+
+	var result bool
+	err = bp.call(ctx, string(FunctionIsPrebuildDone), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// SetApplicationTimeout calls setApplicationTimeout on the server
+func (bp *APIoverJSONRPC) SetApplicationTimeout(ctx context.Context, applicationID string, duration *ApplicationTimeoutDuration) (res *SetApplicationTimeoutResult, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+	_params = append(_params, duration)
+
+	var result SetApplicationTimeoutResult
+	err = bp.call(ctx, string(FunctionSetApplicationTimeout), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// GetApplicationTimeout calls getApplicationTimeout on the server
+func (bp *APIoverJSONRPC) GetApplicationTimeout(ctx context.Context, applicationID string) (res *GetApplicationTimeoutResult, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+
+	var result GetApplicationTimeoutResult
+	err = bp.call(ctx, string(FunctionGetApplicationTimeout), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// GetOpenPorts calls getOpenPorts on the server
+func (bp *APIoverJSONRPC) GetOpenPorts(ctx context.Context, applicationID string) (res []*ApplicationInstancePort, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+
+	var result []*ApplicationInstancePort
+	err = bp.call(ctx, string(FunctionGetOpenPorts), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// OpenPort calls openPort on the server
+func (bp *APIoverJSONRPC) OpenPort(ctx context.Context, applicationID string, port *ApplicationInstancePort) (res *ApplicationInstancePort, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+	_params = append(_params, port)
+
+	var result ApplicationInstancePort
+	err = bp.call(ctx, string(FunctionOpenPort), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// ClosePort calls closePort on the server
+func (bp *APIoverJSONRPC) ClosePort(ctx context.Context, applicationID string, port float32) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+	_params = append(_params, port)
+
+	err = bp.call(ctx, string(FunctionClosePort), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// GetUserStorageResource calls getUserStorageResource on the server
+func (bp *APIoverJSONRPC) GetUserStorageResource(ctx context.Context, options *GetUserStorageResourceOptions) (res string, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, options)
+
+	var result string
+	err = bp.call(ctx, string(FunctionGetUserStorageResource), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// UpdateUserStorageResource calls updateUserStorageResource on the server
+func (bp *APIoverJSONRPC) UpdateUserStorageResource(ctx context.Context, options *UpdateUserStorageResourceOptions) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, options)
+
+	err = bp.call(ctx, string(FunctionUpdateUserStorageResource), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// GetEnvVars calls getEnvVars on the server
+func (bp *APIoverJSONRPC) GetEnvVars(ctx context.Context) (res []*UserEnvVarValue, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	var result []*UserEnvVarValue
+	err = bp.call(ctx, string(FunctionGetEnvVars), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// SetEnvVar calls setEnvVar on the server
+func (bp *APIoverJSONRPC) SetEnvVar(ctx context.Context, variable *UserEnvVarValue) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, variable)
+
+	err = bp.call(ctx, string(FunctionSetEnvVar), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// DeleteEnvVar calls deleteEnvVar on the server
+func (bp *APIoverJSONRPC) DeleteEnvVar(ctx context.Context, variable *UserEnvVarValue) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, variable)
+
+	err = bp.call(ctx, string(FunctionDeleteEnvVar), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// GetContentBlobUploadURL calls getContentBlobUploadUrl on the server
+func (bp *APIoverJSONRPC) GetContentBlobUploadURL(ctx context.Context, name string) (url string, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, name)
+
+	var result string
+	err = bp.call(ctx, string(FunctionGetContentBlobUploadURL), _params, &result)
+	if err != nil {
+		return
+	}
+	url = result
+
+	return
+}
+
+// GetContentBlobDownloadURL calls getContentBlobDownloadUrl on the server
+func (bp *APIoverJSONRPC) GetContentBlobDownloadURL(ctx context.Context, name string) (url string, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, name)
+
+	var result string
+	err = bp.call(ctx, string(FunctionGetContentBlobDownloadURL), _params, &result)
+	if err != nil {
+		return
+	}
+	url = result
+
+	return
+}
+
+// GetBhojpurTokens calls getBhojpurTokens on the server
+func (bp *APIoverJSONRPC) GetBhojpurTokens(ctx context.Context) (res []*APIToken, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	var result []*APIToken
+	err = bp.call(ctx, string(FunctionGetBhojpurTokens), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// GenerateNewBhojpurToken calls generateNewBhojpurToken on the server
+func (bp *APIoverJSONRPC) GenerateNewBhojpurToken(ctx context.Context, options *GenerateNewBhojpurTokenOptions) (res string, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, options)
+
+	var result string
+	err = bp.call(ctx, string(FunctionGenerateNewBhojpurToken), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// DeleteBhojpurToken calls deleteBhojpurToken on the server
+func (bp *APIoverJSONRPC) DeleteBhojpurToken(ctx context.Context, tokenHash string) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, tokenHash)
+
+	err = bp.call(ctx, string(FunctionDeleteBhojpurToken), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// SendFeedback calls sendFeedback on the server
+func (bp *APIoverJSONRPC) SendFeedback(ctx context.Context, feedback string) (res string, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, feedback)
+
+	var result string
+	err = bp.call(ctx, string(FunctionSendFeedback), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// RegisterGithubApp calls registerGithubApp on the server
+func (bp *APIoverJSONRPC) RegisterGithubApp(ctx context.Context, installationID string) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, installationID)
+
+	err = bp.call(ctx, string(FunctionRegisterGithubApp), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// TakeSnapshot calls takeSnapshot on the server
+func (bp *APIoverJSONRPC) TakeSnapshot(ctx context.Context, options *TakeSnapshotOptions) (res string, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, options)
+
+	var result string
+	err = bp.call(ctx, string(FunctionTakeSnapshot), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// WaitForSnapshot calls waitForSnapshot on the server
+func (bp *APIoverJSONRPC) WaitForSnapshot(ctx context.Context, snapshotId string) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, snapshotId)
+
+	err = bp.call(ctx, string(FunctionWaitForSnapshot), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// GetSnapshots calls getSnapshots on the server
+func (bp *APIoverJSONRPC) GetSnapshots(ctx context.Context, applicationID string) (res []*string, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+
+	var result []*string
+	err = bp.call(ctx, string(FunctionGetSnapshots), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// StoreLayout calls storeLayout on the server
+func (bp *APIoverJSONRPC) StoreLayout(ctx context.Context, applicationID string, layoutData string) (err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+	_params = append(_params, layoutData)
+
+	err = bp.call(ctx, string(FunctionStoreLayout), _params, nil)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// GetLayout calls getLayout on the server
+func (bp *APIoverJSONRPC) GetLayout(ctx context.Context, applicationID string) (res string, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+
+	var result string
+	err = bp.call(ctx, string(FunctionGetLayout), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// PreparePluginUpload calls preparePluginUpload on the server
+func (bp *APIoverJSONRPC) PreparePluginUpload(ctx context.Context, params *PreparePluginUploadParams) (res string, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, params)
+
+	var result string
+	err = bp.call(ctx, string(FunctionPreparePluginUpload), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// ResolvePlugins calls resolvePlugins on the server
+func (bp *APIoverJSONRPC) ResolvePlugins(ctx context.Context, applicationID string, params *ResolvePluginsParams) (res *ResolvedPlugins, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, applicationID)
+	_params = append(_params, params)
+
+	var result ResolvedPlugins
+	err = bp.call(ctx, string(FunctionResolvePlugins), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
+
+// InstallUserPlugins calls installUserPlugins on the server
+func (bp *APIoverJSONRPC) InstallUserPlugins(ctx context.Context, params *InstallPluginsParams) (res bool, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, params)
+
+	var result bool
+	err = bp.call(ctx, string(FunctionInstallUserPlugins), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// UninstallUserPlugin calls uninstallUserPlugin on the server
+func (bp *APIoverJSONRPC) UninstallUserPlugin(ctx context.Context, params *UninstallPluginParams) (res bool, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, params)
+
+	var result bool
+	err = bp.call(ctx, string(FunctionUninstallUserPlugin), _params, &result)
+	if err != nil {
+		return
+	}
+	res = result
+
+	return
+}
+
+// GuessGitTokenScopes calls guessGitTokenScopes on the server
+func (bp *APIoverJSONRPC) GuessGitTokenScopes(ctx context.Context, params *GuessGitTokenScopesParams) (res *GuessedGitTokenScopes, err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+
+	_params = append(_params, params)
+
+	var result GuessedGitTokenScopes
+	err = bp.call(ctx, string(FunctionGuessGitTokenScopes), _params, &result)
+	if err != nil {
+		return
+	}
+	res = &result
+
+	return
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ConnectionState is the state of the connection to the server
+type ConnectionState string
+
+const (
+	// StateConnecting means we're opening a connection
+	StateConnecting ConnectionState = "connecting"
+	// StateConnected means the connection is open
+	StateConnected ConnectionState = "connected"
+	// StateBackingOff means the connection failed or was lost, and we wait before trying again
+	StateBackingOff ConnectionState = "backing off"
+	// StateClosed means the connection is closed for good
+	StateClosed ConnectionState = "closed"
+)
+
+// ConnectionStatus describes the connection to the server
+type ConnectionStatus struct {
+	State ConnectionState
+	// Err is why the last attempt failed or the connection was lost, or why it was closed
+	Err error
+	// Attempt counts the attempts to connect since the connection was last established
+	Attempt int
+	// Delay is the time we wait before the next attempt when backing off
+	Delay time.Duration
+	// Time is when the state was entered
+	Time time.Time
+}
+
+// connectionStates holds the connection status and sends its changes to the listeners
+type connectionStates struct {
+	mu        sync.Mutex
+	current   ConnectionStatus
+	listeners map[chan ConnectionStatus]struct{}
+}
+
+func (s *connectionStates) get() ConnectionStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current.State == "" {
+		return ConnectionStatus{State: StateConnecting}
+	}
+	return s.current
+}
+
+func (s *connectionStates) set(status ConnectionStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current.State == StateClosed {
+		return
+	}
+
+	status.Time = time.Now()
+	s.current = status
+	for l := range s.listeners {
+		// listeners only need the latest status, so we replace the one they haven't read yet
+		select {
+		case l <- status:
+		default:
+			select {
+			case <-l:
+			default:
+			}
+			l <- status
+		}
+	}
+}
+
+func (s *connectionStates) close(err error) {
+	s.set(ConnectionStatus{State: StateClosed, Err: err})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for l := range s.listeners {
+		close(l)
+	}
+	s.listeners = nil
+}
+
+func (s *connectionStates) subscribe(ctx context.Context) <-chan ConnectionStatus {
+	res := make(chan ConnectionStatus, 1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current.State == "" {
+		res <- ConnectionStatus{State: StateConnecting}
+	} else {
+		res <- s.current
+	}
+	if s.current.State == StateClosed {
+		close(res)
+		return res
+	}
+
+	if s.listeners == nil {
+		s.listeners = make(map[chan ConnectionStatus]struct{})
+	}
+	s.listeners[res] = struct{}{}
+	go func() {
+		<-ctx.Done()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.listeners[res]; ok {
+			delete(s.listeners, res)
+			close(res)
+		}
+	}()
+	return res
+}
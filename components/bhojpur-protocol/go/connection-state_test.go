@@ -0,0 +1,81 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReconnectPolicy(t *testing.T) {
+	p := ReconnectPolicy{MinDelay: time.Second, MaxDelay: 3 * time.Second, GrowFactor: 2}.withDefaults()
+	if p.HandshakeTimeout != DefaultReconnectPolicy().HandshakeTimeout || p.MaxBadHandshakes != DefaultReconnectPolicy().MaxBadHandshakes {
+		t.Errorf("zero fields were not defaulted: %+v", p)
+	}
+
+	var delays []time.Duration
+	for d := p.MinDelay; len(delays) < 4; d = p.nextDelay(d) {
+		delays = append(delays, d)
+	}
+	expectation := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for i := range expectation {
+		if delays[i] != expectation[i] {
+			t.Fatalf("unexpected delays: got %v, expected %v", delays, expectation)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		if w := p.wait(p.MaxDelay); w < 0 || w > p.MaxDelay {
+			t.Fatalf("jittered wait out of bounds: %v", w)
+		}
+	}
+	p.DisableJitter = true
+	if w := p.wait(p.MaxDelay); w != p.MaxDelay {
+		t.Errorf("unexpected wait without jitter: %v", w)
+	}
+}
+
+func TestConnectionStates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var s connectionStates
+	states := s.subscribe(ctx)
+	if st := <-states; st.State != StateConnecting {
+		t.Errorf("unexpected initial state: %v", st.State)
+	}
+
+	// slow readers only get the latest state
+	s.set(ConnectionStatus{State: StateConnected})
+	s.set(ConnectionStatus{State: StateBackingOff, Err: errNotConnected})
+	if st := <-states; st.State != StateBackingOff || !errors.Is(st.Err, errNotConnected) {
+		t.Errorf("unexpected state: %+v", st)
+	}
+
+	cancelled, cancelListener := context.WithCancel(ctx)
+	other := s.subscribe(cancelled)
+	<-other
+	cancelListener()
+	for range other {
+	}
+
+	s.close(ErrClosed)
+	if st := <-states; st.State != StateClosed || st.Err != ErrClosed {
+		t.Errorf("unexpected state: %+v", st)
+	}
+	if _, ok := <-states; ok {
+		t.Error("states were not closed")
+	}
+
+	s.set(ConnectionStatus{State: StateConnected})
+	if st := s.get(); st.State != StateClosed {
+		t.Errorf("closed connection changed state: %v", st.State)
+	}
+	if st, ok := <-s.subscribe(ctx); !ok || st.State != StateClosed {
+		t.Errorf("unexpected state after close: %+v", st)
+	}
+}
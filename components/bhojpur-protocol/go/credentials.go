@@ -0,0 +1,185 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialProvider provides the token the client authenticates with
+type CredentialProvider interface {
+	// Token returns the current token. It's called on every (re)connect.
+	Token(ctx context.Context) (string, error)
+	// Refresh returns a new token. It's called once when the server rejects the current one.
+	Refresh(ctx context.Context) (string, error)
+}
+
+// StaticToken is a token which never changes
+type StaticToken string
+
+// Token implements CredentialProvider
+func (t StaticToken) Token(ctx context.Context) (string, error) {
+	return string(t), nil
+}
+
+// Refresh implements CredentialProvider
+func (t StaticToken) Refresh(ctx context.Context) (string, error) {
+	return string(t), nil
+}
+
+// EnvCredentials reads the token from an environment variable
+type EnvCredentials struct {
+	Name string
+}
+
+// Token implements CredentialProvider
+func (c *EnvCredentials) Token(ctx context.Context) (string, error) {
+	token := strings.TrimSpace(os.Getenv(c.Name))
+	if token == "" {
+		return "", fmt.Errorf("environment variable %s is not set", c.Name)
+	}
+	return token, nil
+}
+
+// Refresh implements CredentialProvider
+func (c *EnvCredentials) Refresh(ctx context.Context) (string, error) {
+	return c.Token(ctx)
+}
+
+// FileCredentials reads the token from a file, e.g. a mounted secret. The file
+// is read again when it changes.
+type FileCredentials struct {
+	Path string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+// NewFileCredentials creates a provider for the token in the file at path
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{Path: path}
+}
+
+// Token implements CredentialProvider
+func (c *FileCredentials) Token(ctx context.Context) (string, error) {
+	return c.read(false)
+}
+
+// Refresh implements CredentialProvider
+func (c *FileCredentials) Refresh(ctx context.Context) (string, error) {
+	return c.read(true)
+}
+
+func (c *FileCredentials) read(force bool) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stat, err := os.Stat(c.Path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read token: %w", err)
+	}
+	if !force && c.token != "" && stat.ModTime().Equal(c.modTime) && stat.Size() == c.size {
+		return c.token, nil
+	}
+
+	fc, err := os.ReadFile(c.Path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read token: %w", err)
+	}
+	token := strings.TrimSpace(string(fc))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", c.Path)
+	}
+	c.token = token
+	c.modTime = stat.ModTime()
+	c.size = stat.Size()
+	return token, nil
+}
+
+// ExecCredentials runs a command to get the token, like the exec credential plugins
+// of kubectl. The command prints an ExecCredential to stdout. The token is cached
+// until it expires.
+type ExecCredentials struct {
+	Command string
+	Args    []string
+	// Env is added to the environment of the command, as KEY=value
+	Env []string
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// ExecCredential is what an exec credential plugin prints
+type ExecCredential struct {
+	Status *ExecCredentialStatus `json:"status"`
+}
+
+// ExecCredentialStatus holds the token of an ExecCredential
+type ExecCredentialStatus struct {
+	Token string `json:"token"`
+	// ExpirationTimestamp is the time the token expires, it's cached forever if not set
+	ExpirationTimestamp *time.Time `json:"expirationTimestamp,omitempty"`
+}
+
+// NewExecCredentials creates a provider which runs command to get the token
+func NewExecCredentials(command string, args ...string) *ExecCredentials {
+	return &ExecCredentials{Command: command, Args: args}
+}
+
+// Token implements CredentialProvider
+func (c *ExecCredentials) Token(ctx context.Context) (string, error) {
+	return c.run(ctx, false)
+}
+
+// Refresh implements CredentialProvider
+func (c *ExecCredentials) Refresh(ctx context.Context) (string, error) {
+	return c.run(ctx, true)
+}
+
+func (c *ExecCredentials) run(ctx context.Context, force bool) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !force && c.token != "" && (c.expires.IsZero() || time.Now().Before(c.expires)) {
+		return c.token, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Command, c.Args...)
+	cmd.Env = append(os.Environ(), c.Env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("credential plugin %s failed: %w: %s", c.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var cred ExecCredential
+	err = json.Unmarshal(stdout.Bytes(), &cred)
+	if err != nil {
+		return "", fmt.Errorf("credential plugin %s printed invalid output: %w", c.Command, err)
+	}
+	if cred.Status == nil || cred.Status.Token == "" {
+		return "", fmt.Errorf("credential plugin %s printed no token", c.Command)
+	}
+
+	c.token = cred.Status.Token
+	c.expires = time.Time{}
+	if cred.Status.ExpirationTimestamp != nil {
+		c.expires = *cred.Status.ExpirationTimestamp
+	}
+	return c.token, nil
+}
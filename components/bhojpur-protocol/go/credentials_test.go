@@ -0,0 +1,152 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("BHOJPUR_TEST_TOKEN", " env-token\n")
+
+	token, err := (&EnvCredentials{Name: "BHOJPUR_TEST_TOKEN"}).Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "env-token" {
+		t.Errorf("unexpected token: %q", token)
+	}
+
+	_, err = (&EnvCredentials{Name: "BHOJPUR_TEST_DOES_NOT_EXIST"}).Token(context.Background())
+	if err == nil {
+		t.Error("missing environment variable was accepted")
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	ctx := context.Background()
+	fn := filepath.Join(t.TempDir(), "token")
+	write := func(token string, modTime time.Time) {
+		err := os.WriteFile(fn, []byte(token+"\n"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Chtimes(fn, modTime, modTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Now().Add(-time.Hour)
+	write("first", start)
+	creds := NewFileCredentials(fn)
+	token, err := creds.Token(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "first" {
+		t.Errorf("unexpected token: %q", token)
+	}
+
+	write("second", start.Add(time.Minute))
+	token, err = creds.Token(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "second" {
+		t.Errorf("changed file was not read again: got %q", token)
+	}
+
+	write("", start.Add(2*time.Minute))
+	_, err = creds.Refresh(ctx)
+	if err == nil {
+		t.Error("empty token file was accepted")
+	}
+}
+
+func TestExecCredentials(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "count")
+	// the plugin prints a new token on every run
+	script := `echo x >> "$COUNTER"; echo "{\"status\": {\"token\": \"token-$(wc -l < "$COUNTER" | tr -d ' ')\", \"expirationTimestamp\": \"$EXPIRES\"}}"`
+
+	tests := []struct {
+		Name        string
+		Expires     time.Time
+		Expectation []string
+	}{
+		{Name: "cached until expiry", Expires: time.Now().Add(time.Hour), Expectation: []string{"token-1", "token-1", "token-2"}},
+		{Name: "expired", Expires: time.Now().Add(-time.Hour), Expectation: []string{"token-1", "token-2", "token-3"}},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_ = os.Remove(counter)
+			creds := NewExecCredentials("sh", "-c", script)
+			creds.Env = []string{"COUNTER=" + counter, "EXPIRES=" + test.Expires.UTC().Format(time.RFC3339)}
+
+			var act []string
+			for i, get := range []func(context.Context) (string, error){creds.Token, creds.Token, creds.Refresh} {
+				token, err := get(ctx)
+				if err != nil {
+					t.Fatalf("call %d: %v", i, err)
+				}
+				act = append(act, token)
+			}
+			for i := range test.Expectation {
+				if act[i] != test.Expectation[i] {
+					t.Errorf("unexpected tokens: got %v, expected %v", act, test.Expectation)
+					break
+				}
+			}
+		})
+	}
+
+	_, err := NewExecCredentials("sh", "-c", "echo '{}'").Token(ctx)
+	if err == nil {
+		t.Error("plugin output without a token was accepted")
+	}
+	_, err = NewExecCredentials("sh", "-c", "echo denied >&2; exit 1").Token(ctx)
+	if err == nil {
+		t.Error("failing plugin was accepted")
+	}
+}
+
+// hangingCredentials never return a token before ctx is done
+type hangingCredentials struct{}
+
+func (hangingCredentials) Token(ctx context.Context) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (c hangingCredentials) Refresh(ctx context.Context) (string, error) {
+	return c.Token(ctx)
+}
+
+func TestCredentialTimeout(t *testing.T) {
+	rc := NewReconnectingWebsocket("ws://localhost", nil, nil)
+	rc.Credentials = hangingCredentials{}
+	rc.CredentialTimeout = 50 * time.Millisecond
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := rc.header(context.Background(), false)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error: got %v, expected %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("getting credentials did not time out")
+	}
+}
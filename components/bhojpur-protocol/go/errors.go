@@ -0,0 +1,139 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// ErrorCode is the code of an error returned by the server
+type ErrorCode int64
+
+// Taken from src/messaging/error.ts
+const (
+	ErrorCodeNotAuthenticated                ErrorCode = 401
+	ErrorCodeNotEnoughCredit                 ErrorCode = 402
+	ErrorCodePermissionDenied                ErrorCode = 403
+	ErrorCodeNotFound                        ErrorCode = 404
+	ErrorCodeConflict                        ErrorCode = 409
+	ErrorCodeSetupRequired                   ErrorCode = 410
+	ErrorCodeTooManyRequests                 ErrorCode = 429
+	ErrorCodeRepositoryNotWhitelisted        ErrorCode = 430
+	ErrorCodePaymentError                    ErrorCode = 450
+	ErrorCodeContextParseError               ErrorCode = 460
+	ErrorCodeInvalidBhojpurYML               ErrorCode = 461
+	ErrorCodeUserBlocked                     ErrorCode = 470
+	ErrorCodeUserDeleted                     ErrorCode = 471
+	ErrorCodeUserTermsAcceptanceRequired     ErrorCode = 472
+	ErrorCodePlanDoesNotAllowPrivateRepos    ErrorCode = 480
+	ErrorCodePlanProfessionalRequired        ErrorCode = 481
+	ErrorCodePlanOnlyAllowedForStudents      ErrorCode = 485
+	ErrorCodeTooManyRunningApplications      ErrorCode = 490
+	ErrorCodeEEFeature                       ErrorCode = 501
+	ErrorCodeEELicenseRequired               ErrorCode = 555
+	ErrorCodeSaaSFeature                     ErrorCode = 601
+	ErrorCodeTeamSubscriptionInvalidQuantity ErrorCode = 610
+	ErrorCodeTeamSubscriptionAssignment      ErrorCode = 620
+	ErrorCodeSnapshotError                   ErrorCode = 630
+
+	// ErrorCodeInternal is the JSON-RPC code of unexpected server errors
+	ErrorCodeInternal ErrorCode = jsonrpc2.CodeInternalError
+)
+
+// Error is an error returned by the server. It matches the Err* values of the
+// same code with errors.Is, and unwraps to the underlying *jsonrpc2.Error.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	// Method is the function which failed
+	Method FunctionName
+
+	err *jsonrpc2.Error
+}
+
+func (e *Error) Error() string {
+	if e.Method == "" {
+		return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+	}
+	return fmt.Sprintf("%s: %s (code %d)", e.Method, e.Message, e.Code)
+}
+
+// Is matches errors of the same code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func (e *Error) Unwrap() error {
+	if e.err == nil {
+		return nil
+	}
+	return e.err
+}
+
+// Errors to compare the errors returned by the client with, using errors.Is
+var (
+	ErrNotAuthenticated           = &Error{Code: ErrorCodeNotAuthenticated, Message: "not authenticated"}
+	ErrNotEnoughCredit            = &Error{Code: ErrorCodeNotEnoughCredit, Message: "not enough credit"}
+	ErrPermissionDenied           = &Error{Code: ErrorCodePermissionDenied, Message: "permission denied"}
+	ErrNotFound                   = &Error{Code: ErrorCodeNotFound, Message: "not found"}
+	ErrConflict                   = &Error{Code: ErrorCodeConflict, Message: "conflict"}
+	ErrSetupRequired              = &Error{Code: ErrorCodeSetupRequired, Message: "setup required"}
+	ErrTooManyRequests            = &Error{Code: ErrorCodeTooManyRequests, Message: "too many requests"}
+	ErrUserBlocked                = &Error{Code: ErrorCodeUserBlocked, Message: "user blocked"}
+	ErrUserDeleted                = &Error{Code: ErrorCodeUserDeleted, Message: "user deleted"}
+	ErrTooManyRunningApplications = &Error{Code: ErrorCodeTooManyRunningApplications, Message: "too many running applications"}
+	ErrInternal                   = &Error{Code: ErrorCodeInternal, Message: "internal server error"}
+)
+
+// translateError turns the errors of the server into *Error, so that they can be matched
+func translateError(method FunctionName, err error) error {
+	var rpcErr *jsonrpc2.Error
+	if !errors.As(err, &rpcErr) {
+		return err
+	}
+
+	return &Error{
+		Code:    ErrorCode(rpcErr.Code),
+		Message: rpcErr.Message,
+		Method:  method,
+		err:     rpcErr,
+	}
+}
+
+// retryableCodes are the error codes of failures which may succeed when tried again
+var retryableCodes = map[ErrorCode]struct{}{
+	ErrorCodeTooManyRequests: {},
+	ErrorCodeInternal:        {},
+}
+
+// IsRetryable is true if a call which failed with err may succeed when tried again,
+// such as after a connection loss, rate limiting or an internal server error. Errors
+// which won't change by trying again, such as authentication, permission or invalid
+// requests, are permanent. Retries should back off, in particular on rate limiting.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var protoErr *Error
+	if errors.As(err, &protoErr) {
+		_, ok := retryableCodes[protoErr.Code]
+		return ok
+	}
+
+	// The caller gave up, trying again won't help
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// Connection failures
+	return errors.Is(err, jsonrpc2.ErrClosed) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) || errors.Is(err, errNotConnected)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func TestTranslateError(t *testing.T) {
+	err := translateError(FunctionGetApplication, &jsonrpc2.Error{Code: 404, Message: "Application abc does not exist."})
+	wrapped := fmt.Errorf("cannot get application: %w", err)
+
+	if !errors.Is(wrapped, ErrNotFound) {
+		t.Errorf("error does not match ErrNotFound: %v", wrapped)
+	}
+	if errors.Is(wrapped, ErrPermissionDenied) {
+		t.Errorf("error matches ErrPermissionDenied: %v", wrapped)
+	}
+
+	var protoErr *Error
+	if !errors.As(wrapped, &protoErr) {
+		t.Fatalf("error is no *Error: %v", wrapped)
+	}
+	if protoErr.Code != ErrorCodeNotFound || protoErr.Method != FunctionGetApplication {
+		t.Errorf("unexpected error: %+v", protoErr)
+	}
+
+	var rpcErr *jsonrpc2.Error
+	if !errors.As(wrapped, &rpcErr) {
+		t.Errorf("error does not unwrap to *jsonrpc2.Error: %v", wrapped)
+	}
+
+	other := errors.New("other")
+	if translateError(FunctionGetApplication, other) != other {
+		t.Error("non JSON-RPC error was changed")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Err       error
+		Retryable bool
+	}{
+		{"nil", nil, false},
+		{"not authenticated", translateError(FunctionGetLoggedInUser, &jsonrpc2.Error{Code: 401}), false},
+		{"permission denied", translateError(FunctionGetLoggedInUser, &jsonrpc2.Error{Code: 403}), false},
+		{"too many requests", translateError(FunctionGetLoggedInUser, &jsonrpc2.Error{Code: 429}), true},
+		{"internal error", translateError(FunctionGetLoggedInUser, &jsonrpc2.Error{Code: jsonrpc2.CodeInternalError}), true},
+		{"method not found", translateError(FunctionGetLoggedInUser, &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound}), false},
+		{"connection closed", fmt.Errorf("call failed: %w", jsonrpc2.ErrClosed), true},
+		{"context canceled", context.Canceled, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if act := IsRetryable(test.Err); act != test.Retryable {
+				t.Errorf("unexpected result: got %v, expected %v", act, test.Retryable)
+			}
+		})
+	}
+}
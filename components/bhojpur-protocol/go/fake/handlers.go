@@ -0,0 +1,398 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package fake
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/jsonrpc2"
+
+	protocol "github.com/bhojpur/platform/bhojpur-protocol"
+)
+
+// handler implements a function of the server for the user
+type handler func(s *Server, userID string, params []json.RawMessage) (interface{}, error)
+
+// handlers are the functions the fake server supports. All others fail with method not found.
+var handlers = map[protocol.FunctionName]handler{
+	protocol.FunctionGetLoggedInUser:                getLoggedInUser,
+	protocol.FunctionUpdateLoggedInUser:             updateLoggedInUser,
+	protocol.FunctionGetApplications:                getApplications,
+	protocol.FunctionGetApplication:                 getApplication,
+	protocol.FunctionIsApplicationOwner:             isApplicationOwner,
+	protocol.FunctionCreateApplication:              createApplication,
+	protocol.FunctionStartApplication:               startApplication,
+	protocol.FunctionStopApplication:                stopApplication,
+	protocol.FunctionDeleteApplication:              deleteApplication,
+	protocol.FunctionWatchApplicationImageBuildLogs: watchApplicationImageBuildLogs,
+	protocol.FunctionGetEnvVars:                     getEnvVars,
+	protocol.FunctionSetEnvVar:                      setEnvVar,
+	protocol.FunctionDeleteEnvVar:                   deleteEnvVar,
+	protocol.FunctionGetBhojpurTokens:               getBhojpurTokens,
+	protocol.FunctionGenerateNewBhojpurToken:        generateNewBhojpurToken,
+	protocol.FunctionDeleteBhojpurToken:             deleteBhojpurToken,
+}
+
+// decode unmarshals the positional parameters into the targets
+func decode(params []json.RawMessage, targets ...interface{}) error {
+	for i, t := range targets {
+		if i >= len(params) {
+			return &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: fmt.Sprintf("expected %d parameters, got %d", len(targets), len(params))}
+		}
+		err := json.Unmarshal(params[i], t)
+		if err != nil {
+			return &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: fmt.Sprintf("invalid parameter %d: %v", i, err)}
+		}
+	}
+	return nil
+}
+
+func errNotFound(format string, args ...interface{}) error {
+	return &jsonrpc2.Error{Code: int64(protocol.ErrorCodeNotFound), Message: fmt.Sprintf(format, args...)}
+}
+
+func errPermissionDenied(format string, args ...interface{}) error {
+	return &jsonrpc2.Error{Code: int64(protocol.ErrorCodePermissionDenied), Message: fmt.Sprintf(format, args...)}
+}
+
+func getLoggedInUser(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.users[userID], nil
+}
+
+func updateLoggedInUser(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	var user protocol.User
+	err := decode(params, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user.ID = userID
+	s.users[userID] = &user
+	return &user, nil
+}
+
+// application returns an application of the user. Callers must hold the lock.
+func (s *Server) application(userID, id string) (*protocol.Application, error) {
+	app, ok := s.applications[id]
+	if !ok || app.Deleted {
+		return nil, errNotFound("Application %s does not exist.", id)
+	}
+	if app.OwnerID != userID {
+		return nil, errPermissionDenied("Application %s is not owned by the user.", id)
+	}
+	return app, nil
+}
+
+// latestInstance returns the most recently started instance of an application. Callers must hold the lock.
+func (s *Server) latestInstance(applicationID string) *protocol.ApplicationInstance {
+	var res *protocol.ApplicationInstance
+	for _, inst := range s.instances {
+		if inst.ApplicationID != applicationID {
+			continue
+		}
+		if res == nil || inst.CreationTime > res.CreationTime || (inst.CreationTime == res.CreationTime && inst.ID > res.ID) {
+			res = inst
+		}
+	}
+	return res
+}
+
+func getApplications(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	var options protocol.GetApplicationsOptions
+	if len(params) > 0 {
+		err := decode(params, &options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := []*protocol.ApplicationInfo{}
+	for _, app := range s.applications {
+		if app.OwnerID != userID || app.Deleted {
+			continue
+		}
+		if options.PinnedOnly && !app.Pinned {
+			continue
+		}
+		if options.SearchString != "" && !strings.Contains(app.Description, options.SearchString) && !strings.Contains(app.ContextURL, options.SearchString) {
+			continue
+		}
+		res = append(res, &protocol.ApplicationInfo{Application: app, LatestInstance: s.latestInstance(app.ID)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Application.CreationTime > res[j].Application.CreationTime })
+	if options.Limit > 0 && len(res) > int(options.Limit) {
+		res = res[:int(options.Limit)]
+	}
+	return res, nil
+}
+
+func getApplication(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	var id string
+	err := decode(params, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	app, err := s.application(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.ApplicationInfo{Application: app, LatestInstance: s.latestInstance(id)}, nil
+}
+
+func isApplicationOwner(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	var id string
+	err := decode(params, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	app, ok := s.applications[id]
+	if !ok || app.Deleted {
+		return nil, errNotFound("Application %s does not exist.", id)
+	}
+	return app.OwnerID == userID, nil
+}
+
+func createApplication(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	var options protocol.CreateApplicationOptions
+	err := decode(params, &options)
+	if err != nil {
+		return nil, err
+	}
+	if options.ContextURL == "" {
+		return nil, &jsonrpc2.Error{Code: int64(protocol.ErrorCodeContextParseError), Message: "Context URL is empty."}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	app := &protocol.Application{
+		ID:           s.nextID("app"),
+		OwnerID:      userID,
+		ContextURL:   options.ContextURL,
+		Description:  options.ContextURL,
+		CreationTime: now(),
+		Type:         "regular",
+	}
+	s.applications[app.ID] = app
+	return &protocol.ApplicationCreationResult{CreatedApplicationID: app.ID}, nil
+}
+
+func startApplication(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	var id string
+	err := decode(params, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	_, err = s.application(userID, id)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	if latest := s.latestInstance(id); latest != nil && latest.Phase() != protocol.PhaseStopped {
+		s.mu.Unlock()
+		return &protocol.StartApplicationResult{InstanceID: latest.ID, ApplicationURL: latest.IdeURL}, nil
+	}
+
+	instanceID := s.nextID("instance")
+	inst := &protocol.ApplicationInstance{
+		ID:            instanceID,
+		ApplicationID: id,
+		CreationTime:  now(),
+		IdeURL:        fmt.Sprintf("https://%s.bhojpur.test", instanceID),
+		Status:        &protocol.ApplicationInstanceStatus{Phase: string(protocol.PhasePending)},
+	}
+	s.instances[instanceID] = inst
+	s.mu.Unlock()
+
+	s.PushInstanceUpdate(inst)
+	return &protocol.StartApplicationResult{InstanceID: instanceID, ApplicationURL: inst.IdeURL}, nil
+}
+
+func stopApplication(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	var id string
+	err := decode(params, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	_, err = s.application(userID, id)
+	latest := s.latestInstance(id)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil || latest.Phase() == protocol.PhaseStopped {
+		return nil, nil
+	}
+
+	for _, phase := range []protocol.ApplicationPhase{protocol.PhaseStopping, protocol.PhaseStopped} {
+		err = s.SetInstancePhase(latest.ID, phase)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func deleteApplication(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	_, err := stopApplication(s, userID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var id string
+	_ = decode(params, &id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	app := *s.applications[id]
+	app.Deleted = true
+	s.applications[id] = &app
+	return nil, nil
+}
+
+func watchApplicationImageBuildLogs(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	var id string
+	err := decode(params, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.application(userID, id)
+	return nil, err
+}
+
+func getEnvVars(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*protocol.UserEnvVarValue{}, s.envVars[userID]...), nil
+}
+
+func setEnvVar(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	var variable protocol.UserEnvVarValue
+	err := decode(params, &variable)
+	if err != nil {
+		return nil, err
+	}
+	if variable.Name == "" {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: "Variable name is empty."}
+	}
+	if variable.RepositoryPattern == "" {
+		variable.RepositoryPattern = "*/*"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vars := s.envVars[userID]
+	for i, v := range vars {
+		if v.Name == variable.Name && v.RepositoryPattern == variable.RepositoryPattern {
+			variable.ID = v.ID
+			vars[i] = &variable
+			return nil, nil
+		}
+	}
+	variable.ID = s.nextID("envvar")
+	s.envVars[userID] = append(vars, &variable)
+	return nil, nil
+}
+
+func deleteEnvVar(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	var variable protocol.UserEnvVarValue
+	err := decode(params, &variable)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vars := s.envVars[userID]
+	for i, v := range vars {
+		if (variable.ID != "" && v.ID == variable.ID) || (variable.ID == "" && v.Name == variable.Name && v.RepositoryPattern == variable.RepositoryPattern) {
+			s.envVars[userID] = append(vars[:i:i], vars[i+1:]...)
+			return nil, nil
+		}
+	}
+	return nil, errNotFound("Environment variable %s does not exist.", variable.Name)
+}
+
+func getBhojpurTokens(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*protocol.APIToken{}, s.apiTokens[userID]...), nil
+}
+
+func generateNewBhojpurToken(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	var options protocol.GenerateNewBhojpurTokenOptions
+	err := decode(params, &options)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 16)
+	_, err = rand.Read(b)
+	if err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiTokens[userID] = append(s.apiTokens[userID], &protocol.APIToken{
+		Created:   now(),
+		Name:      options.Name,
+		TokenHash: hashToken(token),
+		Type:      options.Type,
+		User:      &protocol.User{ID: userID},
+	})
+	// the token authenticates the user, as on the real server
+	s.tokens[token] = userID
+	return token, nil
+}
+
+func deleteBhojpurToken(s *Server, userID string, params []json.RawMessage) (interface{}, error) {
+	var tokenHash string
+	err := decode(params, &tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tokens := s.apiTokens[userID]
+	for i, t := range tokens {
+		if t.TokenHash != tokenHash {
+			continue
+		}
+		s.apiTokens[userID] = append(tokens[:i:i], tokens[i+1:]...)
+		for token, uid := range s.tokens {
+			if uid == userID && hashToken(token) == tokenHash {
+				delete(s.tokens, token)
+			}
+		}
+		return nil, nil
+	}
+	return nil, errNotFound("Token %s does not exist.", tokenHash)
+}
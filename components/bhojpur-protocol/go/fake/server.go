@@ -0,0 +1,366 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+// Package fake provides an in-memory Bhojpur server which speaks the websocket
+// JSON-RPC protocol of the real server, to test clients without a cluster.
+package fake
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sourcegraph/jsonrpc2"
+	wsrpc "github.com/sourcegraph/jsonrpc2/websocket"
+
+	protocol "github.com/bhojpur/platform/bhojpur-protocol"
+)
+
+// Server is an in-memory Bhojpur server. All state is kept in memory and lost on Close.
+type Server struct {
+	// URL is the websocket endpoint to connect the client to
+	URL string
+
+	srv       *httptest.Server
+	upgrader  websocket.Upgrader
+	listeners []net.Listener
+
+	mu           sync.Mutex
+	ids          int
+	tokens       map[string]string
+	users        map[string]*protocol.User
+	applications map[string]*protocol.Application
+	instances    map[string]*protocol.ApplicationInstance
+	envVars      map[string][]*protocol.UserEnvVarValue
+	apiTokens    map[string][]*protocol.APIToken
+	conns        map[*jsonrpc2.Conn]*connection
+	calls        []Call
+
+	faults faults
+}
+
+// Call is a call a client made
+type Call struct {
+	UserID string
+	Method protocol.FunctionName
+	Params json.RawMessage
+}
+
+type connection struct {
+	conn *jsonrpc2.Conn
+	// token authenticates the client. It's checked on every call, so that it can be revoked.
+	token string
+}
+
+// NewServer starts a new fake server. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		tokens:       make(map[string]string),
+		users:        make(map[string]*protocol.User),
+		applications: make(map[string]*protocol.Application),
+		instances:    make(map[string]*protocol.ApplicationInstance),
+		envVars:      make(map[string][]*protocol.UserEnvVarValue),
+		apiTokens:    make(map[string][]*protocol.APIToken),
+		conns:        make(map[*jsonrpc2.Conn]*connection),
+		faults: faults{
+			latency: make(map[protocol.FunctionName]time.Duration),
+			errors:  make(map[protocol.FunctionName][]*jsonrpc2.Error),
+		},
+		upgrader: websocket.Upgrader{
+			CheckOrigin:       func(r *http.Request) bool { return true },
+			EnableCompression: true,
+		},
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = "ws" + strings.TrimPrefix(s.srv.URL, "http") + "/api/bhojpur"
+	return s
+}
+
+// Close disconnects all clients and stops the server
+func (s *Server) Close() {
+	s.mu.Lock()
+	for _, l := range s.listeners {
+		_ = l.Close()
+	}
+	s.mu.Unlock()
+
+	s.DropConnections()
+	s.srv.Close()
+}
+
+// Serve serves the API on another listener in addition to URL, e.g. on a Unix domain
+// socket or the listener of a protocol.PipeTransport. The listener is closed with the server.
+func (s *Server) Serve(l net.Listener) {
+	s.mu.Lock()
+	s.listeners = append(s.listeners, l)
+	s.mu.Unlock()
+
+	go func() {
+		_ = http.Serve(l, http.HandlerFunc(s.serveHTTP))
+	}()
+}
+
+// AddUser adds a user which authenticates with the token
+func (s *Server) AddUser(user *protocol.User, token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[user.ID] = user
+	s.tokens[token] = user.ID
+}
+
+// AddToken adds another token of an existing user, e.g. to rotate tokens
+func (s *Server) AddToken(userID string, token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[token] = userID
+}
+
+// RevokeToken makes calls with the token fail as not authenticated, and handshakes with it fail with 401
+func (s *Server) RevokeToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, token)
+}
+
+// AddApplication adds an application of an existing user
+func (s *Server) AddApplication(app *protocol.Application) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.applications[app.ID] = app
+}
+
+// Application returns the application of the given ID, or nil if there is none
+func (s *Server) Application(id string) *protocol.Application {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.applications[id]
+}
+
+// Instance returns the instance of the given ID, or nil if there is none
+func (s *Server) Instance(id string) *protocol.ApplicationInstance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.instances[id]
+}
+
+// Calls returns the calls the clients made so far
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// SetInstancePhase changes the phase of an instance and pushes the update to the clients of its owner
+func (s *Server) SetInstancePhase(instanceID string, phase protocol.ApplicationPhase) error {
+	s.mu.Lock()
+	inst, ok := s.instances[instanceID]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("instance %s does not exist", instanceID)
+	}
+	update := *inst
+	status := protocol.ApplicationInstanceStatus{}
+	if inst.Status != nil {
+		status = *inst.Status
+	}
+	status.Phase = string(phase)
+	update.Status = &status
+	if phase == protocol.PhaseStopped {
+		update.StoppedTime = now()
+	}
+	s.instances[instanceID] = &update
+	s.mu.Unlock()
+
+	s.PushInstanceUpdate(&update)
+	return nil
+}
+
+// PushInstanceUpdate sends an instance update to the clients of the owner of its application,
+// without changing the state of the server
+func (s *Server) PushInstanceUpdate(instance *protocol.ApplicationInstance) {
+	s.mu.Lock()
+	var owner string
+	if app, ok := s.applications[instance.ApplicationID]; ok {
+		owner = app.OwnerID
+	}
+	var conns []*jsonrpc2.Conn
+	for _, c := range s.conns {
+		if userID, ok := s.tokens[c.token]; ok && userID == owner {
+			conns = append(conns, c.conn)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, c := range conns {
+		_ = c.Notify(context.Background(), protocol.FunctionOnInstanceUpdate, instance)
+	}
+}
+
+// Connections returns the number of connected clients
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns)
+}
+
+// DropConnections closes the connections of all clients, as a server restart would
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	var conns []*jsonrpc2.Conn
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range conns {
+		_ = c.Close()
+	}
+}
+
+// faults are the failures the server was told to inject
+type faults struct {
+	handshakeStatus   int
+	handshakeFailures int
+	latency           map[protocol.FunctionName]time.Duration
+	errors            map[protocol.FunctionName][]*jsonrpc2.Error
+}
+
+// RejectHandshakes makes the next n websocket handshakes fail with the HTTP status
+func (s *Server) RejectHandshakes(n int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults.handshakeFailures = n
+	s.faults.handshakeStatus = status
+}
+
+// SetLatency delays the responses to a function, or to all functions if method is empty
+func (s *Server) SetLatency(method protocol.FunctionName, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults.latency[method] = latency
+}
+
+// FailNext makes the next call of a function fail with the error code, or of any function if method is empty
+func (s *Server) FailNext(method protocol.FunctionName, code protocol.ErrorCode, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults.errors[method] = append(s.faults.errors[method], &jsonrpc2.Error{Code: int64(code), Message: message})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if s.faults.handshakeFailures > 0 {
+		s.faults.handshakeFailures--
+		status := s.faults.handshakeStatus
+		s.mu.Unlock()
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	_, known := s.tokens[token]
+	s.mu.Unlock()
+	if token != "" && !known {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &connection{token: token}
+	c.conn = jsonrpc2.NewConn(context.Background(), wsrpc.NewObjectStream(ws), jsonrpc2.AsyncHandler(jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+		return s.handle(ctx, c, req)
+	})))
+
+	s.mu.Lock()
+	s.conns[c.conn] = c
+	s.mu.Unlock()
+
+	<-c.conn.DisconnectNotify()
+
+	s.mu.Lock()
+	delete(s.conns, c.conn)
+	s.mu.Unlock()
+}
+
+func (s *Server) handle(ctx context.Context, c *connection, req *jsonrpc2.Request) (interface{}, error) {
+	method := protocol.FunctionName(req.Method)
+
+	s.mu.Lock()
+	userID, authenticated := s.tokens[c.token]
+	call := Call{UserID: userID, Method: method}
+	if req.Params != nil {
+		call.Params = append(json.RawMessage(nil), *req.Params...)
+	}
+	s.calls = append(s.calls, call)
+
+	latency, ok := s.faults.latency[method]
+	if !ok {
+		latency = s.faults.latency[""]
+	}
+	var injected *jsonrpc2.Error
+	for _, m := range []protocol.FunctionName{method, ""} {
+		if errs := s.faults.errors[m]; len(errs) > 0 {
+			injected = errs[0]
+			s.faults.errors[m] = errs[1:]
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if injected != nil {
+		return nil, injected
+	}
+
+	if !authenticated {
+		return nil, &jsonrpc2.Error{Code: int64(protocol.ErrorCodeNotAuthenticated), Message: "Not authenticated"}
+	}
+
+	h, ok := handlers[method]
+	if !ok {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: fmt.Sprintf("method not supported by the fake server: %s", method)}
+	}
+
+	var params []json.RawMessage
+	if req.Params != nil {
+		err := json.Unmarshal(*req.Params, &params)
+		if err != nil {
+			return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: err.Error()}
+		}
+	}
+	return h(s, userID, params)
+}
+
+// nextID returns a new unique ID. Callers must hold the lock.
+func (s *Server) nextID(prefix string) string {
+	s.ids++
+	return fmt.Sprintf("%s-%d", prefix, s.ids)
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+func hashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,399 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	protocol "github.com/bhojpur/platform/bhojpur-protocol"
+	"github.com/bhojpur/platform/bhojpur-protocol/fake"
+)
+
+const token = "test-token"
+
+func connect(t *testing.T, srv *fake.Server, opts protocol.ConnectToServerOpts) *protocol.APIoverJSONRPC {
+	if opts.Token == "" {
+		opts.Token = token
+	}
+	opts.Log = logrus.NewEntry(logrus.New())
+	client, err := protocol.ConnectToServer(srv.URL, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func newServer(t *testing.T) *fake.Server {
+	srv := fake.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddUser(&protocol.User{ID: "user", Name: "test"}, token)
+	return srv
+}
+
+func TestApplicationLifecycle(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srv := newServer(t)
+	client := connect(t, srv, protocol.ConnectToServerOpts{})
+
+	user, err := client.GetLoggedInUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != "user" {
+		t.Errorf("unexpected user: %s", user.ID)
+	}
+
+	created, err := client.CreateApplication(ctx, &protocol.CreateApplicationOptions{ContextURL: "https://github.com/bhojpur/platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher := client.Watcher()
+	started, err := client.StartApplication(ctx, created.CreatedApplicationID, &protocol.StartApplicationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for _, phase := range []protocol.ApplicationPhase{protocol.PhaseCreating, protocol.PhaseInitializing, protocol.PhaseRunning} {
+			_ = srv.SetInstancePhase(started.InstanceID, phase)
+		}
+	}()
+	_, err = watcher.WaitForPhase(ctx, started.InstanceID, protocol.PhaseRunning)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.StopApplication(ctx, created.CreatedApplicationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = watcher.WaitForPhase(ctx, started.InstanceID, protocol.PhaseStopped)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.GetApplication(ctx, "does-not-exist")
+	if !errors.Is(err, protocol.ErrNotFound) {
+		t.Errorf("unexpected error: got %v, expected %v", err, protocol.ErrNotFound)
+	}
+}
+
+func TestEnvVarsAndTokens(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srv := newServer(t)
+	client := connect(t, srv, protocol.ConnectToServerOpts{})
+
+	err := client.SetEnvVar(ctx, &protocol.UserEnvVarValue{Name: "FOO", Value: "bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars, err := client.GetEnvVars(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vars) != 1 || vars[0].Value != "bar" {
+		t.Errorf("unexpected env vars: %v", vars)
+	}
+
+	apiToken, err := client.GenerateNewBhojpurToken(ctx, &protocol.GenerateNewBhojpurTokenOptions{Name: "ci"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := connect(t, srv, protocol.ConnectToServerOpts{Token: apiToken})
+	_, err = other.GetLoggedInUser(ctx)
+	if err != nil {
+		t.Errorf("generated token does not authenticate: %v", err)
+	}
+
+	srv.RevokeToken(token)
+	_, err = client.GetLoggedInUser(ctx)
+	if !errors.Is(err, protocol.ErrNotAuthenticated) {
+		t.Errorf("unexpected error: got %v, expected %v", err, protocol.ErrNotAuthenticated)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srv := newServer(t)
+	client := connect(t, srv, protocol.ConnectToServerOpts{
+		Interceptors: []protocol.Interceptor{protocol.DeadlineInterceptor(100*time.Millisecond, nil)},
+	})
+
+	srv.FailNext(protocol.FunctionGetLoggedInUser, protocol.ErrorCodeTooManyRequests, "slow down")
+	_, err := client.GetLoggedInUser(ctx)
+	if !errors.Is(err, protocol.ErrTooManyRequests) {
+		t.Errorf("unexpected error: got %v, expected %v", err, protocol.ErrTooManyRequests)
+	}
+
+	// the deadline interceptor only applies to calls without a deadline
+	srv.SetLatency(protocol.FunctionGetLoggedInUser, time.Second)
+	_, err = client.GetLoggedInUser(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error: got %v, expected %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestBadHandshake(t *testing.T) {
+	srv := newServer(t)
+	srv.RejectHandshakes(1, http.StatusForbidden)
+
+	closed := make(chan error, 1)
+	connect(t, srv, protocol.ConnectToServerOpts{CloseHandler: func(err error) { closed <- err }})
+
+	select {
+	case err := <-closed:
+		var handshakeErr *protocol.ErrBadHandshake
+		if !errors.As(err, &handshakeErr) || handshakeErr.Resp.StatusCode != http.StatusForbidden {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("connection was not closed")
+	}
+}
+
+func TestResyncAfterDroppedConnection(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	srv := newServer(t)
+	srv.AddApplication(&protocol.Application{ID: "app", OwnerID: "user"})
+
+	reconnected := make(chan struct{}, 1)
+	client := connect(t, srv, protocol.ConnectToServerOpts{ReconnectionHandler: func() { reconnected <- struct{}{} }})
+
+	started, err := client.StartApplication(ctx, "app", &protocol.StartApplicationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates, err := client.InstanceUpdates(ctx, started.InstanceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// make the client learn the application of the instance
+	_ = srv.SetInstancePhase(started.InstanceID, protocol.PhaseCreating)
+	<-updates
+
+	srv.DropConnections()
+	// the client misses this update
+	_ = srv.SetInstancePhase(started.InstanceID, protocol.PhaseRunning)
+
+	select {
+	case <-reconnected:
+	case <-ctx.Done():
+		t.Fatal("client did not reconnect")
+	}
+	for {
+		select {
+		case inst := <-updates:
+			if inst.Phase() == protocol.PhaseRunning {
+				return
+			}
+		case <-ctx.Done():
+			t.Fatal("no snapshot after reconnect")
+		}
+	}
+}
+
+func TestTransports(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srv := newServer(t)
+
+	pipe := protocol.NewPipeTransport()
+	srv.Serve(pipe.Listener())
+
+	socket := filepath.Join(t.TempDir(), "bhojpur.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Serve(l)
+
+	tests := []struct {
+		Name      string
+		Transport protocol.Transport
+	}{
+		{"pipe", pipe},
+		{"unix socket", protocol.NewUnixSocketTransport(socket)},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			client, err := protocol.ConnectToServer("ws://localhost/api/bhojpur", protocol.ConnectToServerOpts{
+				Token:     token,
+				Log:       logrus.NewEntry(logrus.New()),
+				Transport: test.Transport,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			user, err := client.GetLoggedInUser(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.ID != "user" {
+				t.Errorf("unexpected user: %s", user.ID)
+			}
+		})
+	}
+}
+
+// rotatingCredentials return the old token until they're refreshed
+type rotatingCredentials struct {
+	old, new  string
+	refreshes int32
+}
+
+func (c *rotatingCredentials) Token(ctx context.Context) (string, error) {
+	if atomic.LoadInt32(&c.refreshes) > 0 {
+		return c.new, nil
+	}
+	return c.old, nil
+}
+
+func (c *rotatingCredentials) Refresh(ctx context.Context) (string, error) {
+	atomic.AddInt32(&c.refreshes, 1)
+	return c.new, nil
+}
+
+func TestCredentialRefresh(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	srv := newServer(t)
+	srv.AddToken("user", "new-token")
+	creds := &rotatingCredentials{old: token, new: "new-token"}
+
+	reconnected := make(chan struct{}, 1)
+	client := connect(t, srv, protocol.ConnectToServerOpts{
+		Credentials:         creds,
+		ReconnectionHandler: func() { reconnected <- struct{}{} },
+	})
+	_, err := client.GetLoggedInUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the old token expires, the handshake of the reconnect fails with 401
+	srv.RevokeToken(token)
+	srv.DropConnections()
+
+	select {
+	case <-reconnected:
+	case <-ctx.Done():
+		t.Fatal("client did not reconnect")
+	}
+	_, err = client.GetLoggedInUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&creds.refreshes); n != 1 {
+		t.Errorf("unexpected number of refreshes: %d", n)
+	}
+}
+
+func TestConnectionStates(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	srv := newServer(t)
+	client := connect(t, srv, protocol.ConnectToServerOpts{
+		ReconnectPolicy: protocol.ReconnectPolicy{MinDelay: 10 * time.Millisecond},
+	})
+	states := client.ConnectionStates(ctx)
+
+	waitFor := func(state protocol.ConnectionState) protocol.ConnectionStatus {
+		for {
+			select {
+			case st, ok := <-states:
+				if !ok {
+					t.Fatalf("states closed before %s", state)
+				}
+				if st.State == state {
+					return st
+				}
+			case <-ctx.Done():
+				t.Fatalf("connection did not become %s", state)
+			}
+		}
+	}
+
+	waitFor(protocol.StateConnected)
+	srv.DropConnections()
+	if st := waitFor(protocol.StateBackingOff); st.Err == nil {
+		t.Error("backing off without an error")
+	}
+	waitFor(protocol.StateConnected)
+
+	client.Close()
+	if st := waitFor(protocol.StateClosed); !errors.Is(st.Err, protocol.ErrClosed) {
+		t.Errorf("unexpected close error: %v", st.Err)
+	}
+	if st := client.ConnectionState(); st.State != protocol.StateClosed {
+		t.Errorf("unexpected state: %v", st.State)
+	}
+}
+
+func TestMessageLimits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	srv := newServer(t)
+	srv.AddApplication(&protocol.Application{ID: "large", OwnerID: "user", Description: strings.Repeat("x", 4096)})
+	client := connect(t, srv, protocol.ConnectToServerOpts{
+		// backing off long enough to observe it
+		ReconnectPolicy: protocol.ReconnectPolicy{MinDelay: time.Second, DisableJitter: true},
+		Websocket:       protocol.WebsocketOptions{ReadLimit: 2048, WriteLimit: 1024},
+	})
+	states := client.ConnectionStates(ctx)
+
+	var tooLarge *protocol.MessageTooLargeError
+	err := client.SetEnvVar(ctx, &protocol.UserEnvVarValue{Name: "FOO", Value: strings.Repeat("x", 2048)})
+	if !errors.As(err, &tooLarge) || tooLarge.Read {
+		t.Errorf("unexpected error: got %v, expected a write limit error", err)
+	}
+
+	// the response is dropped with the connection, so the call runs into its deadline
+	callCtx, callCancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer callCancel()
+	_, err = client.GetApplication(callCtx, "large")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error: got %v, expected %v", err, context.DeadlineExceeded)
+	}
+	for st := range states {
+		if st.State != protocol.StateBackingOff {
+			continue
+		}
+		if !errors.As(st.Err, &tooLarge) || !tooLarge.Read {
+			t.Errorf("unexpected reason for reconnect: %v", st.Err)
+		}
+		break
+	}
+
+	// the client is still usable for small messages
+	_, err = client.GetLoggedInUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+// generator produces the API interface, the function names, the JSON-RPC client
+// and its mock from the API description in api.json.
+//
+//	go run ./generator [-api api.json] [-out .]
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	// ClientFile is the file the interface, function names and client are generated into
+	ClientFile = "bhojpur-service_gen.go"
+	// MockFile is the file the mock is generated into
+	MockFile = "mock.go"
+)
+
+// API is the description of the server API
+type API struct {
+	// Functions are the functions of the server
+	Functions []Function `json:"functions"`
+	// LocalMethods are implemented by the client without calling the server. They are
+	// part of the interface and the mock, but not generated for the client.
+	LocalMethods []LocalMethod `json:"localMethods"`
+}
+
+// Function is a JSON-RPC function of the server
+type Function struct {
+	// Name is the name of the function on the server
+	Name string `json:"name"`
+	// GoName is the name of the method, defaults to the capitalised name
+	GoName string  `json:"goName,omitempty"`
+	Params []Param `json:"params,omitempty"`
+	// Result is nil for functions which don't return anything
+	Result *Result `json:"result,omitempty"`
+	// Listener marks functions which register a listener on the server. They are
+	// called again after a reconnect.
+	Listener bool `json:"listener,omitempty"`
+}
+
+// Param is a parameter of a function
+type Param struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Result is the result of a function
+type Result struct {
+	// Name is the name of the return value, defaults to res
+	Name string `json:"name,omitempty"`
+	Type string `json:"type"`
+}
+
+// LocalMethod is a method of the interface implemented by the client itself
+type LocalMethod struct {
+	GoName  string   `json:"goName"`
+	Params  []Param  `json:"params,omitempty"`
+	Results []string `json:"results"`
+}
+
+// Load reads and validates an API description
+func Load(fn string) (*API, error) {
+	fc, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	var api API
+	dec := json.NewDecoder(bytes.NewReader(fc))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&api)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", fn, err)
+	}
+
+	names := make(map[string]struct{})
+	for i, f := range api.Functions {
+		if f.Name == "" {
+			return nil, fmt.Errorf("function %d has no name", i)
+		}
+		if f.GoName == "" {
+			api.Functions[i].GoName = strings.ToUpper(f.Name[:1]) + f.Name[1:]
+		}
+		if f.Result != nil && f.Result.Name == "" {
+			f.Result.Name = "res"
+		}
+		for _, n := range []string{f.Name, api.Functions[i].GoName} {
+			if _, exists := names[n]; exists {
+				return nil, fmt.Errorf("function %s is declared twice", n)
+			}
+			names[n] = struct{}{}
+		}
+	}
+	for _, m := range api.LocalMethods {
+		if _, exists := names[m.GoName]; exists {
+			return nil, fmt.Errorf("method %s is declared twice", m.GoName)
+		}
+		names[m.GoName] = struct{}{}
+	}
+
+	return &api, nil
+}
+
+// Generate produces the content of the generated files by their name
+func Generate(api *API) (map[string][]byte, error) {
+	client, err := render(clientTemplate, api)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate %s: %w", ClientFile, err)
+	}
+	mock, err := render(mockTemplate, mockMethods(api))
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate %s: %w", MockFile, err)
+	}
+	return map[string][]byte{
+		ClientFile: client,
+		MockFile:   mock,
+	}, nil
+}
+
+func render(tpl *template.Template, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := tpl.Execute(&buf, data)
+	if err != nil {
+		return nil, err
+	}
+	res, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w\n%s", err, buf.String())
+	}
+	return res, nil
+}
+
+// mockMethod is a method of the interface as the mock sees it
+type mockMethod struct {
+	Name    string
+	Params  []Param
+	Results []string
+}
+
+// mockMethods returns all methods of the interface sorted by name, as mockgen does
+func mockMethods(api *API) []mockMethod {
+	var res []mockMethod
+	for _, f := range api.Functions {
+		m := mockMethod{Name: f.GoName, Params: f.Params}
+		if f.Result != nil {
+			m.Results = append(m.Results, f.Result.Type)
+		}
+		m.Results = append(m.Results, "error")
+		res = append(res, m)
+	}
+	for _, l := range api.LocalMethods {
+		res = append(res, mockMethod{Name: l.GoName, Params: l.Params, Results: l.Results})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+var funcs = template.FuncMap{
+	"deref": func(t string) string { return strings.TrimPrefix(t, "*") },
+	"isPtr": func(t string) bool { return strings.HasPrefix(t, "*") },
+	"params": func(ps []Param) string {
+		var res string
+		for _, p := range ps {
+			res += fmt.Sprintf(", %s %s", p.Name, p.Type)
+		}
+		return res
+	},
+	"args": func(ps []Param) string {
+		var res string
+		for _, p := range ps {
+			res += ", " + p.Name
+		}
+		return res
+	},
+	"results": func(rs []string) string {
+		if len(rs) == 1 {
+			return rs[0]
+		}
+		return "(" + strings.Join(rs, ", ") + ")"
+	},
+}
+
+const header = `// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+// Code generated by go run ./generator. DO NOT EDIT.
+// Source: api.json
+`
+
+var clientTemplate = template.Must(template.New("client").Funcs(funcs).Parse(header + `
+package protocol
+
+import (
+	"context"
+)
+
+// APIInterface wraps the Bhojpur server API
+type APIInterface interface {
+{{- range .Functions }}
+	{{ .GoName }}(ctx context.Context{{ params .Params }}) ({{ with .Result }}{{ .Name }} {{ .Type }}, {{ end }}err error)
+{{- end }}
+{{ range .LocalMethods }}
+	{{ .GoName }}(ctx context.Context{{ params .Params }}) {{ results .Results }}
+{{- end }}
+}
+
+var _ APIInterface = &APIoverJSONRPC{}
+
+// FunctionName is the name of an RPC function
+type FunctionName string
+
+const (
+{{- range .Functions }}
+	// Function{{ .GoName }} is the name of the {{ .Name }} function
+	Function{{ .GoName }} FunctionName = "{{ .Name }}"
+{{- end }}
+)
+{{ range .Functions }}{{ $fn := . }}
+// {{ .GoName }} calls {{ .Name }} on the server
+func (bp *APIoverJSONRPC) {{ .GoName }}(ctx context.Context{{ params .Params }}) ({{ with .Result }}{{ .Name }} {{ .Type }}, {{ end }}err error) {
+	if bp == nil {
+		err = errNotConnected
+		return
+	}
+	var _params []interface{}
+{{ range .Params }}
+	_params = append(_params, {{ .Name }})
+{{- end }}
+{{ with .Result }}
+	var result {{ deref .Type }}
+	err = bp.call(ctx, string(Function{{ $fn.GoName }}), _params, &result)
+	if err != nil {
+		return
+	}
+	{{ .Name }} = {{ if isPtr .Type }}&{{ end }}result
+{{- else }}
+	err = bp.call(ctx, string(Function{{ .GoName }}), _params, nil)
+	if err != nil {
+		return
+	}
+{{- end }}
+{{- if .Listener }}
+	bp.addListener(Function{{ .GoName }}, _params)
+{{- end }}
+
+	return
+}
+{{ end }}`))
+
+var mockTemplate = template.Must(template.New("mock").Funcs(funcs).Parse(header + `
+package protocol
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAPIInterface is a mock of APIInterface interface.
+type MockAPIInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIInterfaceMockRecorder
+}
+
+// MockAPIInterfaceMockRecorder is the mock recorder for MockAPIInterface.
+type MockAPIInterfaceMockRecorder struct {
+	mock *MockAPIInterface
+}
+
+// NewMockAPIInterface creates a new mock instance.
+func NewMockAPIInterface(ctrl *gomock.Controller) *MockAPIInterface {
+	mock := &MockAPIInterface{ctrl: ctrl}
+	mock.recorder = &MockAPIInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIInterface) EXPECT() *MockAPIInterfaceMockRecorder {
+	return m.recorder
+}
+{{ range . }}
+// {{ .Name }} mocks base method.
+func (m *MockAPIInterface) {{ .Name }}(ctx context.Context{{ params .Params }}) {{ results .Results }} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "{{ .Name }}", ctx{{ args .Params }})
+{{- range $i, $r := .Results }}
+	ret{{ $i }}, _ := ret[{{ $i }}].({{ $r }})
+{{- end }}
+	return {{ range $i, $r := .Results }}{{ if $i }}, {{ end }}ret{{ $i }}{{ end }}
+}
+
+// {{ .Name }} indicates an expected call of {{ .Name }}.
+func (mr *MockAPIInterfaceMockRecorder) {{ .Name }}(ctx{{ args .Params }} interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "{{ .Name }}", reflect.TypeOf((*MockAPIInterface)(nil).{{ .Name }}), ctx{{ args .Params }})
+}
+{{ end }}`))
+
+func main() {
+	apiFN := flag.String("api", "api.json", "API description")
+	out := flag.String("out", ".", "directory to generate the files into")
+	flag.Parse()
+
+	api, err := Load(*apiFN)
+	if err != nil {
+		log.Fatal(err)
+	}
+	files, err := Generate(api)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for fn, fc := range files {
+		err = os.WriteFile(filepath.Join(*out, fn), fc, 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
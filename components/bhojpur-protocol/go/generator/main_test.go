@@ -0,0 +1,35 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGeneratedCodeIsUpToDate fails if api.json was changed without running go generate,
+// or if the generated files were edited by hand.
+func TestGeneratedCodeIsUpToDate(t *testing.T) {
+	api, err := Load(filepath.Join("..", "api.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := Generate(api)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for fn, expectation := range files {
+		act, err := os.ReadFile(filepath.Join("..", fn))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(act, expectation) {
+			t.Errorf("%s is stale, run go generate", fn)
+		}
+	}
+}
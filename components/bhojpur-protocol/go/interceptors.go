@@ -0,0 +1,228 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Invoker performs a call on the server. Errors returned by the server are *Error.
+type Invoker func(ctx context.Context, method FunctionName, params, result interface{}) error
+
+// Interceptor wraps the calls to the server, e.g. to log them. It performs the call
+// by calling next, possibly several times.
+type Interceptor func(ctx context.Context, method FunctionName, params, result interface{}, next Invoker) error
+
+// chainInterceptors wraps an invoker in the interceptors. The first interceptor is the outermost.
+func chainInterceptors(invoker Invoker, interceptors []Interceptor) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, method FunctionName, params, result interface{}) error {
+			return interceptor(ctx, method, params, result, next)
+		}
+	}
+	return invoker
+}
+
+// call calls a function on the server through the interceptors
+func (bp *APIoverJSONRPC) call(ctx context.Context, method string, params, result interface{}) error {
+	if bp.invoker != nil {
+		return bp.invoker(ctx, FunctionName(method), params, result)
+	}
+	return bp.invoke(ctx, FunctionName(method), params, result)
+}
+
+// invoke calls a function on the server and translates its errors
+func (bp *APIoverJSONRPC) invoke(ctx context.Context, method FunctionName, params, result interface{}) error {
+	err := bp.C.Call(ctx, string(method), params, result)
+	if err != nil {
+		return translateError(method, err)
+	}
+	return nil
+}
+
+// idempotentFunctions are the functions which don't start with get but don't change anything either
+var idempotentFunctions = map[FunctionName]struct{}{
+	FunctionIsPrebuildDone:      {},
+	FunctionIsApplicationOwner:  {},
+	FunctionHasPermission:       {},
+	FunctionGuessGitTokenScopes: {},
+}
+
+// IsIdempotent is true if calling a function several times has the same effect as calling it once
+func IsIdempotent(method FunctionName) bool {
+	if strings.HasPrefix(string(method), "get") {
+		return true
+	}
+	_, ok := idempotentFunctions[method]
+	return ok
+}
+
+// DeadlineInterceptor sets a deadline on calls which don't have one. Timeouts of
+// individual functions, e.g. longer ones for waitForSnapshot, override the default.
+func DeadlineInterceptor(timeout time.Duration, timeouts map[FunctionName]time.Duration) Interceptor {
+	return func(ctx context.Context, method FunctionName, params, result interface{}, next Invoker) error {
+		if _, ok := ctx.Deadline(); ok {
+			return next(ctx, method, params, result)
+		}
+
+		t := timeout
+		if override, ok := timeouts[method]; ok {
+			t = override
+		}
+		if t <= 0 {
+			return next(ctx, method, params, result)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, t)
+		defer cancel()
+		return next(ctx, method, params, result)
+	}
+}
+
+// RetryOpts configures the RetryInterceptor
+type RetryOpts struct {
+	// MaxAttempts is the number of calls made at most, including the first one. Defaults to 3.
+	MaxAttempts int
+	// InitialBackoff is the upper bound of the wait before the first retry. Defaults to 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper bound of the wait between retries. Defaults to 5s.
+	MaxBackoff time.Duration
+	// Idempotent decides which functions are retried. Defaults to IsIdempotent.
+	Idempotent func(FunctionName) bool
+}
+
+// RetryInterceptor retries calls of idempotent functions which failed with a retryable
+// error (see IsRetryable), waiting a random time up to an exponentially growing backoff in between.
+func RetryInterceptor(opts RetryOpts) Interceptor {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = 100 * time.Millisecond
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 5 * time.Second
+	}
+	if opts.Idempotent == nil {
+		opts.Idempotent = IsIdempotent
+	}
+
+	return func(ctx context.Context, method FunctionName, params, result interface{}, next Invoker) error {
+		if !opts.Idempotent(method) {
+			return next(ctx, method, params, result)
+		}
+
+		backoff := opts.InitialBackoff
+		for attempt := 1; ; attempt++ {
+			err := next(ctx, method, params, result)
+			if err == nil || attempt >= opts.MaxAttempts || !IsRetryable(err) {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(time.Duration(rand.Int63n(int64(backoff) + 1))):
+			}
+			backoff *= 2
+			if backoff > opts.MaxBackoff {
+				backoff = opts.MaxBackoff
+			}
+		}
+	}
+}
+
+// LoggingInterceptor logs every call with its duration. Parameters and results are not
+// logged as they may contain secrets.
+func LoggingInterceptor(log *logrus.Entry) Interceptor {
+	return func(ctx context.Context, method FunctionName, params, result interface{}, next Invoker) error {
+		start := time.Now()
+		err := next(ctx, method, params, result)
+
+		l := log.WithField("method", method).WithField("duration", time.Since(start))
+		if err != nil {
+			l.WithError(err).Debug("call failed")
+		} else {
+			l.Debug("call succeeded")
+		}
+		return err
+	}
+}
+
+// ClientMetrics are Prometheus metrics of the calls to the server. Register them
+// and add their Interceptor to the client.
+type ClientMetrics struct {
+	calls    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewClientMetrics creates new client metrics
+func NewClientMetrics() *ClientMetrics {
+	return &ClientMetrics{
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "bhojpur",
+			Subsystem: "protocol_client",
+			Name:      "calls_total",
+			Help:      "Total number of calls to the server by function and result code",
+		}, []string{"method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "bhojpur",
+			Subsystem: "protocol_client",
+			Name:      "call_duration_seconds",
+			Help:      "Duration of calls to the server by function",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (m *ClientMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.calls.Describe(ch)
+	m.duration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (m *ClientMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.calls.Collect(ch)
+	m.duration.Collect(ch)
+}
+
+// Interceptor records the calls. Placed after a RetryInterceptor every attempt is counted.
+func (m *ClientMetrics) Interceptor() Interceptor {
+	return func(ctx context.Context, method FunctionName, params, result interface{}, next Invoker) error {
+		start := time.Now()
+		err := next(ctx, method, params, result)
+
+		m.duration.WithLabelValues(string(method)).Observe(time.Since(start).Seconds())
+		m.calls.WithLabelValues(string(method), resultCode(err)).Inc()
+		return err
+	}
+}
+
+// resultCode is the code label of a call
+func resultCode(err error) string {
+	var protoErr *Error
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.As(err, &protoErr):
+		return strconv.FormatInt(int64(protoErr.Code), 10)
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline_exceeded"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "transport"
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestChainInterceptors(t *testing.T) {
+	var order []string
+	record := func(name string) Interceptor {
+		return func(ctx context.Context, method FunctionName, params, result interface{}, next Invoker) error {
+			order = append(order, name)
+			return next(ctx, method, params, result)
+		}
+	}
+	invoker := chainInterceptors(func(ctx context.Context, method FunctionName, params, result interface{}) error {
+		order = append(order, "call")
+		return nil
+	}, []Interceptor{record("first"), record("second")})
+
+	err := invoker(context.Background(), FunctionGetLoggedInUser, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{"first", "second", "call"}; !reflect.DeepEqual(order, exp) {
+		t.Errorf("unexpected order: got %v, expected %v", order, exp)
+	}
+}
+
+func TestRetryInterceptor(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Method   FunctionName
+		Errors   []error
+		Attempts int
+	}{
+		{"success", FunctionGetApplication, nil, 1},
+		{"retry until success", FunctionGetApplication, []error{ErrTooManyRequests, ErrInternal}, 3},
+		{"give up", FunctionGetApplication, []error{ErrInternal, ErrInternal, ErrInternal, ErrInternal}, 3},
+		{"permanent error", FunctionGetApplication, []error{ErrPermissionDenied}, 1},
+		{"not idempotent", FunctionStartApplication, []error{ErrInternal}, 1},
+		{"idempotent without get", FunctionIsPrebuildDone, []error{ErrInternal}, 2},
+	}
+
+	retry := RetryInterceptor(RetryOpts{InitialBackoff: time.Millisecond})
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var attempts int
+			_ = retry(context.Background(), test.Method, nil, nil, func(ctx context.Context, method FunctionName, params, result interface{}) error {
+				attempts++
+				if attempts <= len(test.Errors) {
+					return test.Errors[attempts-1]
+				}
+				return nil
+			})
+			if attempts != test.Attempts {
+				t.Errorf("unexpected attempts: got %d, expected %d", attempts, test.Attempts)
+			}
+		})
+	}
+}
+
+func TestDeadlineInterceptor(t *testing.T) {
+	deadline := DeadlineInterceptor(time.Minute, map[FunctionName]time.Duration{FunctionTakeSnapshot: time.Hour})
+	timeout := func(ctx context.Context, method FunctionName) (res time.Duration) {
+		_ = deadline(ctx, method, nil, nil, func(ctx context.Context, method FunctionName, params, result interface{}) error {
+			d, ok := ctx.Deadline()
+			if ok {
+				res = time.Until(d).Round(time.Minute)
+			}
+			return nil
+		})
+		return
+	}
+
+	if act := timeout(context.Background(), FunctionGetApplication); act != time.Minute {
+		t.Errorf("unexpected default timeout: %v", act)
+	}
+	if act := timeout(context.Background(), FunctionTakeSnapshot); act != time.Hour {
+		t.Errorf("unexpected timeout override: %v", act)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	if act := timeout(ctx, FunctionGetApplication); act != 10*time.Minute {
+		t.Errorf("existing deadline was changed: %v", act)
+	}
+}
+
+func TestClientMetrics(t *testing.T) {
+	metrics := NewClientMetrics()
+	interceptor := metrics.Interceptor()
+	for _, err := range []error{nil, ErrNotFound, errors.New("connection reset")} {
+		_ = interceptor(context.Background(), FunctionGetApplication, nil, nil, func(ctx context.Context, method FunctionName, params, result interface{}) error {
+			return err
+		})
+	}
+
+	exp := `
+# HELP bhojpur_protocol_client_calls_total Total number of calls to the server by function and result code
+# TYPE bhojpur_protocol_client_calls_total counter
+bhojpur_protocol_client_calls_total{code="404",method="getApplication"} 1
+bhojpur_protocol_client_calls_total{code="ok",method="getApplication"} 1
+bhojpur_protocol_client_calls_total{code="transport",method="getApplication"} 1
+`
+	err := testutil.CollectAndCompare(metrics, strings.NewReader(exp), "bhojpur_protocol_client_calls_total")
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -2,10 +2,9 @@
 // Licensed under the GNU Affero General Public License (AGPL).
 // See License-AGPL.txt in the project root for license information.
 
-// Code generated by MockGen. DO NOT EDIT.
-// Source: bhojpur-service.go
+// Code generated by go run ./generator. DO NOT EDIT.
+// Source: api.json
 
-// Package protocol is a generated GoMock package.
 package protocol
 
 import (
@@ -39,17 +38,17 @@ func (m *MockAPIInterface) EXPECT() *MockAPIInterfaceMockRecorder {
 }
 
 // AdminBlockUser mocks base method.
-func (m *MockAPIInterface) AdminBlockUser(ctx context.Context, req *AdminBlockUserRequest) error {
+func (m *MockAPIInterface) AdminBlockUser(ctx context.Context, message *AdminBlockUserRequest) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AdminBlockUser", ctx, req)
+	ret := m.ctrl.Call(m, "AdminBlockUser", ctx, message)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AdminBlockUser indicates an expected call of AdminBlockUser.
-func (mr *MockAPIInterfaceMockRecorder) AdminBlockUser(ctx, req interface{}) *gomock.Call {
+func (mr *MockAPIInterfaceMockRecorder) AdminBlockUser(ctx, message interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminBlockUser", reflect.TypeOf((*MockAPIInterface)(nil).AdminBlockUser), ctx, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminBlockUser", reflect.TypeOf((*MockAPIInterface)(nil).AdminBlockUser), ctx, message)
 }
 
 // ClosePort mocks base method.
@@ -109,18 +108,18 @@ func (mr *MockAPIInterfaceMockRecorder) DeleteAccount(ctx interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockAPIInterface)(nil).DeleteAccount), ctx)
 }
 
-// DeleteEnvVar mocks base method.
-func (m *MockAPIInterface) DeleteEnvVar(ctx context.Context, variable *UserEnvVarValue) error {
+// DeleteApplication mocks base method.
+func (m *MockAPIInterface) DeleteApplication(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteEnvVar", ctx, variable)
+	ret := m.ctrl.Call(m, "DeleteApplication", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteEnvVar indicates an expected call of DeleteEnvVar.
-func (mr *MockAPIInterfaceMockRecorder) DeleteEnvVar(ctx, variable interface{}) *gomock.Call {
+// DeleteApplication indicates an expected call of DeleteApplication.
+func (mr *MockAPIInterfaceMockRecorder) DeleteApplication(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEnvVar", reflect.TypeOf((*MockAPIInterface)(nil).DeleteEnvVar), ctx, variable)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApplication", reflect.TypeOf((*MockAPIInterface)(nil).DeleteApplication), ctx, id)
 }
 
 // DeleteBhojpurToken mocks base method.
@@ -137,32 +136,32 @@ func (mr *MockAPIInterfaceMockRecorder) DeleteBhojpurToken(ctx, tokenHash interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBhojpurToken", reflect.TypeOf((*MockAPIInterface)(nil).DeleteBhojpurToken), ctx, tokenHash)
 }
 
-// DeleteOwnAuthProvider mocks base method.
-func (m *MockAPIInterface) DeleteOwnAuthProvider(ctx context.Context, params *DeleteOwnAuthProviderParams) error {
+// DeleteEnvVar mocks base method.
+func (m *MockAPIInterface) DeleteEnvVar(ctx context.Context, variable *UserEnvVarValue) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteOwnAuthProvider", ctx, params)
+	ret := m.ctrl.Call(m, "DeleteEnvVar", ctx, variable)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteOwnAuthProvider indicates an expected call of DeleteOwnAuthProvider.
-func (mr *MockAPIInterfaceMockRecorder) DeleteOwnAuthProvider(ctx, params interface{}) *gomock.Call {
+// DeleteEnvVar indicates an expected call of DeleteEnvVar.
+func (mr *MockAPIInterfaceMockRecorder) DeleteEnvVar(ctx, variable interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOwnAuthProvider", reflect.TypeOf((*MockAPIInterface)(nil).DeleteOwnAuthProvider), ctx, params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEnvVar", reflect.TypeOf((*MockAPIInterface)(nil).DeleteEnvVar), ctx, variable)
 }
 
-// DeleteApplication mocks base method.
-func (m *MockAPIInterface) DeleteApplication(ctx context.Context, id string) error {
+// DeleteOwnAuthProvider mocks base method.
+func (m *MockAPIInterface) DeleteOwnAuthProvider(ctx context.Context, params *DeleteOwnAuthProviderParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteApplication", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteOwnAuthProvider", ctx, params)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteApplication indicates an expected call of DeleteApplication.
-func (mr *MockAPIInterfaceMockRecorder) DeleteApplication(ctx, id interface{}) *gomock.Call {
+// DeleteOwnAuthProvider indicates an expected call of DeleteOwnAuthProvider.
+func (mr *MockAPIInterfaceMockRecorder) DeleteOwnAuthProvider(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApplication", reflect.TypeOf((*MockAPIInterface)(nil).DeleteApplication), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOwnAuthProvider", reflect.TypeOf((*MockAPIInterface)(nil).DeleteOwnAuthProvider), ctx, params)
 }
 
 // GenerateNewBhojpurToken mocks base method.
@@ -180,6 +179,81 @@ func (mr *MockAPIInterfaceMockRecorder) GenerateNewBhojpurToken(ctx, options int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateNewBhojpurToken", reflect.TypeOf((*MockAPIInterface)(nil).GenerateNewBhojpurToken), ctx, options)
 }
 
+// GetApplication mocks base method.
+func (m *MockAPIInterface) GetApplication(ctx context.Context, id string) (*ApplicationInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApplication", ctx, id)
+	ret0, _ := ret[0].(*ApplicationInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApplication indicates an expected call of GetApplication.
+func (mr *MockAPIInterfaceMockRecorder) GetApplication(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplication", reflect.TypeOf((*MockAPIInterface)(nil).GetApplication), ctx, id)
+}
+
+// GetApplicationOwner mocks base method.
+func (m *MockAPIInterface) GetApplicationOwner(ctx context.Context, applicationID string) (*UserInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApplicationOwner", ctx, applicationID)
+	ret0, _ := ret[0].(*UserInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApplicationOwner indicates an expected call of GetApplicationOwner.
+func (mr *MockAPIInterfaceMockRecorder) GetApplicationOwner(ctx, applicationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationOwner", reflect.TypeOf((*MockAPIInterface)(nil).GetApplicationOwner), ctx, applicationID)
+}
+
+// GetApplicationTimeout mocks base method.
+func (m *MockAPIInterface) GetApplicationTimeout(ctx context.Context, applicationID string) (*GetApplicationTimeoutResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApplicationTimeout", ctx, applicationID)
+	ret0, _ := ret[0].(*GetApplicationTimeoutResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApplicationTimeout indicates an expected call of GetApplicationTimeout.
+func (mr *MockAPIInterfaceMockRecorder) GetApplicationTimeout(ctx, applicationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationTimeout", reflect.TypeOf((*MockAPIInterface)(nil).GetApplicationTimeout), ctx, applicationID)
+}
+
+// GetApplicationUsers mocks base method.
+func (m *MockAPIInterface) GetApplicationUsers(ctx context.Context, applicationID string) ([]*ApplicationInstanceUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApplicationUsers", ctx, applicationID)
+	ret0, _ := ret[0].([]*ApplicationInstanceUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApplicationUsers indicates an expected call of GetApplicationUsers.
+func (mr *MockAPIInterfaceMockRecorder) GetApplicationUsers(ctx, applicationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationUsers", reflect.TypeOf((*MockAPIInterface)(nil).GetApplicationUsers), ctx, applicationID)
+}
+
+// GetApplications mocks base method.
+func (m *MockAPIInterface) GetApplications(ctx context.Context, options *GetApplicationsOptions) ([]*ApplicationInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApplications", ctx, options)
+	ret0, _ := ret[0].([]*ApplicationInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApplications indicates an expected call of GetApplications.
+func (mr *MockAPIInterfaceMockRecorder) GetApplications(ctx, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplications", reflect.TypeOf((*MockAPIInterface)(nil).GetApplications), ctx, options)
+}
+
 // GetAuthProviders mocks base method.
 func (m *MockAPIInterface) GetAuthProviders(ctx context.Context) ([]*AuthProviderInfo, error) {
 	m.ctrl.T.Helper()
@@ -195,6 +269,36 @@ func (mr *MockAPIInterfaceMockRecorder) GetAuthProviders(ctx interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthProviders", reflect.TypeOf((*MockAPIInterface)(nil).GetAuthProviders), ctx)
 }
 
+// GetBhojpurTokenScopes mocks base method.
+func (m *MockAPIInterface) GetBhojpurTokenScopes(ctx context.Context, tokenHash string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBhojpurTokenScopes", ctx, tokenHash)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBhojpurTokenScopes indicates an expected call of GetBhojpurTokenScopes.
+func (mr *MockAPIInterfaceMockRecorder) GetBhojpurTokenScopes(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBhojpurTokenScopes", reflect.TypeOf((*MockAPIInterface)(nil).GetBhojpurTokenScopes), ctx, tokenHash)
+}
+
+// GetBhojpurTokens mocks base method.
+func (m *MockAPIInterface) GetBhojpurTokens(ctx context.Context) ([]*APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBhojpurTokens", ctx)
+	ret0, _ := ret[0].([]*APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBhojpurTokens indicates an expected call of GetBhojpurTokens.
+func (mr *MockAPIInterfaceMockRecorder) GetBhojpurTokens(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBhojpurTokens", reflect.TypeOf((*MockAPIInterface)(nil).GetBhojpurTokens), ctx)
+}
+
 // GetBranding mocks base method.
 func (m *MockAPIInterface) GetBranding(ctx context.Context) (*Branding, error) {
 	m.ctrl.T.Helper()
@@ -300,21 +404,6 @@ func (mr *MockAPIInterfaceMockRecorder) GetFeaturedRepositories(ctx interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeaturedRepositories", reflect.TypeOf((*MockAPIInterface)(nil).GetFeaturedRepositories), ctx)
 }
 
-// GetBhojpurTokens mocks base method.
-func (m *MockAPIInterface) GetBhojpurTokens(ctx context.Context) ([]*APIToken, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBhojpurTokens", ctx)
-	ret0, _ := ret[0].([]*APIToken)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetBhojpurTokens indicates an expected call of GetBhojpurTokens.
-func (mr *MockAPIInterfaceMockRecorder) GetBhojpurTokens(ctx interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBhojpurTokens", reflect.TypeOf((*MockAPIInterface)(nil).GetBhojpurTokens), ctx)
-}
-
 // GetLayout mocks base method.
 func (m *MockAPIInterface) GetLayout(ctx context.Context, applicationID string) (string, error) {
 	m.ctrl.T.Helper()
@@ -405,21 +494,6 @@ func (mr *MockAPIInterfaceMockRecorder) GetSnapshots(ctx, applicationID interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSnapshots", reflect.TypeOf((*MockAPIInterface)(nil).GetSnapshots), ctx, applicationID)
 }
 
-// GetBhojpurTokenScopes indicates an expected call of GetBhojpurTokenScopes.
-func (mr *MockAPIInterfaceMockRecorder) GetBhojpurTokenScopes(ctx, tokenHash interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBhojpurTokenScopes", reflect.TypeOf((*MockAPIInterface)(nil).GetBhojpurTokenScopes), ctx, tokenHash)
-}
-
-// GetBhojpurTokenScopes mocks base method.
-func (m *MockAPIInterface) GetBhojpurTokenScopes(ctx context.Context, tokenHash string) ([]string, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBhojpurTokenScopes", ctx, tokenHash)
-	ret0, _ := ret[0].([]string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
 // GetToken mocks base method.
 func (m *MockAPIInterface) GetToken(ctx context.Context, query *GetTokenSearchOptions) (*Token, error) {
 	m.ctrl.T.Helper()
@@ -450,81 +524,6 @@ func (mr *MockAPIInterfaceMockRecorder) GetUserStorageResource(ctx, options inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserStorageResource", reflect.TypeOf((*MockAPIInterface)(nil).GetUserStorageResource), ctx, options)
 }
 
-// GetApplication mocks base method.
-func (m *MockAPIInterface) GetApplication(ctx context.Context, id string) (*ApplicationInfo, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetApplication", ctx, id)
-	ret0, _ := ret[0].(*ApplicationInfo)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetApplication indicates an expected call of GetApplication.
-func (mr *MockAPIInterfaceMockRecorder) GetApplication(ctx, id interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplication", reflect.TypeOf((*MockAPIInterface)(nil).GetApplication), ctx, id)
-}
-
-// GetApplicationOwner mocks base method.
-func (m *MockAPIInterface) GetApplicationOwner(ctx context.Context, applicationID string) (*UserInfo, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetApplicationOwner", ctx, applicationID)
-	ret0, _ := ret[0].(*UserInfo)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetApplicationOwner indicates an expected call of GetApplicationOwner.
-func (mr *MockAPIInterfaceMockRecorder) GetApplicationOwner(ctx, applicationID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationOwner", reflect.TypeOf((*MockAPIInterface)(nil).GetApplicationOwner), ctx, applicationID)
-}
-
-// GetApplicationTimeout mocks base method.
-func (m *MockAPIInterface) GetApplicationTimeout(ctx context.Context, applicationID string) (*GetApplicationTimeoutResult, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetApplicationTimeout", ctx, applicationID)
-	ret0, _ := ret[0].(*GetApplicationTimeoutResult)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetApplicationTimeout indicates an expected call of GetApplicationTimeout.
-func (mr *MockAPIInterfaceMockRecorder) GetApplicationTimeout(ctx, applicationID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationTimeout", reflect.TypeOf((*MockAPIInterface)(nil).GetApplicationTimeout), ctx, applicationID)
-}
-
-// GetApplicationUsers mocks base method.
-func (m *MockAPIInterface) GetApplicationUsers(ctx context.Context, applicationID string) ([]*ApplicationInstanceUser, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetApplicationUsers", ctx, applicationID)
-	ret0, _ := ret[0].([]*ApplicationInstanceUser)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetApplicationUsers indicates an expected call of GetApplicationUsers.
-func (mr *MockAPIInterfaceMockRecorder) GetApplicationUsers(ctx, applicationID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationUsers", reflect.TypeOf((*MockAPIInterface)(nil).GetApplicationUsers), ctx, applicationID)
-}
-
-// GetApplications mocks base method.
-func (m *MockAPIInterface) GetApplications(ctx context.Context, options *GetApplicationsOptions) ([]*ApplicationInfo, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetApplications", ctx, options)
-	ret0, _ := ret[0].([]*ApplicationInfo)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetApplications indicates an expected call of GetApplications.
-func (mr *MockAPIInterfaceMockRecorder) GetApplications(ctx, options interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplications", reflect.TypeOf((*MockAPIInterface)(nil).GetApplications), ctx, options)
-}
-
 // GuessGitTokenScopes mocks base method.
 func (m *MockAPIInterface) GuessGitTokenScopes(ctx context.Context, params *GuessGitTokenScopesParams) (*GuessedGitTokenScopes, error) {
 	m.ctrl.T.Helper()
@@ -585,34 +584,34 @@ func (mr *MockAPIInterfaceMockRecorder) InstanceUpdates(ctx, instanceID interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstanceUpdates", reflect.TypeOf((*MockAPIInterface)(nil).InstanceUpdates), ctx, instanceID)
 }
 
-// IsPrebuildDone mocks base method.
-func (m *MockAPIInterface) IsPrebuildDone(ctx context.Context, pwsid string) (bool, error) {
+// IsApplicationOwner mocks base method.
+func (m *MockAPIInterface) IsApplicationOwner(ctx context.Context, applicationID string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsPrebuildDone", ctx, pwsid)
+	ret := m.ctrl.Call(m, "IsApplicationOwner", ctx, applicationID)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// IsPrebuildDone indicates an expected call of IsPrebuildDone.
-func (mr *MockAPIInterfaceMockRecorder) IsPrebuildDone(ctx, pwsid interface{}) *gomock.Call {
+// IsApplicationOwner indicates an expected call of IsApplicationOwner.
+func (mr *MockAPIInterfaceMockRecorder) IsApplicationOwner(ctx, applicationID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPrebuildDone", reflect.TypeOf((*MockAPIInterface)(nil).IsPrebuildDone), ctx, pwsid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsApplicationOwner", reflect.TypeOf((*MockAPIInterface)(nil).IsApplicationOwner), ctx, applicationID)
 }
 
-// IsApplicationOwner mocks base method.
-func (m *MockAPIInterface) IsApplicationOwner(ctx context.Context, applicationID string) (bool, error) {
+// IsPrebuildDone mocks base method.
+func (m *MockAPIInterface) IsPrebuildDone(ctx context.Context, pwsid string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsApplicationOwner", ctx, applicationID)
+	ret := m.ctrl.Call(m, "IsPrebuildDone", ctx, pwsid)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// IsApplicationOwner indicates an expected call of IsApplicationOwner.
-func (mr *MockAPIInterfaceMockRecorder) IsApplicationOwner(ctx, applicationID interface{}) *gomock.Call {
+// IsPrebuildDone indicates an expected call of IsPrebuildDone.
+func (mr *MockAPIInterfaceMockRecorder) IsPrebuildDone(ctx, pwsid interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsApplicationOwner", reflect.TypeOf((*MockAPIInterface)(nil).IsApplicationOwner), ctx, applicationID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPrebuildDone", reflect.TypeOf((*MockAPIInterface)(nil).IsPrebuildDone), ctx, pwsid)
 }
 
 // OpenPort mocks base method.
@@ -703,22 +702,8 @@ func (mr *MockAPIInterfaceMockRecorder) SendHeartBeat(ctx, options interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeartBeat", reflect.TypeOf((*MockAPIInterface)(nil).SendHeartBeat), ctx, options)
 }
 
-// SetEnvVar mocks base method.
-func (m *MockAPIInterface) SetEnvVar(ctx context.Context, variable *UserEnvVarValue) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetEnvVar", ctx, variable)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// SetEnvVar indicates an expected call of SetEnvVar.
-func (mr *MockAPIInterfaceMockRecorder) SetEnvVar(ctx, variable interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEnvVar", reflect.TypeOf((*MockAPIInterface)(nil).SetEnvVar), ctx, variable)
-}
-
 // SetApplicationDescription mocks base method.
-func (m *MockAPIInterface) SetApplicationDescription(ctx context.Context, id, desc string) error {
+func (m *MockAPIInterface) SetApplicationDescription(ctx context.Context, id string, desc string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SetApplicationDescription", ctx, id, desc)
 	ret0, _ := ret[0].(error)
@@ -746,6 +731,20 @@ func (mr *MockAPIInterfaceMockRecorder) SetApplicationTimeout(ctx, applicationID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetApplicationTimeout", reflect.TypeOf((*MockAPIInterface)(nil).SetApplicationTimeout), ctx, applicationID, duration)
 }
 
+// SetEnvVar mocks base method.
+func (m *MockAPIInterface) SetEnvVar(ctx context.Context, variable *UserEnvVarValue) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEnvVar", ctx, variable)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEnvVar indicates an expected call of SetEnvVar.
+func (mr *MockAPIInterfaceMockRecorder) SetEnvVar(ctx, variable interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEnvVar", reflect.TypeOf((*MockAPIInterface)(nil).SetEnvVar), ctx, variable)
+}
+
 // StartApplication mocks base method.
 func (m *MockAPIInterface) StartApplication(ctx context.Context, id string, options *StartApplicationOptions) (*StartApplicationResult, error) {
 	m.ctrl.T.Helper()
@@ -776,7 +775,7 @@ func (mr *MockAPIInterfaceMockRecorder) StopApplication(ctx, id interface{}) *go
 }
 
 // StoreLayout mocks base method.
-func (m *MockAPIInterface) StoreLayout(ctx context.Context, applicationID, layoutData string) error {
+func (m *MockAPIInterface) StoreLayout(ctx context.Context, applicationID string, layoutData string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "StoreLayout", ctx, applicationID, layoutData)
 	ret0, _ := ret[0].(error)
@@ -804,20 +803,6 @@ func (mr *MockAPIInterfaceMockRecorder) TakeSnapshot(ctx, options interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeSnapshot", reflect.TypeOf((*MockAPIInterface)(nil).TakeSnapshot), ctx, options)
 }
 
-// WaitForSnapshot mocks base method.
-func (m *MockAPIInterface) WaitForSnapshot(ctx context.Context, snapshotId string) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WaitForSnapshot", ctx, snapshotId)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// WaitForSnapshot indicates an expected call of WaitForSnapshot.
-func (mr *MockAPIInterfaceMockRecorder) WaitForSnapshot(ctx, options interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForSnapshot", reflect.TypeOf((*MockAPIInterface)(nil).WaitForSnapshot), ctx, options)
-}
-
 // UninstallUserPlugin mocks base method.
 func (m *MockAPIInterface) UninstallUserPlugin(ctx context.Context, params *UninstallPluginParams) (bool, error) {
 	m.ctrl.T.Helper()
@@ -833,6 +818,20 @@ func (mr *MockAPIInterfaceMockRecorder) UninstallUserPlugin(ctx, params interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UninstallUserPlugin", reflect.TypeOf((*MockAPIInterface)(nil).UninstallUserPlugin), ctx, params)
 }
 
+// UpdateApplicationUserPin mocks base method.
+func (m *MockAPIInterface) UpdateApplicationUserPin(ctx context.Context, id string, action *PinAction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateApplicationUserPin", ctx, id, action)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateApplicationUserPin indicates an expected call of UpdateApplicationUserPin.
+func (mr *MockAPIInterfaceMockRecorder) UpdateApplicationUserPin(ctx, id, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateApplicationUserPin", reflect.TypeOf((*MockAPIInterface)(nil).UpdateApplicationUserPin), ctx, id, action)
+}
+
 // UpdateLoggedInUser mocks base method.
 func (m *MockAPIInterface) UpdateLoggedInUser(ctx context.Context, user *User) (*User, error) {
 	m.ctrl.T.Helper()
@@ -876,18 +875,18 @@ func (mr *MockAPIInterfaceMockRecorder) UpdateUserStorageResource(ctx, options i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserStorageResource", reflect.TypeOf((*MockAPIInterface)(nil).UpdateUserStorageResource), ctx, options)
 }
 
-// UpdateApplicationUserPin mocks base method.
-func (m *MockAPIInterface) UpdateApplicationUserPin(ctx context.Context, id string, action *PinAction) error {
+// WaitForSnapshot mocks base method.
+func (m *MockAPIInterface) WaitForSnapshot(ctx context.Context, snapshotId string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateApplicationUserPin", ctx, id, action)
+	ret := m.ctrl.Call(m, "WaitForSnapshot", ctx, snapshotId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateApplicationUserPin indicates an expected call of UpdateApplicationUserPin.
-func (mr *MockAPIInterfaceMockRecorder) UpdateApplicationUserPin(ctx, id, action interface{}) *gomock.Call {
+// WaitForSnapshot indicates an expected call of WaitForSnapshot.
+func (mr *MockAPIInterfaceMockRecorder) WaitForSnapshot(ctx, snapshotId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateApplicationUserPin", reflect.TypeOf((*MockAPIInterface)(nil).UpdateApplicationUserPin), ctx, id, action)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForSnapshot", reflect.TypeOf((*MockAPIInterface)(nil).WaitForSnapshot), ctx, snapshotId)
 }
 
 // WatchApplicationImageBuildLogs mocks base method.
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net/http"
 	"sync"
 	"time"
@@ -17,6 +18,10 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// defaultCredentialTimeout is the time credential providers have to return a token,
+// e.g. a credential plugin which hangs must not stall reconnects forever
+const defaultCredentialTimeout = 30 * time.Second
+
 // ErrClosed is returned when the reconnecting web socket is closed.
 var ErrClosed = errors.New("reconnecting-ws: closed")
 
@@ -32,15 +37,85 @@ func (e *ErrBadHandshake) Error() string {
 	return fmt.Sprintf("reconnecting-ws: bad handshake: code %v - URL: %v - headers: %v", e.Resp.StatusCode, e.URL, e.ReqHeader)
 }
 
+// ReconnectPolicy configures how a ReconnectingWebsocket connects. Zero fields take the
+// value of DefaultReconnectPolicy.
+type ReconnectPolicy struct {
+	// MinDelay is the backoff after the first failed attempt
+	MinDelay time.Duration
+	// MaxDelay caps the backoff
+	MaxDelay time.Duration
+	// GrowFactor multiplies the backoff after every failed attempt
+	GrowFactor float64
+	// HandshakeTimeout is the time the server has to complete the websocket handshake
+	HandshakeTimeout time.Duration
+	// MaxBadHandshakes is the number of handshakes the server may fail with a 5xx status before we give up
+	MaxBadHandshakes uint8
+	// DisableJitter waits for the full backoff. By default we wait a random time up to the
+	// backoff, so that clients disconnected at the same time don't reconnect at the same time.
+	DisableJitter bool
+}
+
+// DefaultReconnectPolicy returns the policy used unless configured otherwise
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		MinDelay:         2 * time.Second,
+		MaxDelay:         30 * time.Second,
+		GrowFactor:       1.5,
+		HandshakeTimeout: 2 * time.Second,
+		MaxBadHandshakes: 15,
+	}
+}
+
+// withDefaults returns the policy with the zero fields set to their defaults
+func (p ReconnectPolicy) withDefaults() ReconnectPolicy {
+	def := DefaultReconnectPolicy()
+	if p.MinDelay <= 0 {
+		p.MinDelay = def.MinDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = def.MaxDelay
+	}
+	if p.MaxDelay < p.MinDelay {
+		p.MaxDelay = p.MinDelay
+	}
+	if p.GrowFactor < 1 {
+		p.GrowFactor = def.GrowFactor
+	}
+	if p.HandshakeTimeout <= 0 {
+		p.HandshakeTimeout = def.HandshakeTimeout
+	}
+	if p.MaxBadHandshakes == 0 {
+		p.MaxBadHandshakes = def.MaxBadHandshakes
+	}
+	return p
+}
+
+// nextDelay returns the backoff after delay
+func (p ReconnectPolicy) nextDelay(delay time.Duration) time.Duration {
+	delay = time.Duration(float64(delay) * p.GrowFactor)
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// wait returns the time to wait for a backoff
+func (p ReconnectPolicy) wait(backoff time.Duration) time.Duration {
+	if p.DisableJitter || backoff <= 0 {
+		return backoff
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
 // The ReconnectingWebsocket represents a Reconnecting WebSocket connection.
 type ReconnectingWebsocket struct {
-	url              string
-	reqHeader        http.Header
-	handshakeTimeout time.Duration
+	url       string
+	reqHeader http.Header
+
+	// Policy configures the delays between attempts. Set it before calling Dial.
+	Policy ReconnectPolicy
 
-	minReconnectionDelay        time.Duration
-	maxReconnectionDelay        time.Duration
-	reconnectionDelayGrowFactor float64
+	state connectionStates
 
 	once     sync.Once
 	closeErr error
@@ -52,28 +127,43 @@ type ReconnectingWebsocket struct {
 
 	ReconnectionHandler func()
 
+	// Transport opens the connections, defaults to NewWebsocketTransport(nil, nil)
+	Transport Transport
+	// Credentials provide the bearer token sent on every connect, if set
+	Credentials CredentialProvider
+	// CredentialTimeout limits the time to get the token on every connect, defaults to defaultCredentialTimeout
+	CredentialTimeout time.Duration
+	// ConnectionOptions configure the ping/pong intervals and size limits of every connection
+	ConnectionOptions WebsocketOptions
+
 	badHandshakeCount uint8
-	badHandshakeMax   uint8
 }
 
 // NewReconnectingWebsocket creates a new instance of ReconnectingWebsocket
 func NewReconnectingWebsocket(url string, reqHeader http.Header, log *logrus.Entry) *ReconnectingWebsocket {
 	return &ReconnectingWebsocket{
-		url:                         url,
-		reqHeader:                   reqHeader,
-		minReconnectionDelay:        2 * time.Second,
-		maxReconnectionDelay:        30 * time.Second,
-		reconnectionDelayGrowFactor: 1.5,
-		handshakeTimeout:            2 * time.Second,
-		connCh:                      make(chan chan *WebsocketConnection),
-		closedCh:                    make(chan struct{}),
-		errCh:                       make(chan error),
-		log:                         log,
-		badHandshakeCount:           0,
-		badHandshakeMax:             15,
+		url:               url,
+		reqHeader:         reqHeader,
+		Policy:            DefaultReconnectPolicy(),
+		connCh:            make(chan chan *WebsocketConnection),
+		closedCh:          make(chan struct{}),
+		errCh:             make(chan error),
+		log:               log,
+		badHandshakeCount: 0,
 	}
 }
 
+// State returns the current state of the connection
+func (rc *ReconnectingWebsocket) State() ConnectionStatus {
+	return rc.state.get()
+}
+
+// States streams the state of the connection, starting with the current one. Slow readers
+// only get the latest state. The channel is closed once the connection is closed or ctx is done.
+func (rc *ReconnectingWebsocket) States(ctx context.Context) <-chan ConnectionStatus {
+	return rc.state.subscribe(ctx)
+}
+
 // Close closes the underlying webscoket connection.
 func (rc *ReconnectingWebsocket) Close() error {
 	return rc.closeWithError(ErrClosed)
@@ -83,6 +173,7 @@ func (rc *ReconnectingWebsocket) closeWithError(closeErr error) error {
 	rc.once.Do(func() {
 		rc.closeErr = closeErr
 		close(rc.closedCh)
+		rc.state.close(closeErr)
 	})
 	return nil
 }
@@ -134,7 +225,8 @@ func isJSONError(err error) bool {
 func (rc *ReconnectingWebsocket) WriteObject(v interface{}) error {
 	return rc.EnsureConnection(func(conn *WebsocketConnection) (bool, error) {
 		err := conn.WriteJSON(v)
-		closed := err != nil && !isJSONError(err)
+		var tooLarge *MessageTooLargeError
+		closed := err != nil && !isJSONError(err) && !errors.As(err, &tooLarge)
 		return closed, err
 	})
 }
@@ -144,6 +236,11 @@ func (rc *ReconnectingWebsocket) WriteObject(v interface{}) error {
 func (rc *ReconnectingWebsocket) ReadObject(v interface{}) error {
 	return rc.EnsureConnection(func(conn *WebsocketConnection) (bool, error) {
 		err := conn.ReadJSON(v)
+		var tooLarge *MessageTooLargeError
+		if errors.As(err, &tooLarge) {
+			// the connection is closed and the response is lost, calls waiting for it run into their deadline
+			rc.log.WithError(err).WithField("url", rc.url).Error("server sent a message which is too large, reconnecting")
+		}
 		closed := err != nil && !isJSONError(err)
 		return closed, err
 	})
@@ -160,6 +257,7 @@ func (rc *ReconnectingWebsocket) Dial(ctx context.Context) error {
 		conn.Close()
 	}()
 
+	rc.Policy = rc.Policy.withDefaults()
 	conn = rc.connect(ctx)
 
 	for {
@@ -168,10 +266,17 @@ func (rc *ReconnectingWebsocket) Dial(ctx context.Context) error {
 			return rc.closeErr
 		case connCh := <-rc.connCh:
 			connCh <- conn
-		case <-rc.errCh:
+		case err := <-rc.errCh:
 			conn.Close()
 
-			time.Sleep(1 * time.Second)
+			// all clients of a restarting server lose their connection at the same time
+			wait := rc.Policy.wait(rc.Policy.MinDelay)
+			rc.state.set(ConnectionStatus{State: StateBackingOff, Err: err, Delay: wait})
+			select {
+			case <-rc.closedCh:
+				return rc.closeErr
+			case <-time.After(wait):
+			}
 			conn = rc.connect(ctx)
 			if conn != nil && rc.ReconnectionHandler != nil {
 				go rc.ReconnectionHandler()
@@ -181,7 +286,10 @@ func (rc *ReconnectingWebsocket) Dial(ctx context.Context) error {
 }
 
 func (rc *ReconnectingWebsocket) connect(ctx context.Context) *WebsocketConnection {
-	delay := rc.minReconnectionDelay
+	delay := rc.Policy.MinDelay
+	var attempt int
+	// refresh is set when the server rejected the credentials, they're refreshed only once
+	var refresh, refreshed bool
 	for {
 		// Gorilla websocket does not check if context is valid when dialing so we do it prior
 		select {
@@ -192,25 +300,54 @@ func (rc *ReconnectingWebsocket) connect(ctx context.Context) *WebsocketConnecti
 		default:
 		}
 
-		dialer := websocket.Dialer{HandshakeTimeout: rc.handshakeTimeout}
-		conn, resp, err := dialer.DialContext(ctx, rc.url, rc.reqHeader)
+		attempt++
+		rc.state.set(ConnectionStatus{State: StateConnecting, Attempt: attempt})
+
+		transport := rc.Transport
+		if transport == nil {
+			transport = NewWebsocketTransport(nil, nil)
+		}
+		reqHeader, err := rc.header(ctx, refresh)
+		refresh = false
+		if err != nil {
+			wait := rc.Policy.wait(delay)
+			rc.log.WithError(err).WithField("url", rc.url).Errorf("cannot get credentials, trying again in %d seconds...", uint32(wait.Seconds()))
+			if !rc.backOff(ConnectionStatus{State: StateBackingOff, Err: err, Attempt: attempt, Delay: wait}) {
+				return nil
+			}
+			delay = rc.Policy.nextDelay(delay)
+			continue
+		}
+
+		dialCtx, cancel := context.WithTimeout(ctx, rc.Policy.HandshakeTimeout)
+		conn, resp, err := transport.Dial(dialCtx, rc.url, reqHeader)
+		cancel()
 		if err == nil {
 			rc.log.WithField("url", rc.url).Debug("connection was successfully established")
-			ws, err := NewWebsocketConnection(context.Background(), conn, func(staleErr error) {
+			var ws *WebsocketConnection
+			ws, err = NewWebsocketConnectionWithOptions(context.Background(), conn, rc.ConnectionOptions, func(staleErr error) {
 				rc.errCh <- staleErr
 			})
 			if err == nil {
 				rc.badHandshakeCount = 0
+				rc.state.set(ConnectionStatus{State: StateConnected, Attempt: attempt})
 				return ws
 			}
+			conn.Close()
 		}
 
+		if err == websocket.ErrBadHandshake && resp.StatusCode == http.StatusUnauthorized && rc.Credentials != nil && !refreshed {
+			// the token might have expired, we get a new one and try once more
+			rc.log.WithField("url", rc.url).Info("server rejected credentials, refreshing them")
+			refresh, refreshed = true, true
+			continue
+		}
 		if err == websocket.ErrBadHandshake {
 			rc.badHandshakeCount++
 			// if mal-formed handshake request (unauthorized, forbidden) or client actions (redirect) are required then fail immediately
 			// otherwise try several times and fail, maybe temporarily unavailable, like server restart
-			if rc.badHandshakeCount > rc.badHandshakeMax || (http.StatusMultipleChoices <= resp.StatusCode && resp.StatusCode < http.StatusInternalServerError) {
-				_ = rc.closeWithError(&ErrBadHandshake{rc.url, rc.reqHeader, resp})
+			if rc.badHandshakeCount > rc.Policy.MaxBadHandshakes || (http.StatusMultipleChoices <= resp.StatusCode && resp.StatusCode < http.StatusInternalServerError) {
+				_ = rc.closeWithError(&ErrBadHandshake{rc.url, reqHeader, resp})
 				return nil
 			}
 		}
@@ -220,19 +357,60 @@ func (rc *ReconnectingWebsocket) connect(ctx context.Context) *WebsocketConnecti
 		}
 		rc.log.WithField("url", rc.url).Info("websocket handshake")
 
+		wait := rc.Policy.wait(delay)
 		rc.log.WithError(err).
 			WithField("url", rc.url).
-			WithField("badHandshakeCount", fmt.Sprintf("%d/%d", rc.badHandshakeCount, rc.badHandshakeMax)).
+			WithField("badHandshakeCount", fmt.Sprintf("%d/%d", rc.badHandshakeCount, rc.Policy.MaxBadHandshakes)).
 			WithField("statusCode", statusCode).
-			Errorf("failed to connect, trying again in %d seconds...", uint32(delay.Seconds()))
-		select {
-		case <-rc.closedCh:
+			Errorf("failed to connect, trying again in %d seconds...", uint32(wait.Seconds()))
+		if !rc.backOff(ConnectionStatus{State: StateBackingOff, Err: err, Attempt: attempt, Delay: wait}) {
 			return nil
-		case <-time.After(delay):
-			delay = time.Duration(float64(delay) * rc.reconnectionDelayGrowFactor)
-			if delay > rc.maxReconnectionDelay {
-				delay = rc.maxReconnectionDelay
-			}
 		}
+		delay = rc.Policy.nextDelay(delay)
+	}
+}
+
+// backOff waits before the next attempt to connect. It returns false if the websocket was closed meanwhile.
+func (rc *ReconnectingWebsocket) backOff(status ConnectionStatus) bool {
+	rc.state.set(status)
+	select {
+	case <-rc.closedCh:
+		return false
+	case <-time.After(status.Delay):
+		return true
+	}
+}
+
+// header returns the request header for a connect, with a token from the credentials
+func (rc *ReconnectingWebsocket) header(ctx context.Context, refresh bool) (http.Header, error) {
+	if rc.Credentials == nil {
+		return rc.reqHeader, nil
+	}
+
+	timeout := rc.CredentialTimeout
+	if timeout <= 0 {
+		timeout = defaultCredentialTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		token string
+		err   error
+	)
+	if refresh {
+		token, err = rc.Credentials.Refresh(ctx)
+	} else {
+		token, err = rc.Credentials.Token(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := rc.reqHeader.Clone()
+	if res == nil {
+		res = http.Header{}
 	}
+	res.Set("Authorization", "Bearer "+token)
+	return res, nil
 }
@@ -0,0 +1,134 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// listener is a call which registered a listener on the server
+type listener struct {
+	Method FunctionName
+	Params []interface{}
+}
+
+// listenerDone tells if an instance update ends a listener, so that it's not registered again
+var listenerDone = map[FunctionName]func(l listener, instance *ApplicationInstance) bool{
+	// the image is built while the instance is preparing, there are no more logs afterwards
+	FunctionWatchApplicationImageBuildLogs: func(l listener, instance *ApplicationInstance) bool {
+		return len(l.Params) > 0 && l.Params[0] == instance.ApplicationID && phaseOrder[instance.Phase()] > phaseOrder[PhasePreparing]
+	},
+}
+
+// addListener remembers a call which registered a listener on the server, so that
+// it can be made again after a reconnect
+func (bp *APIoverJSONRPC) addListener(method FunctionName, params []interface{}) {
+	key, err := json.Marshal(params)
+	if err != nil {
+		bp.log.WithError(err).WithField("method", method).Warn("cannot remember listener")
+		return
+	}
+
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	if bp.listeners == nil {
+		bp.listeners = make(map[string]listener)
+	}
+	bp.listeners[string(method)+string(key)] = listener{Method: method, Params: params}
+}
+
+// endListeners forgets the listeners the instance update ends. Callers must hold the lock.
+func (bp *APIoverJSONRPC) endListeners(instance *ApplicationInstance) {
+	for key, l := range bp.listeners {
+		done, ok := listenerDone[l.Method]
+		if ok && done(l, instance) {
+			delete(bp.listeners, key)
+		}
+	}
+}
+
+// resubscribe restores the state of a connection after a reconnect. The server
+// forgets about a client when the connection drops, so we authenticate again,
+// register the listeners again and send a snapshot of every subscribed instance,
+// because the updates sent while disconnected are lost.
+func (bp *APIoverJSONRPC) resubscribe(ctx context.Context) {
+	// The token is sent again on every connect, this makes sure it's still accepted
+	_, err := bp.GetLoggedInUser(ctx)
+	if errors.Is(err, ErrNotAuthenticated) {
+		bp.log.WithError(err).Error("cannot authenticate after reconnect")
+		return
+	}
+	if err != nil {
+		bp.log.WithError(err).Warn("cannot verify authentication after reconnect")
+	}
+
+	bp.mu.RLock()
+	listeners := make([]listener, 0, len(bp.listeners))
+	for _, l := range bp.listeners {
+		listeners = append(listeners, l)
+	}
+	// instance ID -> application ID, empty if we don't know it
+	instances := make(map[string]string)
+	for instanceID, subs := range bp.subs {
+		if instanceID == "" || len(subs) == 0 {
+			continue
+		}
+		instances[instanceID] = bp.instanceApps[instanceID]
+	}
+	if len(bp.subs[""]) > 0 || bp.watcher != nil {
+		// everyone listening to all instances has seen these
+		for instanceID, applicationID := range bp.instanceApps {
+			instances[instanceID] = applicationID
+		}
+	}
+	bp.mu.RUnlock()
+
+	for _, l := range listeners {
+		err := bp.call(ctx, string(l.Method), l.Params, nil)
+		if err != nil {
+			bp.log.WithError(err).WithField("method", l.Method).Warn("cannot register listener after reconnect")
+		}
+	}
+
+	var unknown bool
+	for instanceID, applicationID := range instances {
+		if applicationID == "" {
+			unknown = true
+			continue
+		}
+
+		info, err := bp.GetApplication(ctx, applicationID)
+		if err != nil {
+			bp.log.WithError(err).WithField("instanceId", instanceID).Warn("cannot get instance state after reconnect")
+			continue
+		}
+		if info.LatestInstance == nil || info.LatestInstance.ID != instanceID {
+			// a newer instance was started, this one has stopped and we can't get its final state
+			bp.log.WithField("instanceId", instanceID).Debug("instance was replaced while disconnected")
+			continue
+		}
+		bp.dispatch(info.LatestInstance, true)
+	}
+	if !unknown {
+		return
+	}
+
+	// we haven't heard of these instances yet, so we look through the latest instances
+	infos, err := bp.GetApplications(ctx, &GetApplicationsOptions{})
+	if err != nil {
+		bp.log.WithError(err).Warn("cannot get instance states after reconnect")
+		return
+	}
+	for _, info := range infos {
+		if info.LatestInstance == nil {
+			continue
+		}
+		if applicationID, ok := instances[info.LatestInstance.ID]; ok && applicationID == "" {
+			bp.dispatch(info.LatestInstance, true)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// recordingConn answers calls with fixed results and records the calls it got
+type recordingConn struct {
+	results map[string]interface{}
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (c *recordingConn) Call(ctx context.Context, method string, params, result interface{}, opt ...jsonrpc2.CallOption) error {
+	c.mu.Lock()
+	c.calls = append(c.calls, method)
+	c.mu.Unlock()
+
+	res, ok := c.results[method]
+	if !ok {
+		return &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: method}
+	}
+	if err, ok := res.(error); ok {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	fc, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(fc, result)
+}
+
+func (c *recordingConn) Notify(ctx context.Context, method string, params interface{}, opt ...jsonrpc2.CallOption) error {
+	return nil
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func TestResubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn := &recordingConn{results: map[string]interface{}{
+		"getLoggedInUser":                &User{ID: "user"},
+		"watchApplicationImageBuildLogs": nil,
+		"getApplication":                 &ApplicationInfo{LatestInstance: instance(PhaseStopped)},
+	}}
+	bp := &APIoverJSONRPC{C: conn, log: logrus.NewEntry(logrus.New())}
+
+	err := bp.WatchApplicationImageBuildLogs(ctx, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates, err := bp.InstanceUpdates(ctx, "inst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bp.dispatch(instance(PhasePreparing), false)
+	if act := (<-updates).Phase(); act != PhasePreparing {
+		t.Fatalf("unexpected phase: got %s, expected %s", act, PhasePreparing)
+	}
+	conn.calls = nil
+
+	// the update to stopped was missed while disconnected
+	done := make(chan struct{})
+	go func() {
+		bp.resubscribe(ctx)
+		close(done)
+	}()
+	if act := (<-updates).Phase(); act != PhaseStopped {
+		t.Errorf("unexpected phase: got %s, expected %s", act, PhaseStopped)
+	}
+	<-done
+
+	if exp := []string{"getLoggedInUser", "watchApplicationImageBuildLogs", "getApplication"}; !reflect.DeepEqual(conn.calls, exp) {
+		t.Errorf("unexpected calls: got %v, expected %v", conn.calls, exp)
+	}
+}
+
+func TestResubscribeNotAuthenticated(t *testing.T) {
+	conn := &recordingConn{results: map[string]interface{}{
+		"getLoggedInUser": &jsonrpc2.Error{Code: int64(ErrorCodeNotAuthenticated)},
+	}}
+	bp := &APIoverJSONRPC{C: conn, log: logrus.NewEntry(logrus.New())}
+	bp.addListener(FunctionWatchApplicationImageBuildLogs, []interface{}{"app"})
+
+	bp.resubscribe(context.Background())
+
+	if exp := []string{"getLoggedInUser"}; !reflect.DeepEqual(conn.calls, exp) {
+		t.Errorf("unexpected calls: got %v, expected %v", conn.calls, exp)
+	}
+}
+
+func TestResubscribeEndedListener(t *testing.T) {
+	conn := &recordingConn{results: map[string]interface{}{
+		"getLoggedInUser":                &User{ID: "user"},
+		"watchApplicationImageBuildLogs": nil,
+	}}
+	bp := &APIoverJSONRPC{C: conn, log: logrus.NewEntry(logrus.New())}
+
+	err := bp.WatchApplicationImageBuildLogs(context.Background(), "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the image is built once the instance is pending
+	bp.dispatch(instance(PhasePending), false)
+	conn.calls = nil
+
+	bp.resubscribe(context.Background())
+
+	if exp := []string{"getLoggedInUser"}; !reflect.DeepEqual(conn.calls, exp) {
+		t.Errorf("unexpected calls: got %v, expected %v", conn.calls, exp)
+	}
+}
+
+func TestResubscribeSnapshotNotDropped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn := &recordingConn{results: map[string]interface{}{
+		"getLoggedInUser": &User{ID: "user"},
+		"getApplication":  &ApplicationInfo{LatestInstance: instance(PhaseStopped)},
+	}}
+	bp := &APIoverJSONRPC{C: conn, log: logrus.NewEntry(logrus.New())}
+	updates, err := bp.InstanceUpdates(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the reader falls behind, so updates are dropped
+	for i := 0; i < 2*instanceUpdatesBuffer; i++ {
+		bp.dispatch(instance(PhaseRunning), false)
+	}
+	bp.resubscribe(ctx)
+
+	var received int
+	for {
+		select {
+		case inst := <-updates:
+			received++
+			if inst.Phase() == PhaseStopped {
+				if received > instanceUpdatesBuffer+1 {
+					t.Errorf("reader got %d updates, expected at most %d", received, instanceUpdatesBuffer+1)
+				}
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("snapshot was dropped after %d updates", received)
+		}
+	}
+}
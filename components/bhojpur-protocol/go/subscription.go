@@ -0,0 +1,81 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"sync"
+)
+
+// subscription delivers instance updates to a reader of InstanceUpdates, in order
+type subscription struct {
+	chn    chan *ApplicationInstance
+	notify chan struct{}
+
+	mu    sync.Mutex
+	queue []*ApplicationInstance
+}
+
+// newSubscription creates a subscription which delivers updates until ctx is done
+func newSubscription(ctx context.Context) *subscription {
+	s := &subscription{
+		chn:    make(chan *ApplicationInstance),
+		notify: make(chan struct{}, 1),
+	}
+	go s.forward(ctx)
+	return s
+}
+
+// push queues an update for the reader. Updates are dropped once the reader falls
+// instanceUpdatesBuffer updates behind. Snapshots are never dropped, they replace the
+// queued updates of their instance instead, because they're the latest state.
+func (s *subscription) push(instance *ApplicationInstance, snapshot bool) {
+	s.mu.Lock()
+	if snapshot {
+		queue := s.queue[:0]
+		for _, q := range s.queue {
+			if q.ID != instance.ID {
+				queue = append(queue, q)
+			}
+		}
+		s.queue = append(queue, instance)
+	} else if len(s.queue) < instanceUpdatesBuffer {
+		s.queue = append(s.queue, instance)
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscription) forward(ctx context.Context) {
+	defer close(s.chn)
+	for {
+		s.mu.Lock()
+		var next *ApplicationInstance
+		if len(s.queue) > 0 {
+			next = s.queue[0]
+			s.queue = s.queue[1:]
+		}
+		s.mu.Unlock()
+
+		if next == nil {
+			select {
+			case <-s.notify:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case s.chn <- next:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Transport opens the connections to the server. The JSON-RPC messages are exchanged
+// as websocket messages, whatever the connection underneath. The handshake must
+// complete before the deadline of the context passed to Dial.
+type Transport interface {
+	// Dial opens a new connection. It is called again on every reconnect.
+	Dial(ctx context.Context, url string, header http.Header) (*websocket.Conn, *http.Response, error)
+}
+
+// WebsocketTransport connects to the server over TCP, with TLS for wss URLs
+type WebsocketTransport struct {
+	Dialer websocket.Dialer
+}
+
+// NewWebsocketTransport creates a websocket transport which negotiates permessage-deflate
+// compression. The proxy defaults to the one configured by the HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY environment variables.
+func NewWebsocketTransport(tlsConfig *tls.Config, proxy func(*http.Request) (*url.URL, error)) *WebsocketTransport {
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+	return &WebsocketTransport{
+		Dialer: websocket.Dialer{
+			Proxy:             proxy,
+			EnableCompression: true,
+			TLSClientConfig:   tlsConfig,
+		},
+	}
+}
+
+// Dial implements Transport
+func (t *WebsocketTransport) Dial(ctx context.Context, url string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	return t.Dialer.DialContext(ctx, url, header)
+}
+
+// UnixSocketTransport connects to a server listening on a Unix domain socket, e.g.
+// for sidecars running next to the server. The host of the URL is ignored.
+type UnixSocketTransport struct {
+	Path string
+}
+
+// NewUnixSocketTransport creates a transport to the socket at path
+func NewUnixSocketTransport(path string) *UnixSocketTransport {
+	return &UnixSocketTransport{Path: path}
+}
+
+// Dial implements Transport
+func (t *UnixSocketTransport) Dial(ctx context.Context, url string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	dialer := websocket.Dialer{
+		EnableCompression: true,
+		NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", t.Path)
+		},
+	}
+	return dialer.DialContext(ctx, url, header)
+}
+
+// PipeTransport connects to a server in the same process through in-memory pipes.
+// The server accepts the connections from the Listener, e.g. with http.Serve.
+type PipeTransport struct {
+	listener *pipeListener
+}
+
+// NewPipeTransport creates a new in-memory transport
+func NewPipeTransport() *PipeTransport {
+	return &PipeTransport{
+		listener: &pipeListener{
+			conns:  make(chan net.Conn),
+			closed: make(chan struct{}),
+		},
+	}
+}
+
+// Listener returns the listener the server accepts connections from
+func (t *PipeTransport) Listener() net.Listener {
+	return t.listener
+}
+
+// Dial implements Transport
+func (t *PipeTransport) Dial(ctx context.Context, url string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	dialer := websocket.Dialer{
+		EnableCompression: true,
+		NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			client, server := net.Pipe()
+			select {
+			case t.listener.conns <- server:
+				return client, nil
+			case <-t.listener.closed:
+				return nil, errPipeClosed
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+	}
+	return dialer.DialContext(ctx, url, header)
+}
+
+var errPipeClosed = errors.New("pipe transport: closed")
+
+type pipeListener struct {
+	conns  chan net.Conn
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.closed:
+		return nil, errPipeClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "pipe" }
+
+// LoadTLSConfig creates a TLS configuration which trusts the CA bundle in addition to the
+// system's CAs, and authenticates with the client certificate. All files are optional.
+func LoadTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	res := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		fc, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(fc) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", caFile)
+		}
+		res.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate: %w", err)
+		}
+		res.Certificates = []tls.Certificate{cert}
+	}
+
+	return res, nil
+}
+
+// originForEndpoint returns the origin the server expects for an endpoint
+func originForEndpoint(epURL *url.URL) string {
+	protocol := "http"
+	if epURL.Scheme == "wss" || epURL.Scheme == "https" {
+		protocol = "https"
+	}
+	return fmt.Sprintf("%s://%s/", protocol, epURL.Hostname())
+}
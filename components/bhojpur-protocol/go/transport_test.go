@@ -0,0 +1,48 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestOriginForEndpoint(t *testing.T) {
+	tests := []struct {
+		Endpoint    string
+		Expectation string
+	}{
+		{"wss://bhojpur.example.com/api/bhojpur", "https://bhojpur.example.com/"},
+		{"ws://localhost:3000/api/bhojpur", "http://localhost/"},
+		{"https://bhojpur.example.com/api/bhojpur", "https://bhojpur.example.com/"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Endpoint, func(t *testing.T) {
+			u, err := url.Parse(test.Endpoint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if act := originForEndpoint(u); act != test.Expectation {
+				t.Errorf("unexpected origin: got %s, expected %s", act, test.Expectation)
+			}
+		})
+	}
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	cfg, err := LoadTLSConfig("", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RootCAs != nil || len(cfg.Certificates) != 0 {
+		t.Errorf("unexpected TLS config without files: %+v", cfg)
+	}
+
+	_, err = LoadTLSConfig("does-not-exist.pem", "", "")
+	if err == nil {
+		t.Error("missing CA bundle was accepted")
+	}
+}
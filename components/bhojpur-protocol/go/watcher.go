@@ -0,0 +1,324 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ApplicationPhase is the phase of an application instance
+type ApplicationPhase string
+
+const (
+	// PhaseUnknown is the phase of instances without a status
+	PhaseUnknown ApplicationPhase = ""
+	// PhasePreparing means the application image is being built
+	PhasePreparing ApplicationPhase = "preparing"
+	// PhasePending means the instance waits for resources in the cluster
+	PhasePending ApplicationPhase = "pending"
+	// PhaseCreating means the instance is being created, e.g. its images are pulled
+	PhaseCreating ApplicationPhase = "creating"
+	// PhaseInitializing means the content of the application is being restored
+	PhaseInitializing ApplicationPhase = "initializing"
+	// PhaseRunning means the application is ready to use
+	PhaseRunning ApplicationPhase = "running"
+	// PhaseInterrupted means the application is temporarily unavailable, it may go back to running
+	PhaseInterrupted ApplicationPhase = "interrupted"
+	// PhaseStopping means the instance is shutting down and its content is being backed up
+	PhaseStopping ApplicationPhase = "stopping"
+	// PhaseStopped means the instance is gone. This is the final phase.
+	PhaseStopped ApplicationPhase = "stopped"
+)
+
+// phaseOrder is the position of a phase in the lifecycle. Running and interrupted
+// share a position because an instance can switch between them.
+var phaseOrder = map[ApplicationPhase]int{
+	PhaseUnknown:      0,
+	PhasePreparing:    1,
+	PhasePending:      2,
+	PhaseCreating:     3,
+	PhaseInitializing: 4,
+	PhaseRunning:      5,
+	PhaseInterrupted:  5,
+	PhaseStopping:     6,
+	PhaseStopped:      7,
+}
+
+// ErrPhaseNotReached is returned by WaitForPhase if the instance has moved past the phase
+var ErrPhaseNotReached = errors.New("application instance will not reach the phase")
+
+// Phase returns the phase of an application instance
+func (i *ApplicationInstance) Phase() ApplicationPhase {
+	if i == nil || i.Status == nil {
+		return PhaseUnknown
+	}
+	return ApplicationPhase(i.Status.Phase)
+}
+
+// PhaseTransition is a change of the phase of an application instance
+type PhaseTransition struct {
+	InstanceID string
+	From       ApplicationPhase
+	To         ApplicationPhase
+	Instance   *ApplicationInstance
+}
+
+// ApplicationWatcher keeps the latest state of the application instances the server
+// sends updates for. Unlike InstanceUpdates it never drops updates for slow readers.
+type ApplicationWatcher struct {
+	api *APIoverJSONRPC
+
+	mu        sync.Mutex
+	instances map[string]*ApplicationInstance
+	listeners map[*transitionListener]struct{}
+	// changed is closed and replaced on every update
+	changed chan struct{}
+}
+
+// Watcher returns the application watcher of this connection. It only knows about
+// updates received after it was first requested.
+func (bp *APIoverJSONRPC) Watcher() *ApplicationWatcher {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	if bp.watcher == nil {
+		bp.watcher = &ApplicationWatcher{
+			api:       bp,
+			instances: make(map[string]*ApplicationInstance),
+			listeners: make(map[*transitionListener]struct{}),
+			changed:   make(chan struct{}),
+		}
+	}
+	return bp.watcher
+}
+
+// Latest returns the latest known state of an application instance, or nil if there is none
+func (w *ApplicationWatcher) Latest(instanceID string) *ApplicationInstance {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.instances[instanceID]
+}
+
+// Forget drops the state of an application instance, e.g. once it is stopped
+func (w *ApplicationWatcher) Forget(instanceID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.instances, instanceID)
+}
+
+// Transitions streams the phase transitions of an application instance, or of all
+// instances if instanceID is empty, until the context is canceled. Transitions are
+// queued for slow readers rather than dropped.
+func (w *ApplicationWatcher) Transitions(ctx context.Context, instanceID string) <-chan PhaseTransition {
+	l := &transitionListener{
+		instanceID: instanceID,
+		notify:     make(chan struct{}, 1),
+		out:        make(chan PhaseTransition),
+	}
+
+	w.mu.Lock()
+	w.listeners[l] = struct{}{}
+	w.mu.Unlock()
+
+	go func() {
+		defer func() {
+			w.mu.Lock()
+			delete(w.listeners, l)
+			w.mu.Unlock()
+			close(l.out)
+		}()
+
+		for {
+			t, ok := l.pop()
+			if !ok {
+				select {
+				case <-l.notify:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case l.out <- t:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return l.out
+}
+
+// WaitForPhase waits until an application instance is in the given phase, and returns
+// its state then. It fails with ErrPhaseNotReached if the instance has moved past the phase,
+// e.g. it stopped while waiting for it to run.
+//
+// Updates sent while the connection was down are lost, hence the state is polled from
+// the server after every reconnect, and when nothing is known about the instance yet.
+func (w *ApplicationWatcher) WaitForPhase(ctx context.Context, instanceID string, phase ApplicationPhase) (*ApplicationInstance, error) {
+	if _, ok := phaseOrder[phase]; !ok || phase == PhaseUnknown {
+		return nil, fmt.Errorf("unknown phase %q", phase)
+	}
+
+	if w.Latest(instanceID) == nil {
+		err := w.resync(ctx, instanceID)
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	for {
+		reconnected := w.api.reconnectedChan()
+
+		w.mu.Lock()
+		inst := w.instances[instanceID]
+		changed := w.changed
+		w.mu.Unlock()
+
+		if inst != nil {
+			current := inst.Phase()
+			if current == phase {
+				return inst, nil
+			}
+			if phaseOrder[current] > phaseOrder[phase] {
+				return inst, fmt.Errorf("%w: instance %s is %s, waited for %s", ErrPhaseNotReached, instanceID, current, phase)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		case <-reconnected:
+			err := w.resync(ctx, instanceID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				return nil, err
+			}
+		}
+	}
+}
+
+// resync polls the state of an application instance from the server, retrying while
+// the connection is re-established
+func (w *ApplicationWatcher) resync(ctx context.Context, instanceID string) error {
+	delay := 500 * time.Millisecond
+	for {
+		err := w.poll(ctx, instanceID)
+		if err == nil || !IsRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > 10*time.Second {
+			delay = 10 * time.Second
+		}
+	}
+}
+
+func (w *ApplicationWatcher) poll(ctx context.Context, instanceID string) error {
+	var applicationID string
+	if inst := w.Latest(instanceID); inst != nil {
+		applicationID = inst.ApplicationID
+	}
+
+	if applicationID == "" {
+		// without an application we can only look through the latest instances
+		infos, err := w.api.GetApplications(ctx, &GetApplicationsOptions{})
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if info.LatestInstance != nil && info.LatestInstance.ID == instanceID {
+				w.update(info.LatestInstance)
+				return nil
+			}
+		}
+		return nil
+	}
+
+	info, err := w.api.GetApplication(ctx, applicationID)
+	if err != nil {
+		return err
+	}
+	if info.LatestInstance == nil || info.LatestInstance.ID != instanceID {
+		return fmt.Errorf("instance %s is no longer the latest instance of application %s", instanceID, applicationID)
+	}
+	w.update(info.LatestInstance)
+	return nil
+}
+
+// update records the state of an application instance. States which would move the
+// instance back in its lifecycle are stale, e.g. a poll racing with an update, and ignored.
+func (w *ApplicationWatcher) update(inst *ApplicationInstance) {
+	if inst == nil || inst.ID == "" {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prev, known := w.instances[inst.ID]
+	from, to := prev.Phase(), inst.Phase()
+	if known && phaseOrder[to] < phaseOrder[from] {
+		return
+	}
+
+	w.instances[inst.ID] = inst
+	close(w.changed)
+	w.changed = make(chan struct{})
+
+	if known && from == to {
+		return
+	}
+	t := PhaseTransition{InstanceID: inst.ID, From: from, To: to, Instance: inst}
+	for l := range w.listeners {
+		if l.instanceID == "" || l.instanceID == inst.ID {
+			l.push(t)
+		}
+	}
+}
+
+type transitionListener struct {
+	instanceID string
+
+	mu     sync.Mutex
+	queue  []PhaseTransition
+	notify chan struct{}
+	out    chan PhaseTransition
+}
+
+func (l *transitionListener) push(t PhaseTransition) {
+	l.mu.Lock()
+	l.queue = append(l.queue, t)
+	l.mu.Unlock()
+
+	select {
+	case l.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (l *transitionListener) pop() (t PhaseTransition, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.queue) == 0 {
+		return
+	}
+	t = l.queue[0]
+	l.queue = l.queue[1:]
+	return t, true
+}
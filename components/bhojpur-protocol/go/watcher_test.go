@@ -0,0 +1,136 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// staticConn answers getApplication with a fixed result
+type staticConn struct {
+	info *ApplicationInfo
+}
+
+func (c *staticConn) Call(ctx context.Context, method string, params, result interface{}, opt ...jsonrpc2.CallOption) error {
+	if method != string(FunctionGetApplication) {
+		return &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: method}
+	}
+	fc, err := json.Marshal(c.info)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(fc, result)
+}
+
+func (c *staticConn) Notify(ctx context.Context, method string, params interface{}, opt ...jsonrpc2.CallOption) error {
+	return nil
+}
+
+func (c *staticConn) Close() error { return nil }
+
+func instance(phase ApplicationPhase) *ApplicationInstance {
+	return &ApplicationInstance{ID: "inst", ApplicationID: "app", Status: &ApplicationInstanceStatus{Phase: string(phase)}}
+}
+
+func TestWaitForPhase(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bp := &APIoverJSONRPC{C: &staticConn{}}
+	w := bp.Watcher()
+	w.update(instance(PhasePending))
+
+	done := make(chan error)
+	go func() {
+		_, err := w.WaitForPhase(ctx, "inst", PhaseRunning)
+		done <- err
+	}()
+
+	w.update(instance(PhaseCreating))
+	w.update(instance(PhaseRunning))
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w.update(instance(PhaseStopped))
+	_, err := w.WaitForPhase(ctx, "inst", PhaseRunning)
+	if !errors.Is(err, ErrPhaseNotReached) {
+		t.Errorf("unexpected error: got %v, expected %v", err, ErrPhaseNotReached)
+	}
+
+	// stale states don't move the instance back
+	w.update(instance(PhaseRunning))
+	if act := w.Latest("inst").Phase(); act != PhaseStopped {
+		t.Errorf("unexpected phase: got %s, expected %s", act, PhaseStopped)
+	}
+}
+
+func TestWaitForPhasePollsAfterReconnect(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn := &staticConn{}
+	bp := &APIoverJSONRPC{C: conn}
+	w := bp.Watcher()
+	w.update(instance(PhaseRunning))
+
+	done := make(chan error)
+	go func() {
+		_, err := w.WaitForPhase(ctx, "inst", PhaseStopped)
+		done <- err
+	}()
+
+	// the update to stopped was missed while disconnected
+	conn.info = &ApplicationInfo{LatestInstance: instance(PhaseStopped)}
+	for {
+		bp.onReconnect()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestTransitions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bp := &APIoverJSONRPC{C: &staticConn{}}
+	w := bp.Watcher()
+	transitions := w.Transitions(ctx, "inst")
+
+	// nobody reads while the updates come in
+	for _, p := range []ApplicationPhase{PhasePending, PhaseCreating, PhaseCreating, PhaseRunning, PhaseStopping, PhaseStopped} {
+		w.update(instance(p))
+	}
+
+	expectation := []PhaseTransition{
+		{From: PhaseUnknown, To: PhasePending},
+		{From: PhasePending, To: PhaseCreating},
+		{From: PhaseCreating, To: PhaseRunning},
+		{From: PhaseRunning, To: PhaseStopping},
+		{From: PhaseStopping, To: PhaseStopped},
+	}
+	for _, exp := range expectation {
+		select {
+		case act := <-transitions:
+			if act.From != exp.From || act.To != exp.To {
+				t.Errorf("unexpected transition: got %s -> %s, expected %s -> %s", act.From, act.To, exp.From, exp.To)
+			}
+		case <-ctx.Done():
+			t.Fatalf("missing transition %s -> %s", exp.From, exp.To)
+		}
+	}
+}
@@ -7,6 +7,10 @@ package protocol
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -20,47 +24,125 @@ type WebsocketConnection struct {
 	Ctx    context.Context
 	cancel func()
 
+	opts WebsocketOptions
+
 	once     sync.Once
 	closeErr error
 	waitDone chan struct{}
 }
 
-var (
-	// Time allowed to write a message to the peer.
-	writeWait = 10 * time.Second
+// defaultMessageLimit is the default size limit of messages in either direction
+const defaultMessageLimit = 32 << 20
 
-	// Time allowed to read the next pong message from the peer.
-	pongWait = 15 * time.Second
+// WebsocketOptions configures a websocket connection. Zero fields take the value of
+// DefaultWebsocketOptions.
+type WebsocketOptions struct {
+	// WriteWait is the time allowed to write a message to the peer
+	WriteWait time.Duration
+	// PongWait is the time allowed to read the next pong message from the peer
+	PongWait time.Duration
+	// PingPeriod is the time between pings, it must be less than PongWait. Defaults to 90% of PongWait.
+	PingPeriod time.Duration
+	// ReadLimit is the maximum size of a message from the peer in bytes, negative for no limit.
+	// The connection is closed when the peer sends a larger message.
+	ReadLimit int64
+	// WriteLimit is the maximum size of a message to the peer in bytes, negative for no limit.
+	// Larger messages are not sent and fail with a MessageTooLargeError.
+	WriteLimit int64
+}
 
-	// Send pings to peer with this period. Must be less than pongWait.
-	pingPeriod = (pongWait * 9) / 10
-)
+// DefaultWebsocketOptions returns the options used unless configured otherwise
+func DefaultWebsocketOptions() WebsocketOptions {
+	return WebsocketOptions{
+		WriteWait:  10 * time.Second,
+		PongWait:   15 * time.Second,
+		PingPeriod: (15 * time.Second * 9) / 10,
+		ReadLimit:  defaultMessageLimit,
+		WriteLimit: defaultMessageLimit,
+	}
+}
+
+// withDefaults returns the options with the zero fields set to their defaults
+func (o WebsocketOptions) withDefaults() WebsocketOptions {
+	def := DefaultWebsocketOptions()
+	if o.WriteWait <= 0 {
+		o.WriteWait = def.WriteWait
+	}
+	if o.PongWait <= 0 {
+		o.PongWait = def.PongWait
+	}
+	if o.PingPeriod <= 0 || o.PingPeriod >= o.PongWait {
+		o.PingPeriod = (o.PongWait * 9) / 10
+	}
+	if o.ReadLimit == 0 {
+		o.ReadLimit = def.ReadLimit
+	}
+	if o.WriteLimit == 0 {
+		o.WriteLimit = def.WriteLimit
+	}
+	return o
+}
+
+// MessageTooLargeError is returned when a message exceeds the size limit of the connection
+type MessageTooLargeError struct {
+	// Read is true for messages from the peer, false for messages to the peer
+	Read bool
+	// Size is the size of the message in bytes, zero if unknown
+	Size  int64
+	Limit int64
+}
+
+func (e *MessageTooLargeError) Error() string {
+	if e.Read {
+		return fmt.Sprintf("websocket: message from peer exceeds read limit of %d bytes", e.Limit)
+	}
+	return fmt.Sprintf("websocket: message of %d bytes exceeds write limit of %d bytes", e.Size, e.Limit)
+}
+
+// Unwrap returns websocket.ErrReadLimit for messages from the peer
+func (e *MessageTooLargeError) Unwrap() error {
+	if e.Read {
+		return websocket.ErrReadLimit
+	}
+	return nil
+}
 
 //NewWebsocketConnection converts a websocket.Conn into a net.Conn
 func NewWebsocketConnection(ctx context.Context, websocketConn *websocket.Conn, onStale func(staleErr error)) (*WebsocketConnection, error) {
+	return NewWebsocketConnectionWithOptions(ctx, websocketConn, WebsocketOptions{}, onStale)
+}
+
+// NewWebsocketConnectionWithOptions converts a websocket.Conn into a net.Conn, with the
+// ping/pong intervals and size limits of opts
+func NewWebsocketConnectionWithOptions(ctx context.Context, websocketConn *websocket.Conn, opts WebsocketOptions, onStale func(staleErr error)) (*WebsocketConnection, error) {
+	opts = opts.withDefaults()
 	ctx, cancel := context.WithCancel(ctx)
 	c := &WebsocketConnection{
 		Conn:     websocketConn,
 		waitDone: make(chan struct{}),
 		Ctx:      ctx,
 		cancel:   cancel,
+		opts:     opts,
+	}
+	if opts.ReadLimit > 0 {
+		c.SetReadLimit(opts.ReadLimit)
 	}
-	err := c.SetReadDeadline(time.Now().Add(pongWait))
+	err := c.SetReadDeadline(time.Now().Add(opts.PongWait))
 	if err != nil {
 		return nil, err
 	}
-	c.SetPongHandler(func(string) error { c.SetReadDeadline(time.Now().Add(pongWait)); return nil })
+	c.SetPongHandler(func(string) error { c.SetReadDeadline(time.Now().Add(opts.PongWait)); return nil })
 
 	go func() {
 		defer c.Close()
-		ticker := time.NewTicker(pingPeriod)
+		ticker := time.NewTicker(opts.PingPeriod)
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				staleErr := c.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(writeWait))
+				staleErr := c.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(opts.WriteWait))
 				if staleErr != nil {
 					onStale(staleErr)
 					return
@@ -96,7 +178,7 @@ func (c *WebsocketConnection) Read(dst []byte) (int, error) {
 	if len(c.buff) > 0 {
 		src = c.buff
 		c.buff = nil
-	} else if _, msg, err := c.Conn.ReadMessage(); err == nil {
+	} else if msg, err := c.readMessage(); err == nil {
 		src = msg
 	} else {
 		return 0, err
@@ -120,7 +202,11 @@ func (c *WebsocketConnection) Read(dst []byte) (int, error) {
 }
 
 func (c *WebsocketConnection) Write(b []byte) (int, error) {
-	err := c.Conn.WriteMessage(websocket.BinaryMessage, b)
+	err := c.checkWriteLimit(len(b))
+	if err != nil {
+		return 0, err
+	}
+	err = c.Conn.WriteMessage(websocket.BinaryMessage, b)
 	if err != nil {
 		return 0, err
 	}
@@ -134,3 +220,64 @@ func (c *WebsocketConnection) SetDeadline(t time.Time) error {
 	}
 	return c.Conn.SetWriteDeadline(t)
 }
+
+// ReadJSON reads the next JSON-encoded message from the connection and stores it in the value pointed to by v.
+// It fails with a MessageTooLargeError if the message exceeds the read limit.
+func (c *WebsocketConnection) ReadJSON(v interface{}) error {
+	msg, err := c.readMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(msg, v)
+}
+
+// WriteJSON writes the JSON encoding of v as a message. It fails with a MessageTooLargeError
+// if the encoding exceeds the write limit.
+func (c *WebsocketConnection) WriteJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	err = c.checkWriteLimit(len(b))
+	if err != nil {
+		return err
+	}
+	return c.Conn.WriteMessage(websocket.TextMessage, b)
+}
+
+func (c *WebsocketConnection) checkWriteLimit(size int) error {
+	if c.opts.WriteLimit > 0 && int64(size) > c.opts.WriteLimit {
+		return &MessageTooLargeError{Size: int64(size), Limit: c.opts.WriteLimit}
+	}
+	return nil
+}
+
+// readMessage reads the next message. The read limit of the websocket.Conn applies to the
+// frames as sent, so we limit the size of decompressed messages ourselves.
+func (c *WebsocketConnection) readMessage() ([]byte, error) {
+	_, r, err := c.Conn.NextReader()
+	if err != nil {
+		return nil, c.readErr(err)
+	}
+	if c.opts.ReadLimit <= 0 {
+		msg, err := io.ReadAll(r)
+		return msg, c.readErr(err)
+	}
+
+	msg, err := io.ReadAll(io.LimitReader(r, c.opts.ReadLimit+1))
+	if err != nil {
+		return nil, c.readErr(err)
+	}
+	if int64(len(msg)) > c.opts.ReadLimit {
+		_ = c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseMessageTooBig, ""), time.Now().Add(c.opts.WriteWait))
+		return nil, &MessageTooLargeError{Read: true, Limit: c.opts.ReadLimit}
+	}
+	return msg, nil
+}
+
+func (c *WebsocketConnection) readErr(err error) error {
+	if errors.Is(err, websocket.ErrReadLimit) {
+		return &MessageTooLargeError{Read: true, Limit: c.opts.ReadLimit}
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWebsocketOptions(t *testing.T) {
+	opts := WebsocketOptions{PongWait: 10 * time.Second, PingPeriod: time.Minute, WriteLimit: -1}.withDefaults()
+	if opts.PingPeriod != 9*time.Second {
+		t.Errorf("ping period not below pong wait: %v", opts.PingPeriod)
+	}
+	if opts.ReadLimit != defaultMessageLimit || opts.WriteLimit != -1 {
+		t.Errorf("unexpected limits: %d/%d", opts.ReadLimit, opts.WriteLimit)
+	}
+}
+
+func TestWebsocketConnectionLimits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	upgrader := websocket.Upgrader{EnableCompression: true}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// echo everything back
+		for {
+			tpe, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			err = conn.WriteMessage(tpe, msg)
+			if err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	conn, resp, err := NewWebsocketTransport(nil, nil).Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ext := resp.Header.Get("Sec-Websocket-Extensions"); !strings.Contains(ext, "permessage-deflate") {
+		t.Errorf("compression was not negotiated: %q", ext)
+	}
+
+	ws, err := NewWebsocketConnectionWithOptions(ctx, conn, WebsocketOptions{ReadLimit: 64, WriteLimit: 128}, func(error) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	var tooLarge *MessageTooLargeError
+	err = ws.WriteJSON(strings.Repeat("x", 200))
+	if !errors.As(err, &tooLarge) || tooLarge.Read || tooLarge.Limit != 128 {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	// small messages pass in both directions
+	err = ws.WriteJSON("hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg string
+	err = ws.ReadJSON(&msg)
+	if err != nil || msg != "hello" {
+		t.Fatalf("unexpected echo: %q, %v", msg, err)
+	}
+
+	// the echo of this message exceeds the read limit
+	err = ws.WriteJSON(strings.Repeat("x", 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ws.ReadJSON(&msg)
+	if !errors.As(err, &tooLarge) || !tooLarge.Read || !errors.Is(err, websocket.ErrReadLimit) {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	clusterlink "github.com/bhojpur/platform/installer/pkg/components/cluster-link"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var clusterLinkOpts struct {
+	Name       string
+	Bridge     string
+	CA         string
+	SecretName string
+	Namespace  string
+	OutputDir  string
+	Validity   time.Duration
+}
+
+// clusterLinkCmd represents the cluster-link command
+var clusterLinkCmd = &cobra.Command{
+	Use:   "cluster-link",
+	Short: "Generates the credentials which link a workspace cluster to a meta cluster",
+	Long: `Generates the credentials which link a workspace cluster to a meta cluster
+The first run creates a CA, the certificate of the bp-manager-bridge endpoint of the
+meta cluster and the certificate the workspace cluster registers with. Further workspace
+clusters are linked with --ca, which issues only their certificate from the same CA.
+Each certificate is written as a secret to be applied to the matching cluster and
+referenced in clusterLink.certificates. Keep the CA secret safe.`,
+	Example: `  # Link the workspace cluster "eu01" to a meta cluster
+  bhojpur-installer cluster-link --name eu01 --bridge bridge.example.com:8443
+  kubectl --context meta apply -f cluster-link-meta.yaml
+  kubectl --context eu01 apply -f cluster-link-workspace-eu01.yaml
+  # Link another workspace cluster "us01" to the same meta cluster
+  bhojpur-installer cluster-link --name us01 --ca cluster-link-ca.yaml
+  kubectl --context us01 apply -f cluster-link-workspace-us01.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if clusterLinkOpts.Name == "" {
+			return fmt.Errorf("missing --name")
+		}
+		if clusterLinkOpts.CA == "" && clusterLinkOpts.Bridge == "" {
+			return fmt.Errorf("missing --bridge")
+		}
+
+		type output struct {
+			Side string
+			Name string
+			Data clusterlink.Credentials
+		}
+		var outputs []output
+
+		var ca clusterlink.Credentials
+		if clusterLinkOpts.CA != "" {
+			fc, err := ioutil.ReadFile(clusterLinkOpts.CA)
+			if err != nil {
+				return err
+			}
+			var secret corev1.Secret
+			err = yaml.Unmarshal(fc, &secret)
+			if err != nil {
+				return fmt.Errorf("cannot read CA secret %s: %w", clusterLinkOpts.CA, err)
+			}
+			ca = secret.Data
+		} else {
+			var err error
+			ca, err = clusterlink.GenerateCA(clusterLinkOpts.Validity)
+			if err != nil {
+				return err
+			}
+			meta, err := clusterlink.GenerateMetaCredentials(ca, clusterLinkOpts.Bridge, clusterLinkOpts.Validity)
+			if err != nil {
+				return err
+			}
+			outputs = append(outputs,
+				output{Side: "ca", Name: clusterLinkOpts.SecretName + "-ca", Data: ca},
+				output{Side: "meta", Name: clusterLinkOpts.SecretName, Data: meta},
+			)
+		}
+
+		workspace, err := clusterlink.GenerateWorkspaceCredentials(ca, clusterLinkOpts.Name, clusterLinkOpts.Validity)
+		if err != nil {
+			return err
+		}
+		outputs = append(outputs, output{Side: "workspace-" + clusterLinkOpts.Name, Name: clusterLinkOpts.SecretName, Data: workspace})
+
+		for _, o := range outputs {
+			secret := &corev1.Secret{
+				TypeMeta: common.TypeMetaSecret,
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      o.Name,
+					Namespace: clusterLinkOpts.Namespace,
+					Labels:    common.DefaultLabels(clusterlink.Component),
+				},
+				Type: corev1.SecretTypeTLS,
+				Data: o.Data,
+			}
+
+			fc, err := yaml.Marshal(secret)
+			if err != nil {
+				return err
+			}
+
+			fn := filepath.Join(clusterLinkOpts.OutputDir, fmt.Sprintf("%s-%s.yaml", clusterlink.Component, o.Side))
+			// The secrets contain private keys, so only the user may read them
+			err = ioutil.WriteFile(fn, fc, 0600)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s secret written to %s\n", o.Side, fn)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(clusterLinkCmd)
+
+	clusterLinkCmd.Flags().StringVar(&clusterLinkOpts.Name, "name", "", "name of the workspace cluster")
+	clusterLinkCmd.Flags().StringVar(&clusterLinkOpts.Bridge, "bridge", "", "bp-manager-bridge endpoint of the meta cluster, as host:port")
+	clusterLinkCmd.Flags().StringVar(&clusterLinkOpts.CA, "ca", "", "CA secret written by a previous run, to link another workspace cluster to the same meta cluster")
+	clusterLinkCmd.Flags().StringVar(&clusterLinkOpts.SecretName, "secret-name", "cluster-link-certs", "name of the secrets")
+	clusterLinkCmd.Flags().StringVarP(&clusterLinkOpts.Namespace, "namespace", "n", "default", "namespace the Bhojpur.NET Platform is deployed to")
+	clusterLinkCmd.Flags().StringVarP(&clusterLinkOpts.OutputDir, "output-dir", "o", ".", "directory to write the secrets to")
+	clusterLinkCmd.Flags().DurationVar(&clusterLinkOpts.Validity, "validity", 365*24*time.Hour, "validity of the certificates")
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	"github.com/bhojpur/platform/installer/pkg/components/database/backup"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+var dbRestoreOpts struct {
+	ConfigFN  string
+	Namespace string
+	Backup    string
+}
+
+// dbRestoreCmd represents the db restore command
+var dbRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Renders a job which restores the in-cluster database from a backup",
+	Long: `Renders a job which restores the in-cluster database from a backup
+The backup is read from the object storage bucket configured in database.backup.`,
+	Example: `  # Restore a backup
+  bhojpur-installer db restore --config config.yaml --backup bhojpur-20211116203736.sql.gz | kubectl apply -f -`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if dbRestoreOpts.Backup == "" {
+			return fmt.Errorf("missing --backup")
+		}
+
+		_, _, cfg, err := loadConfig(dbRestoreOpts.ConfigFN)
+		if err != nil {
+			return err
+		}
+
+		versionMF, err := getVersionManifest()
+		if err != nil {
+			return err
+		}
+
+		ctx, err := common.NewRenderContext(*cfg, *versionMF, dbRestoreOpts.Namespace)
+		if err != nil {
+			return err
+		}
+
+		objs, err := backup.RestoreJob(ctx, dbRestoreOpts.Backup, time.Now())
+		if err != nil {
+			return err
+		}
+
+		for _, o := range objs {
+			fc, err := yaml.Marshal(o)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("---\n%s\n", string(fc))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbRestoreCmd)
+
+	dbRestoreCmd.Flags().StringVarP(&dbRestoreOpts.ConfigFN, "config", "c", os.Getenv("BHOJPUR_INSTALLER_CONFIG"), "path to the config file")
+	dbRestoreCmd.Flags().StringVarP(&dbRestoreOpts.Namespace, "namespace", "n", "default", "namespace the Bhojpur.NET Platform is deployed to")
+	dbRestoreCmd.Flags().StringVar(&dbRestoreOpts.Backup, "backup", "", "name of the backup to restore, eg bhojpur-20211116203736.sql.gz")
+}
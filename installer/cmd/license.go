@@ -0,0 +1,18 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var licenseCmd = &cobra.Command{
+	Use:   "license",
+	Short: "Performs tasks on Bhojpur.NET Platform licenses",
+}
+
+func init() {
+	rootCmd.AddCommand(licenseCmd)
+}
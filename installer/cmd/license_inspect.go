@@ -0,0 +1,77 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bhojpur/platform/installer/pkg/license"
+	"github.com/spf13/cobra"
+)
+
+var licenseInspectOpts struct {
+	JSON bool
+}
+
+// licenseInspectCmd represents the license inspect command
+var licenseInspectCmd = &cobra.Command{
+	Use:   "inspect <license-file>",
+	Short: "Shows the entitlements of a license file",
+	Long: `Shows the entitlements of a license file
+The signature of the license is verified offline. The command fails if the
+license is malformed, its signature cannot be verified or it has expired.`,
+	Example: `  bhojpur-installer license inspect license.txt`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fc, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		entitlements, err := license.Verify(fc)
+		if err != nil {
+			return err
+		}
+		expired := entitlements.Expired(time.Now())
+
+		if licenseInspectOpts.JSON {
+			jsonOut, err := json.MarshalIndent(struct {
+				*license.Entitlements
+				Expired bool `json:"expired"`
+			}{entitlements, expired}, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(jsonOut))
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintf(w, "ID:\t%s\n", entitlements.ID)
+			fmt.Fprintf(w, "Domain:\t%s\n", entitlements.Domain)
+			fmt.Fprintf(w, "Level:\t%s\n", entitlements.Level)
+			fmt.Fprintf(w, "Seats:\t%d\n", entitlements.Seats)
+			fmt.Fprintf(w, "Valid until:\t%s\n", entitlements.ValidUntil.Format(time.RFC3339))
+			err = w.Flush()
+			if err != nil {
+				return err
+			}
+		}
+
+		if expired {
+			return fmt.Errorf("license expired on %s", entitlements.ValidUntil.Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+func init() {
+	licenseCmd.AddCommand(licenseInspectCmd)
+
+	licenseInspectCmd.Flags().BoolVar(&licenseInspectOpts.JSON, "json", false, "print the entitlements as JSON")
+}
@@ -7,13 +7,17 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	_ "embed"
 
+	"github.com/bhojpur/platform/installer/pkg/cluster"
 	"github.com/bhojpur/platform/installer/pkg/common"
 	"github.com/bhojpur/platform/installer/pkg/components"
+	"github.com/bhojpur/platform/installer/pkg/components/application"
 	"github.com/bhojpur/platform/installer/pkg/config"
 	configv1 "github.com/bhojpur/platform/installer/pkg/config/v1"
+	"github.com/bhojpur/platform/installer/pkg/helm"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/yaml"
 )
@@ -21,7 +25,10 @@ import (
 var renderOpts struct {
 	ConfigFN               string
 	Namespace              string
+	KubeVersion            string
+	CacheDir               string
 	ValidateConfigDisabled bool
+	PreviewWorkspacePod    string
 }
 
 // renderCmd represents the render command
@@ -33,7 +40,9 @@ A config file is required which can be generated with the init command.`,
 	Example: `  # Default install.
   bhojpur-installer render --config config.yaml | kubectl apply -f -
   # Install Bhojpur.NET Platform into a non-default namespace.
-  bhojpur-installer render --config config.yaml --namespace bhojpur | kubectl apply -f -`,
+  bhojpur-installer render --config config.yaml --namespace bhojpur | kubectl apply -f -
+  # Show the pod a prebuild workspace gets with the application templates.
+  bhojpur-installer render --config config.yaml --preview-workspace-pod prebuild`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		_, cfgVersion, cfg, err := loadConfig(renderOpts.ConfigFN)
 		if err != nil {
@@ -54,6 +63,10 @@ A config file is required which can be generated with the init command.`,
 			if err != nil {
 				return err
 			}
+			if err := helm.CheckValues(cfg.HelmValues); err != nil {
+				res.Fatal = append(res.Fatal, err.Error())
+				res.Valid = false
+			}
 
 			if !res.Valid {
 				res.Marshal(os.Stderr)
@@ -62,11 +75,41 @@ A config file is required which can be generated with the init command.`,
 			}
 		}
 
+		if cfg.PodSecurity.Mode == "" {
+			// PodSecurityPolicies are no longer served from Kubernetes 1.25, so they
+			// are only used if the cluster is known to be older
+			cfg.PodSecurity.Mode = configv1.PodSecurityModeAdmission
+			if renderOpts.KubeVersion != "" {
+				psa, err := cluster.UsePodSecurityAdmission(renderOpts.KubeVersion)
+				if err != nil {
+					return fmt.Errorf("invalid Kubernetes version %s: %w", renderOpts.KubeVersion, err)
+				}
+				if !psa {
+					cfg.PodSecurity.Mode = configv1.PodSecurityModePolicy
+				}
+			}
+		}
+
+		helm.CacheDir = renderOpts.CacheDir
+
 		ctx, err := common.NewRenderContext(*cfg, *versionMF, renderOpts.Namespace)
 		if err != nil {
 			return err
 		}
 
+		if renderOpts.PreviewWorkspacePod != "" {
+			pod, err := application.PreviewPod(ctx, renderOpts.PreviewWorkspacePod)
+			if err != nil {
+				return err
+			}
+			fc, err := yaml.Marshal(pod)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("---\n%s\n", string(fc))
+			return nil
+		}
+
 		var renderable common.RenderFunc
 		var helmCharts common.HelmFunc
 		switch cfg.Kind {
@@ -76,7 +119,7 @@ A config file is required which can be generated with the init command.`,
 		case configv1.InstallationMeta:
 			renderable = components.MetaObjects
 			helmCharts = components.MetaHelmDependencies
-		case configv1.InstallationApplication:
+		case configv1.InstallationWorkspace:
 			renderable = components.ApplicationObjects
 			helmCharts = components.ApplicationHelmDependencies
 		default:
@@ -152,5 +195,8 @@ func init() {
 
 	renderCmd.PersistentFlags().StringVarP(&renderOpts.ConfigFN, "config", "c", os.Getenv("BHOJPUR_INSTALLER_CONFIG"), "path to the config file")
 	renderCmd.PersistentFlags().StringVarP(&renderOpts.Namespace, "namespace", "n", "default", "namespace to deploy to")
+	renderCmd.Flags().StringVar(&renderOpts.KubeVersion, "kube-version", "", "Kubernetes version of the cluster, used to choose the pod security mode if not set in the config - PodSecurityAdmission is used if empty")
+	renderCmd.Flags().StringVar(&renderOpts.CacheDir, "cache-dir", "", "opt-in: directory to cache the rendered Helm charts in, keyed by chart digest and values - off by default, as the cached charts contain their secrets")
+	renderCmd.Flags().StringVar(&renderOpts.PreviewWorkspacePod, "preview-workspace-pod", "", "print the pod a workspace of this type gets instead of the manifests, one of "+strings.Join(configv1.ApplicationTemplateTypes, ", "))
 	renderCmd.Flags().BoolVar(&renderOpts.ValidateConfigDisabled, "no-validation", false, "if set, the config will not be validated before running")
 }
@@ -0,0 +1,143 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bhojpur/platform/installer/pkg/status"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var statusOpts struct {
+	Kube          kubeConfig
+	Namespace     string
+	Output        string
+	LogLines      int64
+	RestartWindow time.Duration
+}
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Reports the health of an installed Bhojpur.NET Platform",
+	Long: `Reports the health of an installed Bhojpur.NET Platform
+The installed components are read from the installation config map. For each
+of them the ready replicas, the images compared with the installed version
+manifest, the recent restarts and the failing pods with their last log lines are shown.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if statusOpts.Output != "table" && statusOpts.Output != "json" {
+			return fmt.Errorf("unknown output format %s, must be table or json", statusOpts.Output)
+		}
+
+		if err := checkKubeConfig(&statusOpts.Kube); err != nil {
+			return err
+		}
+
+		overrides := &clientcmd.ConfigOverrides{}
+		overrides.Context.Namespace = statusOpts.Namespace
+		clientcfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: statusOpts.Kube.Config},
+			overrides,
+		)
+		res, err := clientcfg.ClientConfig()
+		if err != nil {
+			return err
+		}
+		namespace, _, err := clientcfg.Namespace()
+		if err != nil {
+			return err
+		}
+		client, err := kubernetes.NewForConfig(res)
+		if err != nil {
+			return err
+		}
+
+		report, err := status.Get(context.Background(), client, namespace, status.Options{
+			LogLines:      statusOpts.LogLines,
+			RestartWindow: statusOpts.RestartWindow,
+		})
+		if err != nil {
+			return err
+		}
+
+		if statusOpts.Output == "json" {
+			jsonOut, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(jsonOut))
+		} else {
+			err = printStatusTable(os.Stdout, report)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !report.Healthy {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+func printStatusTable(out io.Writer, report *status.Report) error {
+	fmt.Fprintf(out, "Namespace: %s\nVersion:   %s\n\n", report.Namespace, report.Version)
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tKIND\tREADY\tRESTARTS\tIMAGES\tSTATUS")
+	for _, c := range report.Components {
+		images := "up to date"
+		for _, i := range c.Images {
+			if len(i.Running) > 0 {
+				images = fmt.Sprintf("%s runs %s", i.Container, strings.Join(i.Running, ", "))
+				break
+			}
+		}
+
+		health := "healthy"
+		switch {
+		case c.Missing:
+			health, images = "missing", "-"
+		case !c.Healthy():
+			health = "unhealthy"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d/%d\t%d\t%s\t%s\n", c.Component, c.Kind, c.Ready, c.Desired, c.RecentRestarts, images, health)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, c := range report.Components {
+		for _, p := range c.FailingPods {
+			fmt.Fprintf(out, "\n%s: pod %s is failing: %s\n", c.Component, p.Name, p.Reason)
+			for _, l := range p.Logs {
+				fmt.Fprintf(out, "  %s\n", l)
+			}
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().StringVar(&statusOpts.Kube.Config, "kubeconfig", "", "path to the kubeconfig file")
+	statusCmd.Flags().StringVarP(&statusOpts.Namespace, "namespace", "n", "", "namespace the Bhojpur.NET Platform is deployed to, defaults to the kubeconfig namespace")
+	statusCmd.Flags().StringVarP(&statusOpts.Output, "output", "o", "table", "output format, either table or json")
+	statusCmd.Flags().Int64Var(&statusOpts.LogLines, "log-lines", 20, "number of log lines shown for each failing pod")
+	statusCmd.Flags().DurationVar(&statusOpts.RestartWindow, "restart-window", time.Hour, "restarts within this time are reported")
+}
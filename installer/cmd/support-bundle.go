@@ -0,0 +1,162 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bhojpur/platform/installer/pkg/cluster"
+	"github.com/bhojpur/platform/installer/pkg/config"
+	"github.com/bhojpur/platform/installer/pkg/supportbundle"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var supportBundleOpts struct {
+	Kube      kubeConfig
+	Config    string
+	Namespace string
+	Output    string
+	LogLines  int64
+}
+
+// supportBundleCmd represents the support-bundle command
+var supportBundleCmd = &cobra.Command{
+	Use:   "support-bundle",
+	Short: "Collects a diagnostic archive to share with support",
+	Long: `Collects a diagnostic archive to share with support
+The archive contains the config, the version manifest, the installed objects, the
+cluster validation results, the pod statuses, the events and the last log lines of
+every Bhojpur.NET Platform container. Secret values are redacted. Checks which run
+jobs in the cluster, like the database connection check, are skipped.`,
+	Example: `  bhojpur-installer support-bundle --config config.yaml --namespace default`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkKubeConfig(&supportBundleOpts.Kube); err != nil {
+			return err
+		}
+
+		overrides := &clientcmd.ConfigOverrides{}
+		overrides.Context.Namespace = supportBundleOpts.Namespace
+		clientcfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: supportBundleOpts.Kube.Config},
+			overrides,
+		)
+		res, err := clientcfg.ClientConfig()
+		if err != nil {
+			return err
+		}
+		namespace, _, err := clientcfg.Namespace()
+		if err != nil {
+			return err
+		}
+		client, err := kubernetes.NewForConfig(res)
+		if err != nil {
+			return err
+		}
+		dyn, err := dynamic.NewForConfig(res)
+		if err != nil {
+			return err
+		}
+		mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(client.Discovery()))
+
+		name := fmt.Sprintf("bhojpur-support-bundle-%s", time.Now().UTC().Format("20060102150405"))
+		output := supportBundleOpts.Output
+		if output == "" {
+			output = name + ".tar.gz"
+		}
+
+		// The bundle is redacted, but still describes the installation in detail
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		bundle := supportbundle.NewWriter(f, name)
+		ctx := context.Background()
+
+		if supportBundleOpts.Config != "" {
+			cfg, version, err := config.Load(supportBundleOpts.Config)
+			if err != nil {
+				bundle.AddError("config", err)
+			} else if err := addRedactedConfig(bundle, cfg, version); err != nil {
+				bundle.AddError("config", err)
+			}
+		}
+
+		versionMF, err := getVersionManifest()
+		if err != nil {
+			bundle.AddError("versions", err)
+		} else if err := bundle.AddYAML("versions.yaml", versionMF); err != nil {
+			return err
+		}
+
+		validation, err := cluster.ClusterChecks.Validate(ctx, res, namespace)
+		if err != nil {
+			bundle.AddError("validation", err)
+		} else {
+			if supportBundleOpts.Config != "" {
+				// Collecting the bundle must not change the cluster, so checks running jobs are skipped
+				checks, err := clusterConfigChecks(supportBundleOpts.Config)
+				if err != nil {
+					bundle.AddError("validation", err)
+				} else if cfgValidation, err := checks.ReadOnly().Validate(ctx, res, namespace); err != nil {
+					bundle.AddError("validation", err)
+				} else {
+					mergeValidationResults(validation, cfgValidation)
+				}
+			}
+			if err := bundle.AddYAML("validation.yaml", validation); err != nil {
+				return err
+			}
+		}
+
+		err = supportbundle.CollectCluster(ctx, client, dyn, mapper, namespace, supportBundleOpts.LogLines, bundle)
+		if err != nil {
+			return err
+		}
+
+		err = bundle.Close()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("support bundle written to %s\n", output)
+		return nil
+	},
+}
+
+func addRedactedConfig(bundle *supportbundle.Writer, cfg interface{}, version string) error {
+	apiVersion, err := config.LoadConfigVersion(version)
+	if err != nil {
+		return err
+	}
+	err = apiVersion.Redact(cfg)
+	if err != nil {
+		return err
+	}
+	fc, err := config.Marshal(version, cfg)
+	if err != nil {
+		return err
+	}
+	return bundle.Add("config.yaml", fc)
+}
+
+func init() {
+	rootCmd.AddCommand(supportBundleCmd)
+
+	supportBundleCmd.Flags().StringVar(&supportBundleOpts.Kube.Config, "kubeconfig", "", "path to the kubeconfig file")
+	supportBundleCmd.Flags().StringVarP(&supportBundleOpts.Config, "config", "c", os.Getenv("BHOJPUR_INSTALLER_CONFIG"), "path to the config file")
+	supportBundleCmd.Flags().StringVarP(&supportBundleOpts.Namespace, "namespace", "n", "", "namespace the Bhojpur.NET Platform is deployed to, defaults to the kubeconfig namespace")
+	supportBundleCmd.Flags().StringVarP(&supportBundleOpts.Output, "output", "o", "", "path of the archive, defaults to bhojpur-support-bundle-<timestamp>.tar.gz")
+	supportBundleCmd.Flags().Int64Var(&supportBundleOpts.LogLines, "log-lines", 500, "number of log lines collected from each container")
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/bhojpur/platform/installer/pkg/cluster"
 	"github.com/bhojpur/platform/installer/pkg/config"
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -50,24 +49,16 @@ var validateClusterCmd = &cobra.Command{
 		}
 
 		if validateClusterOpts.Config != "" {
-			res, err := runClusterConfigValidation(context.Background(), res, namespace)
+			checks, err := clusterConfigChecks(validateClusterOpts.Config)
 			if err != nil {
 				return err
 			}
-
-			// Update the status
-			switch res.Status {
-			case cluster.ValidationStatusError:
-				// Always change the status if error
-				result.Status = cluster.ValidationStatusError
-			case cluster.ValidationStatusWarning:
-				// Only put to warning if status is ok
-				if result.Status == cluster.ValidationStatusOk {
-					result.Status = cluster.ValidationStatusWarning
-				}
+			res, err := checks.Validate(context.Background(), res, namespace)
+			if err != nil {
+				return err
 			}
 
-			result.Items = append(result.Items, res.Items...)
+			mergeValidationResults(result, res)
 		}
 
 		jsonOut, err := json.MarshalIndent(result, "", "  ")
@@ -90,8 +81,24 @@ var validateClusterCmd = &cobra.Command{
 	},
 }
 
-func runClusterConfigValidation(ctx context.Context, restConfig *rest.Config, namespace string) (*cluster.ValidationResult, error) {
-	_, version, cfg, err := loadConfig(validateClusterOpts.Config)
+func mergeValidationResults(result *cluster.ValidationResult, res *cluster.ValidationResult) {
+	// Update the status
+	switch res.Status {
+	case cluster.ValidationStatusError:
+		// Always change the status if error
+		result.Status = cluster.ValidationStatusError
+	case cluster.ValidationStatusWarning:
+		// Only put to warning if status is ok
+		if result.Status == cluster.ValidationStatusOk {
+			result.Status = cluster.ValidationStatusWarning
+		}
+	}
+
+	result.Items = append(result.Items, res.Items...)
+}
+
+func clusterConfigChecks(cfgFN string) (cluster.ValidationChecks, error) {
+	_, version, cfg, err := loadConfig(cfgFN)
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +106,7 @@ func runClusterConfigValidation(ctx context.Context, restConfig *rest.Config, na
 	if err != nil {
 		return nil, err
 	}
-	return apiVersion.ClusterValidation(cfg).Validate(ctx, restConfig, namespace)
+	return apiVersion.ClusterValidation(cfg), nil
 }
 
 func init() {
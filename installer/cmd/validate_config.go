@@ -10,6 +10,8 @@ import (
 	"os"
 
 	"github.com/bhojpur/platform/installer/pkg/config"
+	configv1 "github.com/bhojpur/platform/installer/pkg/config/v1"
+	"github.com/bhojpur/platform/installer/pkg/helm"
 	"github.com/spf13/cobra"
 )
 
@@ -50,6 +52,13 @@ func runConfigValidation(version string, cfg interface{}) error {
 	if err != nil {
 		return err
 	}
+	if c, ok := cfg.(*configv1.Config); ok {
+		// The overrides need the embedded charts, which the config package doesn't know
+		if err := helm.CheckValues(c.HelmValues); err != nil {
+			res.Fatal = append(res.Fatal, err.Error())
+			res.Valid = false
+		}
+	}
 	res.Marshal(os.Stdout)
 	if len(res.Fatal) > 0 {
 		return fmt.Errorf("configuration invalid")
@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver"
+	cmv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	certmanager "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -22,6 +24,11 @@ const (
 	// additional information, which get interpreted as pre-release (eg, 1.2.3-rc4)
 	kernelVersionConstraint     = ">= 5.4.0-0"
 	kubernetesVersionConstraint = ">= 1.21.0-0"
+
+	// Pod Security Admission is enabled by default from 1.23 and PodSecurityPolicies
+	// are no longer served from 1.25
+	podSecurityAdmissionConstraint = ">= 1.23.0-0"
+	podSecurityPolicyConstraint    = "< 1.25.0-0"
 )
 
 // checkAffinityLabels validates that the nodes have all the required affinity labels applied
@@ -275,3 +282,135 @@ func checkKernelVersion(ctx context.Context, config *rest.Config, namespace stri
 
 	return res, nil
 }
+
+// CheckIssuer produces a new check for a cert-manager Issuer or ClusterIssuer
+func CheckIssuer(name string, clusterScoped bool) ValidationCheck {
+	kind := "Issuer"
+	if clusterScoped {
+		kind = "ClusterIssuer"
+	}
+
+	return ValidationCheck{
+		Name:        kind + " " + name + " is present and ready",
+		Description: "ensures the " + name + " " + kind + " is present and able to issue certificates",
+		Check: func(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error) {
+			client, err := certmanager.NewForConfig(config)
+			if err != nil {
+				return nil, err
+			}
+
+			var status cmv1.IssuerStatus
+			if clusterScoped {
+				var issuer *cmv1.ClusterIssuer
+				issuer, err = client.CertmanagerV1().ClusterIssuers().Get(ctx, name, metav1.GetOptions{})
+				if err == nil {
+					status = issuer.Status
+				}
+			} else {
+				var issuer *cmv1.Issuer
+				issuer, err = client.CertmanagerV1().Issuers(namespace).Get(ctx, name, metav1.GetOptions{})
+				if err == nil {
+					status = issuer.Status
+				}
+			}
+			if errors.IsNotFound(err) {
+				return []ValidationError{
+					{
+						Message: kind + " " + name + " not found",
+						Type:    ValidationStatusError,
+					},
+				}, nil
+			} else if err != nil {
+				return nil, err
+			}
+
+			for _, c := range status.Conditions {
+				if c.Type == cmv1.IssuerConditionReady && c.Status == cmmeta.ConditionTrue {
+					return nil, nil
+				}
+			}
+
+			// The issuer may still be registering with its ACME server
+			return []ValidationError{
+				{
+					Message: kind + " " + name + " is not ready",
+					Type:    ValidationStatusWarning,
+				},
+			}, nil
+		},
+	}
+}
+
+// UsePodSecurityAdmission returns true if a cluster running the given Kubernetes
+// version should use Pod Security Admission rather than PodSecurityPolicies
+func UsePodSecurityAdmission(kubeVersion string) (bool, error) {
+	constraint, err := semver.NewConstraint(podSecurityAdmissionConstraint)
+	if err != nil {
+		return false, err
+	}
+
+	version, err := semver.NewVersion(kubeVersion)
+	if err != nil {
+		return false, err
+	}
+
+	return constraint.Check(version), nil
+}
+
+// CheckPodSecurityMode produces a new check that the pod security mode is supported
+// by the cluster. A nil admission means the mode is chosen from the Kubernetes version.
+func CheckPodSecurityMode(admission *bool) ValidationCheck {
+	return ValidationCheck{
+		Name:        "pod security mode",
+		Description: "ensures the pod security mode is supported by the Kubernetes version",
+		Check: func(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error) {
+			server, err := serverVersion(ctx, config)
+			if err != nil {
+				return nil, err
+			}
+
+			version, err := semver.NewVersion(server.GitVersion)
+			if err != nil {
+				return []ValidationError{{
+					Message: err.Error() + " Kubernetes version: " + server.GitVersion,
+					Type:    ValidationStatusWarning,
+				}}, nil
+			}
+
+			pspConstraint, err := semver.NewConstraint(podSecurityPolicyConstraint)
+			if err != nil {
+				return nil, err
+			}
+			psaConstraint, err := semver.NewConstraint(podSecurityAdmissionConstraint)
+			if err != nil {
+				return nil, err
+			}
+
+			if admission == nil {
+				// Without a version, render uses Pod Security Admission
+				if !psaConstraint.Check(version) {
+					return []ValidationError{{
+						Message: "podSecurity.mode is not set and Kubernetes version " + server.GitVersion + " does not support Pod Security Admission - set podSecurity.mode to PodSecurityPolicy or render with --kube-version " + server.GitVersion,
+						Type:    ValidationStatusError,
+					}}, nil
+				}
+				return nil, nil
+			}
+
+			if *admission && !psaConstraint.Check(version) {
+				return []ValidationError{{
+					Message: "Kubernetes version " + server.GitVersion + " does not satisfy " + podSecurityAdmissionConstraint + " required for Pod Security Admission",
+					Type:    ValidationStatusError,
+				}}, nil
+			}
+			if !*admission && !pspConstraint.Check(version) {
+				return []ValidationError{{
+					Message: "Kubernetes version " + server.GitVersion + " no longer serves PodSecurityPolicies - set podSecurity.mode to PodSecurityAdmission",
+					Type:    ValidationStatusError,
+				}}, nil
+			}
+
+			return nil, nil
+		},
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/pointer"
+)
+
+func TestCheckPodSecurityMode(t *testing.T) {
+	tests := []struct {
+		Name        string
+		KubeVersion string
+		Admission   *bool
+		Expectation ValidationStatus
+	}{
+		{Name: "default on 1.22", KubeVersion: "v1.22.4", Expectation: ValidationStatusError},
+		{Name: "default on 1.23", KubeVersion: "v1.23.1", Expectation: ValidationStatusOk},
+		{Name: "default on 1.25", KubeVersion: "v1.25.2", Expectation: ValidationStatusOk},
+		{Name: "policies on 1.24", KubeVersion: "v1.24.0", Admission: pointer.Bool(false), Expectation: ValidationStatusOk},
+		{Name: "policies on 1.25", KubeVersion: "v1.25.0", Admission: pointer.Bool(false), Expectation: ValidationStatusError},
+		{Name: "admission on 1.22", KubeVersion: "v1.22.4", Admission: pointer.Bool(true), Expectation: ValidationStatusError},
+		{Name: "admission on 1.25", KubeVersion: "v1.25.2", Admission: pointer.Bool(true), Expectation: ValidationStatusOk},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewEncoder(w).Encode(version.Info{GitVersion: test.KubeVersion})
+			}))
+			defer srv.Close()
+
+			errs, err := CheckPodSecurityMode(test.Admission).Check(context.Background(), &rest.Config{Host: srv.URL}, "default")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			act := ValidationStatusOk
+			for _, e := range errs {
+				act = e.Type
+			}
+			if act != test.Expectation {
+				t.Errorf("unexpected status: got %s, expected %s (%v)", act, test.Expectation, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	databaseCheckImage          = "docker.io/library/mysql:5.7"
+	databaseCheckJobPrefix      = "bhojpur-db-check-"
+	databaseCheckCAPath         = "/etc/db-ca"
+	defaultDatabaseCheckTimeout = 10 * time.Second
+
+	// databaseCheckGracePeriod is the time allowed on top of the connect timeout
+	// to pull the image and schedule the pod
+	databaseCheckGracePeriod = 2 * time.Minute
+)
+
+type DatabaseConnectionOpts struct {
+	// SecretName is the secret holding the host, port, username and password
+	SecretName string
+	// SSLMode is passed to the MySQL client as --ssl-mode. Empty uses the client default.
+	SSLMode string
+	// CASecretName is a secret with a ca.crt entry used to verify the server
+	CASecretName string
+	// ConnectTimeout is the time the client waits for the connection to be established
+	ConnectTimeout time.Duration
+}
+
+// CheckDatabaseConnection produces a new check that runs a short-lived job in the cluster
+// to connect to an external database using the configured credentials and TLS settings
+func CheckDatabaseConnection(opts DatabaseConnectionOpts) ValidationCheck {
+	if opts.ConnectTimeout == 0 {
+		opts.ConnectTimeout = defaultDatabaseCheckTimeout
+	}
+
+	return ValidationCheck{
+		Name:        "database is reachable",
+		Description: "ensures the external database accepts connections from within the cluster",
+		CreatesJob:  true,
+		Check: func(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error) {
+			client, err := clientsetFromContext(ctx, config)
+			if err != nil {
+				return nil, err
+			}
+
+			job, err := client.BatchV1().Jobs(namespace).Create(ctx, databaseCheckJob(opts), metav1.CreateOptions{})
+			if err != nil {
+				return nil, err
+			}
+			defer func() {
+				// Use a fresh context so that the job is removed even if the check was cancelled
+				propagation := metav1.DeletePropagationBackground
+				_ = client.BatchV1().Jobs(namespace).Delete(context.Background(), job.Name, metav1.DeleteOptions{
+					PropagationPolicy: &propagation,
+				})
+			}()
+
+			var succeeded bool
+			err = wait.PollImmediate(time.Second, opts.ConnectTimeout+databaseCheckGracePeriod, func() (bool, error) {
+				j, err := client.BatchV1().Jobs(namespace).Get(ctx, job.Name, metav1.GetOptions{})
+				if err != nil {
+					return false, err
+				}
+				if j.Status.Succeeded > 0 {
+					succeeded = true
+					return true, nil
+				}
+				return j.Status.Failed > 0, nil
+			})
+			if err == wait.ErrWaitTimeout {
+				return []ValidationError{{
+					Message: fmt.Sprintf("database connection check did not complete in time, see job %s", job.Name),
+					Type:    ValidationStatusWarning,
+				}}, nil
+			} else if err != nil {
+				return nil, err
+			}
+
+			if succeeded {
+				return nil, nil
+			}
+
+			msg := "unable to connect to the database"
+			if logs := databaseCheckLogs(ctx, client, namespace, job.Name); logs != "" {
+				msg += ": " + logs
+			}
+			return []ValidationError{{
+				Message: msg,
+				Type:    ValidationStatusError,
+			}}, nil
+		},
+	}
+}
+
+func databaseCheckJob(opts DatabaseConnectionOpts) *batchv1.Job {
+	secretEnv := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: opts.SecretName},
+				Key:                  key,
+			}},
+		}
+	}
+
+	args := []string{
+		`--host="$DB_HOST"`,
+		`--port="$DB_PORT"`,
+		`--user="$DB_USERNAME"`,
+		// The client only accepts whole seconds
+		fmt.Sprintf("--connect-timeout=%d", int(math.Ceil(opts.ConnectTimeout.Seconds()))),
+	}
+	if opts.SSLMode != "" {
+		args = append(args, "--ssl-mode="+opts.SSLMode)
+	}
+
+	var (
+		volumes      []corev1.Volume
+		volumeMounts []corev1.VolumeMount
+	)
+	if opts.CASecretName != "" {
+		args = append(args, "--ssl-ca="+databaseCheckCAPath+"/ca.crt")
+		volumes = append(volumes, corev1.Volume{
+			Name: "ca",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName: opts.CASecretName,
+			}},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "ca",
+			MountPath: databaseCheckCAPath,
+			ReadOnly:  true,
+		})
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: databaseCheckJobPrefix,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            pointer.Int32(0),
+			TTLSecondsAfterFinished: pointer.Int32(60),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					AutomountServiceAccountToken: pointer.Bool(false),
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:    pointer.Int64(999),
+						RunAsNonRoot: pointer.Bool(true),
+					},
+					Volumes: volumes,
+					Containers: []corev1.Container{{
+						Name:    "check",
+						Image:   databaseCheckImage,
+						Command: []string{"sh", "-c"},
+						Args: []string{
+							fmt.Sprintf(`mysql %s --execute="SELECT 1"`, strings.Join(args, " ")),
+						},
+						Env: []corev1.EnvVar{
+							secretEnv("DB_HOST", "host"),
+							secretEnv("DB_PORT", "port"),
+							secretEnv("DB_USERNAME", "username"),
+							// The MySQL client reads the password from the environment
+							secretEnv("MYSQL_PWD", "password"),
+						},
+						VolumeMounts: volumeMounts,
+					}},
+				},
+			},
+		},
+	}
+}
+
+// databaseCheckLogs returns the output of the check pod, or an empty string if it can't be read
+func databaseCheckLogs(ctx context.Context, client kubernetes.Interface, namespace, jobName string) string {
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "job-name=" + jobName,
+	})
+	if err != nil || len(pods.Items) == 0 {
+		return ""
+	}
+
+	raw, err := client.CoreV1().Pods(namespace).GetLogs(pods.Items[0].Name, &corev1.PodLogOptions{}).DoRaw(ctx)
+	if err != nil {
+		return ""
+	}
+	return string(bytes.TrimSpace(raw))
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cluster
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDatabaseCheckJob(t *testing.T) {
+	type Expectation struct {
+		Args    []string
+		Volumes []string
+		Mounts  []string
+	}
+	tests := []struct {
+		Name        string
+		Opts        DatabaseConnectionOpts
+		Expectation Expectation
+	}{
+		{
+			Name: "no TLS",
+			Opts: DatabaseConnectionOpts{SecretName: "database-token", ConnectTimeout: defaultDatabaseCheckTimeout},
+			Expectation: Expectation{
+				Args: []string{"--connect-timeout=10"},
+			},
+		},
+		{
+			Name: "require TLS",
+			Opts: DatabaseConnectionOpts{SecretName: "database-token", SSLMode: "REQUIRED", ConnectTimeout: 1500 * time.Millisecond},
+			Expectation: Expectation{
+				Args: []string{"--connect-timeout=2", "--ssl-mode=REQUIRED"},
+			},
+		},
+		{
+			Name: "verify the server",
+			Opts: DatabaseConnectionOpts{SecretName: "database-token", SSLMode: "VERIFY_IDENTITY", CASecretName: "database-ca", ConnectTimeout: defaultDatabaseCheckTimeout},
+			Expectation: Expectation{
+				Args:    []string{"--connect-timeout=10", "--ssl-mode=VERIFY_IDENTITY", "--ssl-ca=" + databaseCheckCAPath + "/ca.crt"},
+				Volumes: []string{"database-ca"},
+				Mounts:  []string{databaseCheckCAPath},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			pod := databaseCheckJob(test.Opts).Spec.Template.Spec
+			container := pod.Containers[0]
+
+			var act Expectation
+			for _, arg := range strings.Fields(container.Args[0]) {
+				if strings.HasPrefix(arg, "--connect-timeout") || strings.HasPrefix(arg, "--ssl-") {
+					act.Args = append(act.Args, arg)
+				}
+			}
+			for _, v := range pod.Volumes {
+				act.Volumes = append(act.Volumes, v.Secret.SecretName)
+			}
+			for _, m := range container.VolumeMounts {
+				act.Mounts = append(act.Mounts, m.MountPath)
+			}
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("databaseCheckJob() mismatch (-want +got):\n%s", diff)
+			}
+
+			for _, env := range container.Env {
+				if ref := env.ValueFrom.SecretKeyRef; ref == nil || ref.Name != "database-token" {
+					t.Errorf("%s is not read from the database secret", env.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckDatabaseConnection(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Succeeded   bool
+		Expectation ValidationStatus
+	}{
+		{Name: "connected", Succeeded: true, Expectation: ValidationStatusOk},
+		{Name: "failed", Succeeded: false, Expectation: ValidationStatusError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			client.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				// the fake client neither generates names nor runs jobs
+				job := action.(k8stesting.CreateAction).GetObject().(*batchv1.Job)
+				job.Name = job.GenerateName + "test"
+				if test.Succeeded {
+					job.Status.Succeeded = 1
+				} else {
+					job.Status.Failed = 1
+				}
+				return false, nil, nil
+			})
+			ctx := context.WithValue(context.Background(), keyClientset, client)
+
+			errs, err := CheckDatabaseConnection(DatabaseConnectionOpts{SecretName: "database-token"}).Check(ctx, nil, "default")
+			if err != nil {
+				t.Fatal(err)
+			}
+			act := ValidationStatusOk
+			for _, e := range errs {
+				act = e.Type
+			}
+			if act != test.Expectation {
+				t.Errorf("unexpected status: got %s, expected %s (%v)", act, test.Expectation, errs)
+			}
+
+			jobs, err := client.BatchV1().Jobs("default").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(jobs.Items) != 0 {
+				t.Errorf("check job was not removed")
+			}
+		})
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	checks := ValidationChecks{
+		CheckPodSecurityMode(nil),
+		CheckDatabaseConnection(DatabaseConnectionOpts{SecretName: "database"}),
+		CheckSecret("https-certificates"),
+	}
+
+	var act []string
+	for _, check := range checks.ReadOnly() {
+		act = append(act, check.Name)
+	}
+	expectation := []string{"pod security mode", "https-certificates is present and valid"}
+	if diff := cmp.Diff(expectation, act); diff != "" {
+		t.Errorf("ReadOnly() mismatch (-want +got):\n%s", diff)
+	}
+}
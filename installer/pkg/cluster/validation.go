@@ -33,6 +33,8 @@ type ValidationCheck struct {
 	Name        string              `json:"name"`
 	Description string              `json:"description"`
 	Check       ValidationCheckFunc `json:"-"`
+	// CreatesJob is set if the check runs a job in the cluster, rather than only reading from it
+	CreatesJob bool `json:"-"`
 }
 
 type ValidationCheckFunc func(ctx context.Context, config *rest.Config, namespace string) ([]ValidationError, error)
@@ -82,6 +84,17 @@ type ValidationChecks []ValidationCheck
 
 func (v ValidationChecks) Len() int { return len(v) }
 
+// ReadOnly returns the checks which do not create jobs in the cluster
+func (v ValidationChecks) ReadOnly() ValidationChecks {
+	res := make(ValidationChecks, 0, len(v))
+	for _, check := range v {
+		if !check.CreatesJob {
+			res = append(res, check)
+		}
+	}
+	return res
+}
+
 // Validate runs the checks
 func (checks ValidationChecks) Validate(ctx context.Context, config *rest.Config, namespace string) (*ValidationResult, error) {
 	results := &ValidationResult{
@@ -0,0 +1,139 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// AuthProviderMount is where the credentials of the auth providers are mounted in the server
+const AuthProviderMount = "/mnt/secrets/auth-providers"
+
+// AuthProvider is an auth provider as read by the server
+type AuthProvider struct {
+	config.AuthProviderConfigs
+	OAuth AuthProviderOAuth `json:"oauth"`
+}
+
+// AuthProviderOAuth replaces the client ID and secret with the files they are read
+// from, if they are stored in a secret
+type AuthProviderOAuth struct {
+	config.OAuth
+	ClientIdFile     string `json:"clientIdFile,omitempty"`
+	ClientSecretFile string `json:"clientSecretFile,omitempty"`
+}
+
+func authProviderVolume(idx int) string {
+	return fmt.Sprintf("auth-provider-%d", idx)
+}
+
+// AuthProviders produces the server configuration of the auth providers from the installer
+// config, with the defaults of their type filled in
+func AuthProviders(ctx *RenderContext) []AuthProvider {
+	res := make([]AuthProvider, 0, len(ctx.Config.AuthProviders))
+	for i, p := range ctx.Config.AuthProviders {
+		p = p.WithDefaults(ctx.Config.Domain)
+		provider := AuthProvider{
+			AuthProviderConfigs: p,
+			OAuth:               AuthProviderOAuth{OAuth: p.OAuth},
+		}
+		if p.OAuth.Credentials != nil {
+			dir := filepath.Join(AuthProviderMount, authProviderVolume(i))
+			provider.OAuth.Credentials = nil
+			provider.OAuth.ClientIdFile = filepath.Join(dir, "clientId")
+			provider.OAuth.ClientSecretFile = filepath.Join(dir, "clientSecret")
+		}
+		res = append(res, provider)
+	}
+	return res
+}
+
+// AddAuthProviderMounts mounts the secrets of the auth providers whose credentials are
+// stored in a secret. If a list of containers is provided, the mounts are only added to
+// those containers. If the list is empty, they're added to all containers.
+func AddAuthProviderMounts(ctx *RenderContext, pod *corev1.PodSpec, container ...string) {
+	idx := make(map[string]struct{}, len(container))
+	for _, c := range container {
+		idx[c] = struct{}{}
+	}
+
+	for i, p := range ctx.Config.AuthProviders {
+		if p.OAuth.Credentials == nil {
+			continue
+		}
+
+		volumeName := authProviderVolume(i)
+		pod.Volumes = append(pod.Volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: p.OAuth.Credentials.Name,
+				},
+			},
+		})
+
+		for c := range pod.Containers {
+			if _, ok := idx[pod.Containers[c].Name]; len(container) > 0 && !ok {
+				continue
+			}
+
+			pod.Containers[c].VolumeMounts = append(pod.Containers[c].VolumeMounts, corev1.VolumeMount{
+				Name:      volumeName,
+				ReadOnly:  true,
+				MountPath: filepath.Join(AuthProviderMount, volumeName),
+			})
+		}
+	}
+}
+
+// WithAuthProviders sets the auth providers in the config.json of the server config map
+// and mounts their secrets into the server deployment
+func WithAuthProviders(f RenderFunc) RenderFunc {
+	return func(ctx *RenderContext) ([]runtime.Object, error) {
+		objs, err := f(ctx)
+		if err != nil || len(ctx.Config.AuthProviders) == 0 {
+			return objs, err
+		}
+
+		var configured, mounted bool
+		for _, obj := range objs {
+			switch o := obj.(type) {
+			case *corev1.ConfigMap:
+				if o.Name != ServerComponent || o.Data["config.json"] == "" {
+					continue
+				}
+				var cfg map[string]interface{}
+				if err := json.Unmarshal([]byte(o.Data["config.json"]), &cfg); err != nil {
+					return nil, fmt.Errorf("cannot parse the server config: %w", err)
+				}
+				cfg["authProviderConfigs"] = AuthProviders(ctx)
+				fc, err := json.MarshalIndent(cfg, "", " ")
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal server config: %w", err)
+				}
+				o.Data["config.json"] = string(fc)
+				configured = true
+			case *appsv1.Deployment:
+				if o.Name != ServerComponent {
+					continue
+				}
+				AddAuthProviderMounts(ctx, &o.Spec.Template.Spec, ServerComponent)
+				mounted = true
+			}
+		}
+		if !configured || !mounted {
+			return nil, fmt.Errorf("no %s config map and deployment to add the auth providers to", ServerComponent)
+		}
+
+		return objs, nil
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package common_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func serverObjects(ctx *common.RenderContext) ([]runtime.Object, error) {
+	return []runtime.Object{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: common.ServerComponent},
+			Data:       map[string]string{"config.json": `{"hostUrl":"https://example.com"}`},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: common.ServerComponent},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: common.ServerComponent}, {Name: "kube-rbac-proxy"}},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func TestWithAuthProviders(t *testing.T) {
+	type Expectation struct {
+		Providers []map[string]interface{}
+		Volumes   []string
+		Mounts    map[string][]string
+	}
+	tests := []struct {
+		Name          string
+		AuthProviders []config.AuthProviderConfigs
+		Expectation   Expectation
+	}{
+		{
+			Name: "no auth providers",
+			Expectation: Expectation{
+				Mounts: map[string][]string{},
+			},
+		},
+		{
+			Name: "inline and secret credentials",
+			AuthProviders: []config.AuthProviderConfigs{
+				{
+					ID:    "Public-GitHub",
+					Host:  "github.com",
+					Type:  config.AuthProviderGitHub,
+					OAuth: config.OAuth{ClientId: "id", ClientSecret: "secret"},
+				},
+				{
+					ID:    "GitLab",
+					Host:  "gitlab.example.com",
+					Type:  config.AuthProviderGitLab,
+					OAuth: config.OAuth{Credentials: &config.ObjectRef{Kind: config.ObjectRefSecret, Name: "gitlab-oauth"}},
+				},
+			},
+			Expectation: Expectation{
+				Providers: []map[string]interface{}{
+					{
+						"id":           "Public-GitHub",
+						"callBackUrl":  "https://bhojpur.example.com/auth/github.com/callback",
+						"clientId":     "id",
+						"clientSecret": "secret",
+					},
+					{
+						"id":               "GitLab",
+						"callBackUrl":      "https://bhojpur.example.com/auth/gitlab.example.com/callback",
+						"clientIdFile":     "/mnt/secrets/auth-providers/auth-provider-1/clientId",
+						"clientSecretFile": "/mnt/secrets/auth-providers/auth-provider-1/clientSecret",
+					},
+				},
+				Volumes: []string{"auth-provider-1"},
+				Mounts: map[string][]string{
+					common.ServerComponent: {"/mnt/secrets/auth-providers/auth-provider-1"},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx := &common.RenderContext{
+				Config: config.Config{
+					Domain:        "bhojpur.example.com",
+					AuthProviders: test.AuthProviders,
+				},
+			}
+
+			objs, err := common.WithAuthProviders(serverObjects)(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			act := Expectation{Mounts: make(map[string][]string)}
+
+			var server struct {
+				AuthProviderConfigs []struct {
+					ID    string                 `json:"id"`
+					OAuth map[string]interface{} `json:"oauth"`
+				} `json:"authProviderConfigs"`
+			}
+			if err := json.Unmarshal([]byte(objs[0].(*corev1.ConfigMap).Data["config.json"]), &server); err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range server.AuthProviderConfigs {
+				provider := map[string]interface{}{"id": p.ID}
+				for _, k := range []string{"callBackUrl", "clientId", "clientSecret", "clientIdFile", "clientSecretFile"} {
+					if v, ok := p.OAuth[k]; ok {
+						provider[k] = v
+					}
+				}
+				act.Providers = append(act.Providers, provider)
+			}
+
+			pod := objs[1].(*appsv1.Deployment).Spec.Template.Spec
+			for _, v := range pod.Volumes {
+				act.Volumes = append(act.Volumes, v.Name)
+			}
+			for _, c := range pod.Containers {
+				for _, m := range c.VolumeMounts {
+					act.Mounts[c.Name] = append(act.Mounts[c.Name], m.MountPath)
+				}
+			}
+
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("WithAuthProviders() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithAuthProvidersWithoutServer(t *testing.T) {
+	ctx := &common.RenderContext{
+		Config: config.Config{
+			AuthProviders: []config.AuthProviderConfigs{{ID: "Public-GitHub", Host: "github.com", Type: config.AuthProviderGitHub}},
+		},
+	}
+
+	_, err := common.WithAuthProviders(common.CompositeRenderFunc())(ctx)
+	if err == nil {
+		t.Fatal("expected an error without a server config map and deployment")
+	}
+}
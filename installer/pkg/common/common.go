@@ -137,6 +137,57 @@ func MessageBusEnv(_ *config.Config) (res []corev1.EnvVar) {
 	}}
 }
 
+// externalDatabaseEnv configures the TLS, connection pool and timeouts of an external database
+func externalDatabaseEnv(db *config.DatabaseExternal) (res []corev1.EnvVar) {
+	if db.TLS != nil {
+		res = append(res, corev1.EnvVar{
+			Name:  "DB_TLS_MODE",
+			Value: string(db.TLS.Mode),
+		})
+		if db.TLS.CA != nil {
+			res = append(res, corev1.EnvVar{
+				Name: "DB_CA_CERT",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: db.TLS.CA.Name},
+					Key:                  "ca.crt",
+				}},
+			})
+		}
+	}
+
+	if db.Pool != nil {
+		if db.Pool.MaxConnections > 0 {
+			res = append(res, corev1.EnvVar{
+				Name:  "DB_MAX_CONNECTIONS",
+				Value: fmt.Sprintf("%d", db.Pool.MaxConnections),
+			})
+		}
+		if db.Pool.MaxIdleConnections > 0 {
+			res = append(res, corev1.EnvVar{
+				Name:  "DB_MAX_IDLE_CONNECTIONS",
+				Value: fmt.Sprintf("%d", db.Pool.MaxIdleConnections),
+			})
+		}
+	}
+
+	if db.Timeouts != nil {
+		if db.Timeouts.Connect != nil {
+			res = append(res, corev1.EnvVar{
+				Name:  "DB_CONNECT_TIMEOUT_MS",
+				Value: fmt.Sprintf("%d", db.Timeouts.Connect.Milliseconds()),
+			})
+		}
+		if db.Timeouts.Idle != nil {
+			res = append(res, corev1.EnvVar{
+				Name:  "DB_IDLE_TIMEOUT_MS",
+				Value: fmt.Sprintf("%d", db.Timeouts.Idle.Milliseconds()),
+			})
+		}
+	}
+
+	return res
+}
+
 func DatabaseEnv(cfg *config.Config) (res []corev1.EnvVar) {
 	var (
 		secretRef corev1.LocalObjectReference
@@ -180,6 +231,7 @@ func DatabaseEnv(cfg *config.Config) (res []corev1.EnvVar) {
 				}},
 			},
 		)
+		envvars = append(envvars, externalDatabaseEnv(cfg.Database.External)...)
 	} else if cfg.Database.CloudSQL != nil && cfg.Database.CloudSQL.ServiceAccount.Name != "" {
 		// GCP
 		secretRef = corev1.LocalObjectReference{Name: cfg.Database.CloudSQL.ServiceAccount.Name}
@@ -228,6 +280,12 @@ func DatabaseEnv(cfg *config.Config) (res []corev1.EnvVar) {
 	return envvars
 }
 
+// UsePodSecurityAdmission returns true if pods are secured by Pod Security Admission
+// rather than by PodSecurityPolicies
+func UsePodSecurityAdmission(cfg *config.Config) bool {
+	return cfg.PodSecurity.Mode == config.PodSecurityModeAdmission
+}
+
 func DatabaseWaiterContainer(ctx *RenderContext) *corev1.Container {
 	return &corev1.Container{
 		Name:  "database-waiter",
@@ -407,7 +465,7 @@ var DeploymentStrategy = appsv1.DeploymentStrategy{
 var (
 	TypeMetaNamespace = metav1.TypeMeta{
 		APIVersion: "v1",
-		Kind:       "namespace",
+		Kind:       "Namespace",
 	}
 	TypeMetaStatefulSet = metav1.TypeMeta{
 		APIVersion: "apps/v1",
@@ -481,6 +539,10 @@ var (
 		APIVersion: "batch/v1",
 		Kind:       "Job",
 	}
+	TypeMetaBatchCronJob = metav1.TypeMeta{
+		APIVersion: "batch/v1",
+		Kind:       "CronJob",
+	}
 )
 
 // validCookieChars contains all characters which may occur in an HTTP Cookie value (unicode \u0021 through \u007E),
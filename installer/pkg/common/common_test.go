@@ -6,9 +6,12 @@ package common_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/bhojpur/platform/installer/pkg/common"
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
 	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestRepoName(t *testing.T) {
@@ -61,3 +64,73 @@ func TestRepoName(t *testing.T) {
 		})
 	}
 }
+
+func TestDatabaseEnv(t *testing.T) {
+	tests := []struct {
+		Name        string
+		External    *config.DatabaseExternal
+		Expectation map[string]string
+	}{
+		{
+			Name:     "credentials only",
+			External: &config.DatabaseExternal{Certificate: config.ObjectRef{Kind: config.ObjectRefSecret, Name: "database-token"}},
+			Expectation: map[string]string{
+				"DB_HOST":                       "database-token/host",
+				"DB_PORT":                       "database-token/port",
+				"DB_PASSWORD":                   "database-token/password",
+				"DB_USERNAME":                   "database-token/username",
+				"DB_ENCRYPTION_KEYS":            "database-token/encryptionKeys",
+				"DB_DELETED_ENTRIES_GC_ENABLED": "false",
+			},
+		},
+		{
+			Name: "TLS, pool and timeouts",
+			External: &config.DatabaseExternal{
+				Certificate: config.ObjectRef{Kind: config.ObjectRefSecret, Name: "database-token"},
+				TLS: &config.DatabaseTLS{
+					Mode: config.DatabaseTLSVerifyFull,
+					CA:   &config.ObjectRef{Kind: config.ObjectRefSecret, Name: "database-ca"},
+				},
+				Pool: &config.DatabasePool{MaxConnections: 20, MaxIdleConnections: 5},
+				Timeouts: &config.DatabaseTimeouts{
+					Connect: &metav1.Duration{Duration: 10 * time.Second},
+					Idle:    &metav1.Duration{Duration: 5 * time.Minute},
+				},
+			},
+			Expectation: map[string]string{
+				"DB_HOST":                       "database-token/host",
+				"DB_PORT":                       "database-token/port",
+				"DB_TLS_MODE":                   "verify-full",
+				"DB_CA_CERT":                    "database-ca/ca.crt",
+				"DB_MAX_CONNECTIONS":            "20",
+				"DB_MAX_IDLE_CONNECTIONS":       "5",
+				"DB_CONNECT_TIMEOUT_MS":         "10000",
+				"DB_IDLE_TIMEOUT_MS":            "300000",
+				"DB_PASSWORD":                   "database-token/password",
+				"DB_USERNAME":                   "database-token/username",
+				"DB_ENCRYPTION_KEYS":            "database-token/encryptionKeys",
+				"DB_DELETED_ENTRIES_GC_ENABLED": "false",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			env := common.DatabaseEnv(&config.Config{Database: config.Database{External: test.External}})
+
+			act := make(map[string]string, len(env))
+			for _, e := range env {
+				if e.ValueFrom != nil {
+					ref := e.ValueFrom.SecretKeyRef
+					act[e.Name] = ref.Name + "/" + ref.Key
+					continue
+				}
+				act[e.Name] = e.Value
+			}
+
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("DatabaseEnv() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
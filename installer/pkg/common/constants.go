@@ -19,8 +19,10 @@ const (
 	DockerRegistryName          = "registry"
 	BhojpurContainerRegistry    = "us-west2-docker.pkg.dev/bhojpur/platform/build"
 	InClusterDbSecret           = "mysql"
+	InstallationConfigMap       = "bhojpur-app"
 	InClusterMessageQueueName   = "rabbitmq"
 	InClusterMessageQueueTLS    = "messagebus-certificates-secret-core"
+	InClusterStorageSecret      = "minio"
 	KubeRBACProxyRepo           = "quay.io"
 	KubeRBACProxyImage          = "brancz/kube-rbac-proxy"
 	KubeRBACProxyTag            = "v0.11.0"
@@ -34,6 +34,7 @@ var sortOrder = []string{
 	"PersistentVolume",
 	"PersistentVolumeClaim",
 	"CustomResourceDefinition",
+	"ConstraintTemplate",
 	"ClusterRole",
 	"ClusterRoleList",
 	"ClusterRoleBinding",
@@ -67,10 +68,17 @@ func DependencySortingRenderFunc(objects []RuntimeObject) ([]RuntimeObject, erro
 	for k, v := range sortOrder {
 		sortMap[v] = k
 	}
+	score := func(kind string) int {
+		// As with Helm, unknown kinds (eg, custom resources) get installed last
+		if s, ok := sortMap[kind]; ok {
+			return s
+		}
+		return len(sortOrder)
+	}
 
-	sort.Slice(objects, func(i, j int) bool {
-		scoreI := sortMap[objects[i].Kind]
-		scoreJ := sortMap[objects[j].Kind]
+	sort.SliceStable(objects, func(i, j int) bool {
+		scoreI := score(objects[i].Kind)
+		scoreJ := score(objects[j].Kind)
 
 		return scoreI < scoreJ
 	})
@@ -80,11 +88,12 @@ func DependencySortingRenderFunc(objects []RuntimeObject) ([]RuntimeObject, erro
 
 func GenerateInstallationConfigMap(ctx *RenderContext, objects []RuntimeObject) ([]RuntimeObject, error) {
 	cfgMapData := make([]string, 0)
-	component := "bhojpur-app"
+	component := InstallationConfigMap
 
 	// Convert to a simplified object that allows us to access the objects
 	for _, c := range objects {
-		if c.Kind != "" {
+		// Namespaces are left out so that uninstalling does not remove them
+		if c.Kind != "" && c.Kind != TypeMetaNamespace.Kind {
 			marshal, err := yaml.Marshal(c)
 			if err != nil {
 				return nil, err
@@ -50,6 +50,26 @@ func CompositeHelmFunc(f ...HelmFunc) HelmFunc {
 	}
 }
 
+// ComponentRenderFunc only renders the objects if the component is enabled in the config
+func ComponentRenderFunc(component string, f RenderFunc) RenderFunc {
+	return func(ctx *RenderContext) ([]runtime.Object, error) {
+		if !ctx.Config.Components.Enabled(component) {
+			return nil, nil
+		}
+		return f(ctx)
+	}
+}
+
+// ComponentHelmFunc only renders the charts if the component is enabled in the config
+func ComponentHelmFunc(component string, f HelmFunc) HelmFunc {
+	return func(ctx *RenderContext) ([]string, error) {
+		if !ctx.Config.Components.Enabled(component) {
+			return nil, nil
+		}
+		return f(ctx)
+	}
+}
+
 type GeneratedValues struct {
 	StorageAccessKey         string
 	StorageSecretKey         string
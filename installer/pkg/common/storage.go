@@ -14,7 +14,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
-const storageMount = "/mnt/secrets/storage"
+// StorageMount is where the object storage credentials are mounted in the containers
+const StorageMount = "/mnt/secrets/storage"
 
 // StorageConfig produces config service configuration from the installer config
 
@@ -37,7 +38,7 @@ func StorageConfig(context *RenderContext) storageconfig.StorageConfig {
 			GCloudConfig: storageconfig.GCPConfig{
 				Region:             context.Config.Metadata.Region,
 				Project:            context.Config.ObjectStorage.CloudStorage.Project,
-				CredentialsFile:    filepath.Join(storageMount, "service-account.json"),
+				CredentialsFile:    filepath.Join(StorageMount, "service-account.json"),
 				ParallelUpload:     6,
 				MaximumBackupCount: 3,
 			},
@@ -49,8 +50,8 @@ func StorageConfig(context *RenderContext) storageconfig.StorageConfig {
 			Kind: storageconfig.MinIOStorage,
 			MinIOConfig: storageconfig.MinIOConfig{
 				Endpoint:            context.Config.ObjectStorage.S3.Endpoint,
-				AccessKeyIdFile:     filepath.Join(storageMount, "accessKeyId"),
-				SecretAccessKeyFile: filepath.Join(storageMount, "secretAccessKey"),
+				AccessKeyIdFile:     filepath.Join(StorageMount, "accessKeyId"),
+				SecretAccessKeyFile: filepath.Join(StorageMount, "secretAccessKey"),
 				Secure:              true,
 				Region:              context.Config.Metadata.Region,
 				ParallelUpload:      100,
@@ -125,7 +126,7 @@ func mountStorage(pod *corev1.PodSpec, secret string, container ...string) {
 			corev1.VolumeMount{
 				Name:      volumeName,
 				ReadOnly:  true,
-				MountPath: storageMount,
+				MountPath: StorageMount,
 			},
 		)
 	}
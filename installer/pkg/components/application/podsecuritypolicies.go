@@ -15,6 +15,10 @@ import (
 )
 
 func podsecuritypolicies(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if common.UsePodSecurityAdmission(&ctx.Config) {
+		return nil, nil
+	}
+
 	return []runtime.Object{
 		&v1beta1.PodSecurityPolicy{
 			TypeMeta: common.TypeMetaPodSecurityPolicy,
@@ -0,0 +1,145 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bhojpur/platform/installer/pkg/cluster"
+	"github.com/bhojpur/platform/installer/pkg/common"
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/utils/pointer"
+)
+
+// PreviewPod approximates the pod the workspace manager creates for a workspace of the
+// given type. The default template and the template of the type are merged into it
+// in that order, as the workspace manager does.
+func PreviewPod(ctx *common.RenderContext, workspaceType string) (*corev1.Pod, error) {
+	known := false
+	for _, t := range config.ApplicationTemplateTypes {
+		if t == workspaceType {
+			known = true
+		}
+	}
+	if !known {
+		return nil, fmt.Errorf("unknown workspace type %s, must be one of %v", workspaceType, config.ApplicationTemplateTypes)
+	}
+
+	affinity := cluster.AffinityLabelApplicationRegular
+	if workspaceType == "prebuild" || workspaceType == "imagebuild" || workspaceType == "probe" {
+		affinity = cluster.AffinityLabelApplicationHeadless
+	}
+
+	labels := common.DefaultLabels(Component)
+	labels["workspaceType"] = workspaceType
+
+	pod := &corev1.Pod{
+		TypeMeta: common.TypeMetaPod,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("ws-%s-preview", workspaceType),
+			Namespace: ctx.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PodSpec{
+			Affinity:                     common.Affinity(affinity),
+			ServiceAccountName:           Component,
+			AutomountServiceAccountToken: pointer.Bool(false),
+			EnableServiceLinks:           pointer.Bool(false),
+			RestartPolicy:                corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:  config.ApplicationContainer,
+				Image: common.ImageName(common.ThirdPartyContainerRepo(ctx.Config.Repository, common.DockerRegistryURL), DefaultApplicationImage, DefaultApplicationImageVersion),
+				Ports: []corev1.ContainerPort{
+					{Name: "default", ContainerPort: ContainerPort},
+					{Name: "supervisor", ContainerPort: SupervisorPort},
+				},
+				Resources: corev1.ResourceRequirements{
+					Requests: ctx.Config.Application.Resources.Requests,
+					Limits:   ctx.Config.Application.Resources.Limits,
+				},
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: config.ApplicationVolume, MountPath: "/workspace"},
+					{Name: config.ApplicationDaemonVolume, MountPath: "/.workspace"},
+				},
+			}},
+			// The workspace manager uses host paths, which depend on the node
+			Volumes: []corev1.Volume{
+				{Name: config.ApplicationVolume, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+				{Name: config.ApplicationDaemonVolume, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+		},
+	}
+
+	for _, t := range []string{"default", workspaceType} {
+		tpl := ctx.Config.Application.Templates.Get(t)
+		if tpl == nil {
+			continue
+		}
+
+		var err error
+		pod, err = mergeTemplate(pod, tpl)
+		if err != nil {
+			return nil, fmt.Errorf("cannot merge the %s template: %w", t, err)
+		}
+	}
+
+	return pod, nil
+}
+
+func mergeTemplate(pod *corev1.Pod, tpl *corev1.Pod) (*corev1.Pod, error) {
+	original, err := json.Marshal(pod)
+	if err != nil {
+		return nil, err
+	}
+	// Unset fields, such as containers, are marshalled as null which would delete
+	// them in a merge patch
+	fc, err := json.Marshal(tpl)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	err = json.Unmarshal(fc, &values)
+	if err != nil {
+		return nil, err
+	}
+	removeNulls(values)
+	patch, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := strategicpatch.StrategicMergePatch(original, patch, corev1.Pod{})
+	if err != nil {
+		return nil, err
+	}
+
+	var res corev1.Pod
+	err = json.Unmarshal(merged, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func removeNulls(values map[string]interface{}) {
+	for k, v := range values {
+		switch val := v.(type) {
+		case nil:
+			delete(values, k)
+		case map[string]interface{}:
+			removeNulls(val)
+		case []interface{}:
+			for _, e := range val {
+				if m, ok := e.(map[string]interface{}); ok {
+					removeNulls(m)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package application
+
+import (
+	"testing"
+
+	"github.com/bhojpur/platform/installer/pkg/cluster"
+	"github.com/bhojpur/platform/installer/pkg/common"
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestPreviewPod(t *testing.T) {
+	type Expectation struct {
+		Affinity   string
+		Label      string
+		Containers []string
+		Env        []corev1.EnvVar
+		Resources  corev1.ResourceList
+		Volumes    []string
+		Mounts     []string
+		Err        bool
+	}
+
+	templates := &config.ApplicationTemplates{
+		Default: &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name: config.ApplicationContainer,
+					Env:  []corev1.EnvVar{{Name: "FOO", Value: "default"}},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "cache", MountPath: "/cache"},
+					},
+				}},
+				Volumes: []corev1.Volume{{Name: "cache", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+			},
+		},
+		Prebuild: &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: config.ApplicationContainer,
+						Env:  []corev1.EnvVar{{Name: "BAR", Value: "prebuild"}},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+						},
+					},
+					{Name: "sidecar"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		Name          string
+		WorkspaceType string
+		Expectation   Expectation
+	}{
+		{
+			Name:          "regular",
+			WorkspaceType: "regular",
+			Expectation: Expectation{
+				Affinity:   cluster.AffinityLabelApplicationRegular,
+				Label:      "regular",
+				Containers: []string{config.ApplicationContainer},
+				Env:        []corev1.EnvVar{{Name: "FOO", Value: "default"}},
+				Resources: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+				Volumes: []string{"cache", config.ApplicationVolume, config.ApplicationDaemonVolume},
+				Mounts:  []string{"/cache", "/workspace", "/.workspace"},
+			},
+		},
+		{
+			Name:          "prebuild",
+			WorkspaceType: "prebuild",
+			Expectation: Expectation{
+				Affinity:   cluster.AffinityLabelApplicationHeadless,
+				Label:      "prebuild",
+				Containers: []string{config.ApplicationContainer, "sidecar"},
+				Env:        []corev1.EnvVar{{Name: "BAR", Value: "prebuild"}, {Name: "FOO", Value: "default"}},
+				Resources: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+				Volumes: []string{"cache", config.ApplicationVolume, config.ApplicationDaemonVolume},
+				Mounts:  []string{"/cache", "/workspace", "/.workspace"},
+			},
+		},
+		{
+			Name:          "unknown type",
+			WorkspaceType: "nightly",
+			Expectation:   Expectation{Err: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx := &common.RenderContext{
+				Namespace: "default",
+				Config: config.Config{
+					Application: config.Application{
+						Resources: config.Resources{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("1"),
+								corev1.ResourceMemory: resource.MustParse("2Gi"),
+							},
+						},
+						Templates: templates,
+					},
+				},
+			}
+
+			pod, err := PreviewPod(ctx, test.WorkspaceType)
+			var act Expectation
+			if err != nil {
+				act.Err = true
+			} else {
+				terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+				act.Affinity = terms[0].MatchExpressions[0].Key
+				act.Label = pod.Labels["workspaceType"]
+				for _, c := range pod.Spec.Containers {
+					act.Containers = append(act.Containers, c.Name)
+				}
+				workspace := pod.Spec.Containers[0]
+				act.Env = workspace.Env
+				act.Resources = workspace.Resources.Requests
+				for _, v := range pod.Spec.Volumes {
+					act.Volumes = append(act.Volumes, v.Name)
+				}
+				for _, m := range workspace.VolumeMounts {
+					act.Mounts = append(act.Mounts, m.MountPath)
+				}
+			}
+
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("PreviewPod() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -14,6 +14,10 @@ import (
 )
 
 func role(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if common.UsePodSecurityAdmission(&ctx.Config) {
+		return nil, nil
+	}
+
 	return []runtime.Object{&rbacv1.Role{
 		TypeMeta: common.TypeMetaRole,
 		ObjectMeta: metav1.ObjectMeta{
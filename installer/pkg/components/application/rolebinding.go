@@ -13,6 +13,10 @@ import (
 )
 
 func rolebinding(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if common.UsePodSecurityAdmission(&ctx.Config) {
+		return nil, nil
+	}
+
 	labels := common.DefaultLabels(Component)
 
 	return []runtime.Object{
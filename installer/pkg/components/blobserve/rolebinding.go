@@ -17,40 +17,47 @@ import (
 func rolebinding(ctx *common.RenderContext) ([]runtime.Object, error) {
 	labels := common.DefaultLabels(Component)
 
-	return []runtime.Object{
-		&rbacv1.RoleBinding{
-			TypeMeta: common.TypeMetaRoleBinding,
+	objs := []runtime.Object{
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta: common.TypeMetaClusterRoleBinding,
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      Component,
-				Namespace: ctx.Namespace,
-				Labels:    labels,
+				Name:   fmt.Sprintf("%s-%s-kube-rbac-proxy", ctx.Namespace, Component),
+				Labels: labels,
 			},
 			RoleRef: rbacv1.RoleRef{
 				Kind:     "ClusterRole",
-				Name:     fmt.Sprintf("%s-ns-psp:restricted-root-user", ctx.Namespace),
+				Name:     fmt.Sprintf("%s-kube-rbac-proxy", ctx.Namespace),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 			Subjects: []rbacv1.Subject{{
-				Kind: "ServiceAccount",
-				Name: Component,
+				Kind:      "ServiceAccount",
+				Name:      Component,
+				Namespace: ctx.Namespace,
 			}},
 		},
-		&rbacv1.ClusterRoleBinding{
-			TypeMeta: common.TypeMetaClusterRoleBinding,
+	}
+
+	if common.UsePodSecurityAdmission(&ctx.Config) {
+		return objs, nil
+	}
+
+	return append(objs,
+		&rbacv1.RoleBinding{
+			TypeMeta: common.TypeMetaRoleBinding,
 			ObjectMeta: metav1.ObjectMeta{
-				Name:   fmt.Sprintf("%s-%s-kube-rbac-proxy", ctx.Namespace, Component),
-				Labels: labels,
+				Name:      Component,
+				Namespace: ctx.Namespace,
+				Labels:    labels,
 			},
 			RoleRef: rbacv1.RoleRef{
 				Kind:     "ClusterRole",
-				Name:     fmt.Sprintf("%s-kube-rbac-proxy", ctx.Namespace),
+				Name:     fmt.Sprintf("%s-ns-psp:restricted-root-user", ctx.Namespace),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 			Subjects: []rbacv1.Subject{{
-				Kind:      "ServiceAccount",
-				Name:      Component,
-				Namespace: ctx.Namespace,
+				Kind: "ServiceAccount",
+				Name: Component,
 			}},
 		},
-	}, nil
+	), nil
 }
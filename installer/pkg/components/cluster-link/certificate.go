@@ -0,0 +1,81 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package clusterlink
+
+import (
+	"net"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
+	v1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// certificate issues this side of the link from the shared CA. If the certificates
+// were generated by the cluster-link command, they already exist as a secret.
+func certificate(ctx *common.RenderContext) ([]runtime.Object, error) {
+	link := ctx.Config.ClusterLink
+	if link.CA == nil {
+		return nil, nil
+	}
+
+	spec := v1.CertificateSpec{
+		Duration:   common.InternalCertDuration,
+		SecretName: TLSSecret,
+		PrivateKey: &v1.CertificatePrivateKey{
+			Algorithm: v1.ECDSAKeyAlgorithm,
+			Size:      256,
+		},
+		IssuerRef: cmmeta.ObjectReference{
+			Name:  Issuer,
+			Kind:  "Issuer",
+			Group: "cert-manager.io",
+		},
+	}
+
+	if ctx.Config.Kind == config.InstallationMeta {
+		// The bridge serves the registration endpoint
+		host, _, err := net.SplitHostPort(link.Bridge)
+		if err != nil {
+			return nil, err
+		}
+		spec.CommonName = host
+		if ip := net.ParseIP(host); ip != nil {
+			spec.IPAddresses = []string{ip.String()}
+		} else {
+			spec.DNSNames = []string{host}
+		}
+		spec.Usages = []v1.KeyUsage{v1.UsageDigitalSignature, v1.UsageKeyEncipherment, v1.UsageServerAuth}
+	} else {
+		// The meta cluster identifies the workspace cluster by the common name
+		spec.CommonName = link.Name
+		spec.Usages = []v1.KeyUsage{v1.UsageDigitalSignature, v1.UsageKeyEncipherment, v1.UsageClientAuth}
+	}
+
+	return []runtime.Object{
+		&v1.Issuer{
+			TypeMeta: common.TypeMetaCertificateIssuer,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      Issuer,
+				Namespace: ctx.Namespace,
+				Labels:    common.DefaultLabels(Component),
+			},
+			Spec: v1.IssuerSpec{IssuerConfig: v1.IssuerConfig{
+				CA: &v1.CAIssuer{SecretName: link.CA.Name},
+			}},
+		},
+		&v1.Certificate{
+			TypeMeta: common.TypeMetaCertificate,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      Component,
+				Namespace: ctx.Namespace,
+				Labels:    common.DefaultLabels(Component),
+			},
+			Spec: spec,
+		},
+	}, nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package clusterlink
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type tlsConfig struct {
+	CA          string `json:"ca"`
+	Certificate string `json:"crt"`
+	Key         string `json:"key"`
+}
+
+type clusterConfig struct {
+	Name  string `json:"name"`
+	Score int32  `json:"score"`
+}
+
+// linkConfig is read by bp-manager-bridge on the meta cluster and by bp-manager
+// on the workspace cluster. The meta cluster only accepts the listed clusters.
+type linkConfig struct {
+	Role     string          `json:"role"`
+	Name     string          `json:"name,omitempty"`
+	Clusters []clusterConfig `json:"clusters,omitempty"`
+	Bridge   string          `json:"bridge"`
+	TLS      tlsConfig       `json:"tls"`
+}
+
+func configmap(ctx *common.RenderContext) ([]runtime.Object, error) {
+	link := ctx.Config.ClusterLink
+
+	cfg := linkConfig{
+		Role:   "workspace",
+		Name:   link.Name,
+		Bridge: link.Bridge,
+		TLS: tlsConfig{
+			CA:          filepath.Join(CertsMountPath, "ca.crt"),
+			Certificate: filepath.Join(CertsMountPath, "tls.crt"),
+			Key:         filepath.Join(CertsMountPath, "tls.key"),
+		},
+	}
+	if ctx.Config.Kind == config.InstallationMeta {
+		cfg.Role = "meta"
+		cfg.Name = ""
+		for _, c := range link.Clusters {
+			cfg.Clusters = append(cfg.Clusters, clusterConfig{Name: c.Name, Score: c.Score})
+		}
+	}
+
+	fc, err := json.MarshalIndent(cfg, "", " ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cluster link config: %w", err)
+	}
+
+	return []runtime.Object{
+		&corev1.ConfigMap{
+			TypeMeta: common.TypeMetaConfigmap,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      Component,
+				Namespace: ctx.Namespace,
+				Labels:    common.DefaultLabels(Component),
+			},
+			Data: map[string]string{
+				"config.json": string(fc),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,19 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package clusterlink
+
+const (
+	Component = "cluster-link"
+	Issuer    = "cluster-link-issuer"
+	// TLSSecret holds the certificate issued from the shared CA
+	TLSSecret = "cluster-link-tls"
+	// CertsMountPath is where the components mount the TLS secret
+	CertsMountPath = "/certs/cluster-link"
+	// ConfigMountPath is where the components mount the config.json of the link
+	ConfigMountPath = "/config/cluster-link"
+
+	certsVolume  = "cluster-link-certs"
+	configVolume = "cluster-link-config"
+)
@@ -0,0 +1,185 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package clusterlink
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// Credentials are the contents of the TLS secret of one side of the link
+type Credentials map[string][]byte
+
+type keyPair struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// GenerateCA creates the CA all certificates of the links are issued from. It's a
+// secret with the tls.crt and tls.key, like the clusterLink.ca secret.
+func GenerateCA(validity time.Duration) (Credentials, error) {
+	notBefore := time.Now()
+	ca, err := newKeyPair(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s-ca", Component)},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(validity),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return ca.credentials(nil)
+}
+
+// GenerateMetaCredentials issues the certificate of the meta cluster, which serves the bridge endpoint
+func GenerateMetaCredentials(ca Credentials, bridge string, validity time.Duration) (Credentials, error) {
+	host, _, err := net.SplitHostPort(bridge)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bridge endpoint %s: %w", bridge, err)
+	}
+
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: host},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+
+	return issue(ca, template, validity)
+}
+
+// GenerateWorkspaceCredentials issues the certificate the workspace cluster registers with as name
+func GenerateWorkspaceCredentials(ca Credentials, name string, validity time.Duration) (Credentials, error) {
+	return issue(ca, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, validity)
+}
+
+func issue(ca Credentials, template *x509.Certificate, validity time.Duration) (Credentials, error) {
+	parent, err := parseKeyPair(ca)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CA: %w", err)
+	}
+
+	template.NotBefore = time.Now()
+	template.NotAfter = template.NotBefore.Add(validity)
+	if template.NotAfter.After(parent.cert.NotAfter) {
+		// A certificate is only valid as long as its CA
+		template.NotAfter = parent.cert.NotAfter
+	}
+
+	kp, err := newKeyPair(template, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	return kp.credentials(parent)
+}
+
+// credentials returns the secret data of the key pair, with the ca.crt if it's issued by a CA
+func (kp *keyPair) credentials(ca *keyPair) (Credentials, error) {
+	key, err := encodeKey(kp.key)
+	if err != nil {
+		return nil, err
+	}
+
+	res := Credentials{
+		"tls.crt": kp.pem,
+		"tls.key": key,
+	}
+	if ca != nil {
+		res["ca.crt"] = ca.pem
+	}
+	return res, nil
+}
+
+func parseKeyPair(c Credentials) (*keyPair, error) {
+	certBlock, _ := pem.Decode(c["tls.crt"])
+	if certBlock == nil {
+		return nil, fmt.Errorf("tls.crt is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if !cert.IsCA {
+		return nil, fmt.Errorf("tls.crt is not a CA certificate")
+	}
+
+	keyBlock, _ := pem.Decode(c["tls.key"])
+	if keyBlock == nil {
+		return nil, fmt.Errorf("tls.key is not PEM encoded")
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keyPair{
+		cert: cert,
+		key:  key,
+		pem:  c["tls.crt"],
+	}, nil
+}
+
+// newKeyPair signs the template with the parent, or self-signs it if there is no parent
+func newKeyPair(template *x509.Certificate, parent *keyPair) (*keyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template.SerialNumber = serial
+
+	signerCert, signerKey := template, key
+	if parent != nil {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keyPair{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}, nil
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package clusterlink
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestGenerateCredentials(t *testing.T) {
+	ca, err := GenerateCA(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := parseKeyPair(ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert.cert)
+
+	tests := []struct {
+		Name   string
+		Bridge string
+		Usage  x509.ExtKeyUsage
+	}{
+		{Name: "bridge.example.com", Bridge: "bridge.example.com:8443", Usage: x509.ExtKeyUsageServerAuth},
+		{Name: "10.0.0.1", Bridge: "10.0.0.1:8443", Usage: x509.ExtKeyUsageServerAuth},
+		{Name: "eu01", Usage: x509.ExtKeyUsageClientAuth},
+		{Name: "us01", Usage: x509.ExtKeyUsageClientAuth},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var (
+				creds Credentials
+				err   error
+			)
+			if test.Bridge != "" {
+				creds, err = GenerateMetaCredentials(ca, test.Bridge, 2*time.Hour)
+			} else {
+				creds, err = GenerateWorkspaceCredentials(ca, test.Name, 2*time.Hour)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			block, _ := pem.Decode(creds["tls.crt"])
+			if block == nil {
+				t.Fatal("tls.crt is not PEM encoded")
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(creds["ca.crt"]) != string(ca["tls.crt"]) {
+				t.Errorf("ca.crt is not the CA")
+			}
+			if cert.Subject.CommonName != test.Name {
+				t.Errorf("unexpected common name: got %s, expected %s", cert.Subject.CommonName, test.Name)
+			}
+
+			opts := x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{test.Usage}}
+			if test.Bridge != "" {
+				opts.DNSName = test.Name
+				if ip := net.ParseIP(test.Name); ip != nil && len(cert.DNSNames) > 0 {
+					t.Errorf("IP endpoint in the DNS names: %v", cert.DNSNames)
+				}
+			}
+			_, err = cert.Verify(opts)
+			if err != nil {
+				t.Errorf("certificate does not verify: %v", err)
+			}
+			if cert.NotAfter.After(caCert.cert.NotAfter) {
+				t.Errorf("certificate expires after the CA: %s > %s", cert.NotAfter, caCert.cert.NotAfter)
+			}
+		})
+	}
+}
+
+func TestGenerateCredentialsInvalidCA(t *testing.T) {
+	ca, err := GenerateCA(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workspace, err := GenerateWorkspaceCredentials(ca, "eu01", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the certificate of a workspace cluster cannot issue others
+	_, err = GenerateWorkspaceCredentials(workspace, "us01", time.Hour)
+	if err == nil {
+		t.Error("expected an error for a CA which is not a CA")
+	}
+	_, err = GenerateWorkspaceCredentials(Credentials{}, "us01", time.Hour)
+	if err == nil {
+		t.Error("expected an error for an empty CA")
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package clusterlink
+
+import (
+	"fmt"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// enabled returns if the installation is one side of a cluster link
+func enabled(cfg *config.Config) bool {
+	if cfg.ClusterLink == nil {
+		return false
+	}
+	return cfg.Kind == config.InstallationMeta || cfg.Kind == config.InstallationWorkspace
+}
+
+// SecretName is the secret with the ca.crt, tls.crt and tls.key this cluster uses for the link
+func SecretName(cfg *config.Config) string {
+	if cfg.ClusterLink.Certificates != nil {
+		return cfg.ClusterLink.Certificates.Name
+	}
+	return TLSSecret
+}
+
+var Objects = common.CompositeRenderFunc(func(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !enabled(&ctx.Config) {
+		return nil, nil
+	}
+
+	return common.CompositeRenderFunc(
+		certificate,
+		configmap,
+	)(ctx)
+})
+
+// WithMounts mounts the TLS secret and the config of the link into the container named
+// after the component in its deployment, ie bp-manager and bp-manager-bridge
+func WithMounts(component string, f common.RenderFunc) common.RenderFunc {
+	return func(ctx *common.RenderContext) ([]runtime.Object, error) {
+		objs, err := f(ctx)
+		if err != nil || !enabled(&ctx.Config) {
+			return objs, err
+		}
+
+		var mounted bool
+		for _, obj := range objs {
+			deployment, ok := obj.(*appsv1.Deployment)
+			if !ok || deployment.Name != component {
+				continue
+			}
+			addMounts(&ctx.Config, &deployment.Spec.Template.Spec, component)
+			mounted = true
+		}
+		if !mounted {
+			return nil, fmt.Errorf("no %s deployment to mount the cluster link into", component)
+		}
+
+		return objs, nil
+	}
+}
+
+func addMounts(cfg *config.Config, pod *corev1.PodSpec, container string) {
+	pod.Volumes = append(pod.Volumes,
+		corev1.Volume{
+			Name: certsVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: SecretName(cfg)},
+			},
+		},
+		corev1.Volume{
+			Name: configVolume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: Component},
+				},
+			},
+		},
+	)
+
+	for i := range pod.Containers {
+		if pod.Containers[i].Name != container {
+			continue
+		}
+		pod.Containers[i].VolumeMounts = append(pod.Containers[i].VolumeMounts,
+			corev1.VolumeMount{Name: certsVolume, MountPath: CertsMountPath, ReadOnly: true},
+			corev1.VolumeMount{Name: configVolume, MountPath: ConfigMountPath, ReadOnly: true},
+		)
+	}
+}
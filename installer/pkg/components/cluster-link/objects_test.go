@@ -0,0 +1,103 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package clusterlink
+
+import (
+	"testing"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestWithMounts(t *testing.T) {
+	deployment := func(ctx *common.RenderContext) ([]runtime.Object, error) {
+		return []runtime.Object{&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "bp-manager"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "bp-manager"}, {Name: "kube-rbac-proxy"}},
+			}}},
+		}}, nil
+	}
+
+	type Expectation struct {
+		Secret string
+		Mounts []string
+		Error  bool
+	}
+	tests := []struct {
+		Name        string
+		Kind        config.InstallationKind
+		ClusterLink *config.ClusterLink
+		Component   string
+		Expectation Expectation
+	}{
+		{
+			Name:      "no link",
+			Kind:      config.InstallationWorkspace,
+			Component: "bp-manager",
+		},
+		{
+			Name:        "generated certificates",
+			Kind:        config.InstallationWorkspace,
+			ClusterLink: &config.ClusterLink{Name: "eu01", Certificates: &config.ObjectRef{Kind: config.ObjectRefSecret, Name: "certs"}},
+			Component:   "bp-manager",
+			Expectation: Expectation{
+				Secret: "certs",
+				Mounts: []string{CertsMountPath, ConfigMountPath},
+			},
+		},
+		{
+			Name:        "issued certificates",
+			Kind:        config.InstallationWorkspace,
+			ClusterLink: &config.ClusterLink{Name: "eu01", CA: &config.ObjectRef{Kind: config.ObjectRefSecret, Name: "ca"}},
+			Component:   "bp-manager",
+			Expectation: Expectation{
+				Secret: TLSSecret,
+				Mounts: []string{CertsMountPath, ConfigMountPath},
+			},
+		},
+		{
+			Name:        "missing deployment",
+			Kind:        config.InstallationMeta,
+			ClusterLink: &config.ClusterLink{Clusters: []config.LinkedCluster{{Name: "eu01"}}, CA: &config.ObjectRef{Kind: config.ObjectRefSecret, Name: "ca"}},
+			Component:   "bp-manager-bridge",
+			Expectation: Expectation{Error: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx := &common.RenderContext{Config: config.Config{Kind: test.Kind, ClusterLink: test.ClusterLink}}
+
+			var act Expectation
+			objs, err := WithMounts(test.Component, deployment)(ctx)
+			if err != nil {
+				act.Error = true
+			} else {
+				pod := objs[0].(*appsv1.Deployment).Spec.Template.Spec
+				for _, v := range pod.Volumes {
+					if v.Secret != nil {
+						act.Secret = v.Secret.SecretName
+					}
+				}
+				for _, m := range pod.Containers[0].VolumeMounts {
+					act.Mounts = append(act.Mounts, m.MountPath)
+				}
+				if len(pod.Containers[1].VolumeMounts) > 0 {
+					t.Errorf("mounted into the sidecar: %v", pod.Containers[1].VolumeMounts)
+				}
+			}
+
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("WithMounts() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
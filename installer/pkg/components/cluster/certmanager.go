@@ -8,6 +8,8 @@ import (
 	"fmt"
 
 	"github.com/bhojpur/platform/installer/pkg/common"
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
+	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
 	v1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -69,3 +71,87 @@ func certmanager(ctx *common.RenderContext) ([]runtime.Object, error) {
 		},
 	}, nil
 }
+
+// httpsCertificate renders the public certificate for the domain if the config
+// points to an issuer - otherwise the certificate secret is expected to exist already
+func httpsCertificate(ctx *common.RenderContext) ([]runtime.Object, error) {
+	issuerCfg := ctx.Config.CertificateIssuer
+	if issuerCfg == nil {
+		return nil, nil
+	}
+
+	var (
+		res       []runtime.Object
+		issuerRef cmmeta.ObjectReference
+	)
+	switch issuerCfg.Kind {
+	case config.IssuerKindIssuer, config.IssuerKindClusterIssuer:
+		issuerRef = cmmeta.ObjectReference{
+			Name:  issuerCfg.Name,
+			Kind:  string(issuerCfg.Kind),
+			Group: "cert-manager.io",
+		}
+	case config.IssuerKindACME:
+		if issuerCfg.ACME == nil {
+			return nil, fmt.Errorf("certificate issuer of kind %s requires acme configuration", issuerCfg.Kind)
+		}
+
+		// Wildcard names can only be solved with DNS01 - if HTTP01 is given, it's used for the bare domain
+		solvers := []cmacme.ACMEChallengeSolver{{
+			DNS01: issuerCfg.ACME.DNS01,
+		}}
+		if issuerCfg.ACME.HTTP01 != nil {
+			solvers = append(solvers, cmacme.ACMEChallengeSolver{
+				Selector: &cmacme.CertificateDNSNameSelector{
+					DNSNames: []string{ctx.Config.Domain},
+				},
+				HTTP01: issuerCfg.ACME.HTTP01,
+			})
+		}
+
+		res = append(res, &v1.Issuer{
+			TypeMeta: common.TypeMetaCertificateIssuer,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ACMEIssuer,
+				Namespace: ctx.Namespace,
+				Labels:    common.DefaultLabels(Component),
+			},
+			Spec: v1.IssuerSpec{IssuerConfig: v1.IssuerConfig{
+				ACME: &cmacme.ACMEIssuer{
+					Email:  issuerCfg.ACME.Email,
+					Server: issuerCfg.ACME.Server,
+					PrivateKey: cmmeta.SecretKeySelector{
+						LocalObjectReference: cmmeta.LocalObjectReference{Name: ACMEAccountKeySecret},
+					},
+					Solvers: solvers,
+				},
+			}},
+		})
+		issuerRef = cmmeta.ObjectReference{
+			Name:  ACMEIssuer,
+			Kind:  "Issuer",
+			Group: "cert-manager.io",
+		}
+	default:
+		return nil, fmt.Errorf("unsupported certificate issuer kind: %s", issuerCfg.Kind)
+	}
+
+	return append(res, &v1.Certificate{
+		TypeMeta: common.TypeMetaCertificate,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ctx.Config.Certificate.Name,
+			Namespace: ctx.Namespace,
+			Labels:    common.DefaultLabels(Component),
+		},
+		Spec: v1.CertificateSpec{
+			SecretName: ctx.Config.Certificate.Name,
+			// the names a certificate must have, see "TLS certificates" in the README
+			DNSNames: []string{
+				ctx.Config.Domain,
+				fmt.Sprintf("*.%s", ctx.Config.Domain),
+				fmt.Sprintf("*.ws.%s", ctx.Config.Domain),
+			},
+			IssuerRef: issuerRef,
+		},
+	}), nil
+}
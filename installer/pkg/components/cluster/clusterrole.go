@@ -13,7 +13,7 @@ import (
 )
 
 func clusterrole(ctx *common.RenderContext) ([]runtime.Object, error) {
-	return []runtime.Object{
+	objs := []runtime.Object{
 		&v1.ClusterRole{
 			TypeMeta: common.TypeMetaClusterRole,
 			ObjectMeta: metav1.ObjectMeta{
@@ -29,6 +29,14 @@ func clusterrole(ctx *common.RenderContext) ([]runtime.Object, error) {
 				Verbs:     []string{"create"},
 			}},
 		},
+	}
+
+	// The roles only grant the use of the PodSecurityPolicies
+	if common.UsePodSecurityAdmission(&ctx.Config) {
+		return objs, nil
+	}
+
+	return append(objs,
 		&v1.ClusterRole{
 			TypeMeta: common.TypeMetaClusterRole,
 			ObjectMeta: metav1.ObjectMeta{
@@ -65,5 +73,5 @@ func clusterrole(ctx *common.RenderContext) ([]runtime.Object, error) {
 				ResourceNames: []string{fmt.Sprintf("%s-ns-unprivileged", ctx.Namespace)},
 			}},
 		},
-	}, nil
+	), nil
 }
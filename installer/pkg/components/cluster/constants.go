@@ -7,4 +7,7 @@ package cluster
 const (
 	Component       = "cluster"
 	NobodyComponent = "nobody"
+
+	ACMEIssuer           = "bhojpur-acme-issuer"
+	ACMEAccountKeySecret = "bhojpur-acme-account-key"
 )
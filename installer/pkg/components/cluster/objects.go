@@ -11,7 +11,9 @@ import "github.com/bhojpur/platform/installer/pkg/common"
 
 var Objects = common.CompositeRenderFunc(
 	certmanager,
+	httpsCertificate,
 	clusterrole,
+	podsecurityadmission,
 	podsecuritypolicies,
 	resourcequota,
 	rolebinding,
@@ -0,0 +1,259 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	agentsmith "github.com/bhojpur/platform/installer/pkg/components/agent-smith"
+	wsdaemon "github.com/bhojpur/platform/installer/pkg/components/bp-daemon"
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// podSecurityLevel has to be privileged as the daemon pods share the namespace with everything else
+	podSecurityLevel      = "privileged"
+	podSecurityAuditLevel = "baseline"
+
+	gatekeeperConstraintKind = "BhojpurPrivilegedPods"
+)
+
+// privilegedServiceAccounts are the only service accounts allowed to run privileged
+// pods or use the host namespaces. This mirrors the PodSecurityPolicy role bindings.
+func privilegedServiceAccounts() []string {
+	return []string{
+		agentsmith.Component,
+		wsdaemon.Component,
+	}
+}
+
+func podsecurityadmission(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !common.UsePodSecurityAdmission(&ctx.Config) {
+		return nil, nil
+	}
+
+	res := []runtime.Object{
+		&corev1.Namespace{
+			TypeMeta: common.TypeMetaNamespace,
+			ObjectMeta: metav1.ObjectMeta{
+				Name: ctx.Namespace,
+				Labels: map[string]string{
+					"pod-security.kubernetes.io/enforce":         podSecurityLevel,
+					"pod-security.kubernetes.io/enforce-version": "latest",
+					"pod-security.kubernetes.io/audit":           podSecurityAuditLevel,
+					"pod-security.kubernetes.io/audit-version":   "latest",
+				},
+			},
+		},
+	}
+
+	switch ctx.Config.PodSecurity.PolicyEngine {
+	case "":
+	case config.PolicyEngineKyverno:
+		res = append(res, kyvernoPolicy(ctx))
+	case config.PolicyEngineGatekeeper:
+		res = append(res, gatekeeperPolicy(ctx)...)
+	default:
+		return nil, fmt.Errorf("unsupported policy engine: %s", ctx.Config.PodSecurity.PolicyEngine)
+	}
+
+	return res, nil
+}
+
+func kyvernoPolicy(ctx *common.RenderContext) runtime.Object {
+	var serviceAccounts []interface{}
+	for _, sa := range privilegedServiceAccounts() {
+		serviceAccounts = append(serviceAccounts, sa)
+	}
+
+	rule := func(name, message string, pattern map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"name": name,
+			"match": map[string]interface{}{
+				"resources": map[string]interface{}{
+					"kinds": []interface{}{"Pod"},
+				},
+			},
+			"preconditions": map[string]interface{}{
+				"all": []interface{}{
+					map[string]interface{}{
+						"key":      "{{ request.object.spec.serviceAccountName || 'default' }}",
+						"operator": "NotIn",
+						"value":    serviceAccounts,
+					},
+				},
+			},
+			"validate": map[string]interface{}{
+				"message": message,
+				"pattern": pattern,
+			},
+		}
+	}
+
+	unprivileged := []interface{}{
+		map[string]interface{}{
+			"=(securityContext)": map[string]interface{}{
+				"=(privileged)": false,
+			},
+		},
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kyverno.io/v1",
+		"kind":       "Policy",
+		"metadata": map[string]interface{}{
+			"name":      fmt.Sprintf("%s-privileged-pods", ctx.Namespace),
+			"namespace": ctx.Namespace,
+			"labels":    stringMap(common.DefaultLabels(Component)),
+		},
+		"spec": map[string]interface{}{
+			"validationFailureAction": "enforce",
+			"background":              true,
+			"rules": []interface{}{
+				rule("privileged-containers", "privileged containers are only allowed for the Bhojpur.NET Platform daemon pods", map[string]interface{}{
+					"spec": map[string]interface{}{
+						"=(initContainers)": unprivileged,
+						"containers":        unprivileged,
+					},
+				}),
+				rule("host-namespaces", "host namespaces are only allowed for the Bhojpur.NET Platform daemon pods", map[string]interface{}{
+					"spec": map[string]interface{}{
+						"=(hostPID)":     false,
+						"=(hostIPC)":     false,
+						"=(hostNetwork)": false,
+					},
+				}),
+			},
+		},
+	}}
+}
+
+const gatekeeperRego = `package %s
+
+violation[{"msg": msg}] {
+	not allowed_service_account
+	c := input_containers[_]
+	c.securityContext.privileged
+	msg := sprintf("privileged container %%v is not allowed for service account %%v", [c.name, service_account])
+}
+
+violation[{"msg": msg}] {
+	not allowed_service_account
+	host_namespace
+	msg := sprintf("host namespaces are not allowed for service account %%v", [service_account])
+}
+
+service_account = sa {
+	sa := input.review.object.spec.serviceAccountName
+} else = "default"
+
+allowed_service_account {
+	input.parameters.allowedServiceAccounts[_] == service_account
+}
+
+host_namespace {
+	input.review.object.spec.hostPID
+}
+
+host_namespace {
+	input.review.object.spec.hostIPC
+}
+
+host_namespace {
+	input.review.object.spec.hostNetwork
+}
+
+input_containers[c] {
+	c := input.review.object.spec.containers[_]
+}
+
+input_containers[c] {
+	c := input.review.object.spec.initContainers[_]
+}
+`
+
+func gatekeeperPolicy(ctx *common.RenderContext) []runtime.Object {
+	var serviceAccounts []interface{}
+	for _, sa := range privilegedServiceAccounts() {
+		serviceAccounts = append(serviceAccounts, sa)
+	}
+
+	templateName := strings.ToLower(gatekeeperConstraintKind)
+
+	return []runtime.Object{
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "templates.gatekeeper.sh/v1beta1",
+			"kind":       "ConstraintTemplate",
+			"metadata": map[string]interface{}{
+				"name":   templateName,
+				"labels": stringMap(common.DefaultLabels(Component)),
+			},
+			"spec": map[string]interface{}{
+				"crd": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"names": map[string]interface{}{
+							"kind": gatekeeperConstraintKind,
+						},
+						"validation": map[string]interface{}{
+							"openAPIV3Schema": map[string]interface{}{
+								"type": "object",
+								"properties": map[string]interface{}{
+									"allowedServiceAccounts": map[string]interface{}{
+										"type":  "array",
+										"items": map[string]interface{}{"type": "string"},
+									},
+								},
+							},
+						},
+					},
+				},
+				"targets": []interface{}{
+					map[string]interface{}{
+						"target": "admission.k8s.gatekeeper.sh",
+						"rego":   fmt.Sprintf(gatekeeperRego, templateName),
+					},
+				},
+			},
+		}},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+			"kind":       gatekeeperConstraintKind,
+			"metadata": map[string]interface{}{
+				"name":   fmt.Sprintf("%s-privileged-pods", ctx.Namespace),
+				"labels": stringMap(common.DefaultLabels(Component)),
+			},
+			"spec": map[string]interface{}{
+				"match": map[string]interface{}{
+					"kinds": []interface{}{
+						map[string]interface{}{
+							"apiGroups": []interface{}{""},
+							"kinds":     []interface{}{"Pod"},
+						},
+					},
+					"namespaces": []interface{}{ctx.Namespace},
+				},
+				"parameters": map[string]interface{}{
+					"allowedServiceAccounts": serviceAccounts,
+				},
+			},
+		}},
+	}
+}
+
+// stringMap converts labels into a form that can be held by an unstructured object
+func stringMap(in map[string]string) map[string]interface{} {
+	res := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		res[k] = v
+	}
+	return res
+}
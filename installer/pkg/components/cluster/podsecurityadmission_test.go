@@ -0,0 +1,70 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestKyvernoPolicyPattern(t *testing.T) {
+	ctx := &common.RenderContext{
+		Namespace: "default",
+		Config: config.Config{
+			PodSecurity: config.PodSecurity{
+				Mode:         config.PodSecurityModeAdmission,
+				PolicyEngine: config.PolicyEngineKyverno,
+			},
+		},
+	}
+
+	objs, err := podsecurityadmission(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 {
+		t.Fatalf("expected a namespace and a policy, got %d objects", len(objs))
+	}
+
+	rules, _, err := unstructured.NestedSlice(objs[1].(*unstructured.Unstructured).Object, "spec", "rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	act := make(map[string]interface{})
+	for _, r := range rules {
+		rule := r.(map[string]interface{})
+		act[rule["name"].(string)] = rule["validate"].(map[string]interface{})["pattern"]
+	}
+
+	unprivileged := []interface{}{
+		map[string]interface{}{
+			"=(securityContext)": map[string]interface{}{
+				"=(privileged)": false,
+			},
+		},
+	}
+	expectation := map[string]interface{}{
+		"privileged-containers": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"=(initContainers)": unprivileged,
+				"containers":        unprivileged,
+			},
+		},
+		"host-namespaces": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"=(hostPID)":     false,
+				"=(hostIPC)":     false,
+				"=(hostNetwork)": false,
+			},
+		},
+	}
+	if diff := cmp.Diff(expectation, act); diff != "" {
+		t.Errorf("kyvernoPolicy() pattern mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -13,6 +13,10 @@ import (
 )
 
 func rolebinding(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if common.UsePodSecurityAdmission(&ctx.Config) {
+		return nil, nil
+	}
+
 	return []runtime.Object{&v1.RoleBinding{
 		TypeMeta: common.TypeMetaRoleBinding,
 		ObjectMeta: metav1.ObjectMeta{
@@ -16,6 +16,7 @@ import (
 	wsproxy "github.com/bhojpur/platform/installer/pkg/components/bp-proxy"
 	wsscheduler "github.com/bhojpur/platform/installer/pkg/components/bp-scheduler"
 	"github.com/bhojpur/platform/installer/pkg/components/cluster"
+	clusterlink "github.com/bhojpur/platform/installer/pkg/components/cluster-link"
 	contentservice "github.com/bhojpur/platform/installer/pkg/components/content-service"
 	"github.com/bhojpur/platform/installer/pkg/components/dashboard"
 	"github.com/bhojpur/platform/installer/pkg/components/database"
@@ -30,33 +31,34 @@ import (
 	"github.com/bhojpur/platform/installer/pkg/components/rabbitmq"
 	registryfacade "github.com/bhojpur/platform/installer/pkg/components/registry-facade"
 	"github.com/bhojpur/platform/installer/pkg/components/server"
+	"github.com/bhojpur/platform/installer/pkg/helm"
 )
 
 var MetaObjects = common.CompositeRenderFunc(
-	contentservice.Objects,
-	proxy.Objects,
-	dashboard.Objects,
-	database.Objects,
-	ide_proxy.Objects,
-	imagebuildermk3.Objects,
-	migrations.Objects,
-	minio.Objects,
-	openvsxproxy.Objects,
-	rabbitmq.Objects,
-	server.Objects,
-	wsmanagerbridge.Objects,
+	common.ComponentRenderFunc(contentservice.Component, contentservice.Objects),
+	common.ComponentRenderFunc(proxy.Component, proxy.Objects),
+	common.ComponentRenderFunc(dashboard.Component, dashboard.Objects),
+	common.ComponentRenderFunc(database.Component, database.Objects),
+	common.ComponentRenderFunc(ide_proxy.Component, ide_proxy.Objects),
+	common.ComponentRenderFunc(imagebuildermk3.Component, imagebuildermk3.Objects),
+	common.ComponentRenderFunc(migrations.Component, migrations.Objects),
+	common.ComponentRenderFunc(minio.Component, minio.Objects),
+	common.ComponentRenderFunc(openvsxproxy.Component, openvsxproxy.Objects),
+	common.ComponentRenderFunc(rabbitmq.Component, rabbitmq.Objects),
+	common.ComponentRenderFunc(server.Component, common.WithAuthProviders(server.Objects)),
+	common.ComponentRenderFunc(wsmanagerbridge.Component, clusterlink.WithMounts(wsmanagerbridge.Component, wsmanagerbridge.Objects)),
 )
 
 var ApplicationObjects = common.CompositeRenderFunc(
-	agentsmith.Objects,
-	blobserve.Objects,
+	common.ComponentRenderFunc(agentsmith.Component, agentsmith.Objects),
+	common.ComponentRenderFunc(blobserve.Component, blobserve.Objects),
 	bhojpur.Objects,
-	registryfacade.Objects,
+	common.ComponentRenderFunc(registryfacade.Component, registryfacade.Objects),
 	application.Objects,
-	wsdaemon.Objects,
-	wsmanager.Objects,
-	wsproxy.Objects,
-	wsscheduler.Objects,
+	common.ComponentRenderFunc(wsdaemon.Component, wsdaemon.Objects),
+	common.ComponentRenderFunc(wsmanager.Component, clusterlink.WithMounts(wsmanager.Component, wsmanager.Objects)),
+	common.ComponentRenderFunc(wsproxy.Component, wsproxy.Objects),
+	common.ComponentRenderFunc(wsscheduler.Component, wsscheduler.Objects),
 )
 
 var FullObjects = common.CompositeRenderFunc(
@@ -65,10 +67,10 @@ var FullObjects = common.CompositeRenderFunc(
 )
 
 var MetaHelmDependencies = common.CompositeHelmFunc(
-	database.Helm,
-	jaegeroperator.Helm,
-	minio.Helm,
-	rabbitmq.Helm,
+	common.ComponentHelmFunc(database.Component, database.Helm),
+	common.ComponentHelmFunc(jaegeroperator.Component, jaegeroperator.Helm),
+	common.ComponentHelmFunc(minio.Component, minio.Helm),
+	common.ComponentHelmFunc(rabbitmq.Component, rabbitmq.Helm),
 )
 
 var ApplicationHelmDependencies = common.CompositeHelmFunc()
@@ -81,10 +83,12 @@ var FullHelmDependencies = common.CompositeHelmFunc(
 // Anything in the "common" section are included in all installation types
 
 var CommonObjects = common.CompositeRenderFunc(
-	dockerregistry.Objects,
+	clusterlink.Objects,
+	common.ComponentRenderFunc(dockerregistry.Component, dockerregistry.Objects),
 	cluster.Objects,
 )
 
 var CommonHelmDependencies = common.CompositeHelmFunc(
-	dockerregistry.Helm,
+	common.ComponentHelmFunc(dockerregistry.Component, dockerregistry.Helm),
+	helm.ExternalCharts,
 )
@@ -0,0 +1,35 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package backup
+
+const (
+	Component        = "db-backup"
+	RestoreComponent = "db-restore"
+
+	// Prefix is the location of the backups within the bucket
+	Prefix          = "mysql"
+	defaultKeepLast = 7
+
+	MySQLImage       = "library/mysql"
+	MySQLTag         = "5.7"
+	MinioClientImage = "minio/mc"
+	MinioClientTag   = "RELEASE.2021-11-16T20-37-36Z"
+	GCloudImage      = "google/cloud-sdk"
+	GCloudTag        = "365.0.0-slim"
+
+	// Keys of the in-cluster storage secret, as created by the MinIO chart
+	minioAccessKey = "access-key"
+	minioSecretKey = "secret-key"
+
+	dumpVolume    = "dump"
+	dumpMountPath = "/dump"
+	dumpFile      = dumpMountPath + "/backup.sql.gz"
+	backupSuffix  = ".sql.gz"
+	storageName   = "storage"
+	databaseName  = "mysql"
+)
+
+// Databases are the databases included in a backup
+var Databases = []string{"bhojpur", "bhojpur-sessions"}
@@ -0,0 +1,204 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package backup
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bhojpur/platform/installer/pkg/cluster"
+	"github.com/bhojpur/platform/installer/pkg/common"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+func enabled(ctx *common.RenderContext) bool {
+	return pointer.BoolDeref(ctx.Config.Database.InCluster, false) && ctx.Config.Database.Backup != nil
+}
+
+// mysqlClient authenticates using the database secret
+func mysqlClient(cmd string) string {
+	return fmt.Sprintf(`%s --host="$DB_HOST" --port="$DB_PORT" --user="$DB_USERNAME"`, cmd)
+}
+
+// podSpec runs the first container to completion before the second one starts,
+// so that the dump is transferred between the database and the object storage
+func podSpec(ctx *common.RenderContext, first, second corev1.Container) (*corev1.PodSpec, error) {
+	dumpMount := corev1.VolumeMount{
+		Name:      dumpVolume,
+		MountPath: dumpMountPath,
+	}
+	first.VolumeMounts = append(first.VolumeMounts, dumpMount)
+	second.VolumeMounts = append(second.VolumeMounts, dumpMount)
+
+	spec := &corev1.PodSpec{
+		Affinity:                     common.Affinity(cluster.AffinityLabelMeta),
+		ServiceAccountName:           Component,
+		AutomountServiceAccountToken: pointer.Bool(false),
+		EnableServiceLinks:           pointer.Bool(false),
+		RestartPolicy:                corev1.RestartPolicyOnFailure,
+		Volumes:                      []corev1.Volume{*common.NewEmptyDirVolume(dumpVolume)},
+		Containers:                   []corev1.Container{first, second},
+	}
+
+	// common.AddStorageMounts only considers the regular containers
+	err := common.AddStorageMounts(ctx, spec, storageName)
+	if err != nil {
+		return nil, err
+	}
+	spec.InitContainers, spec.Containers = spec.Containers[:1], spec.Containers[1:]
+
+	return spec, nil
+}
+
+func databaseContainer(ctx *common.RenderContext, script string) corev1.Container {
+	return corev1.Container{
+		Name:            databaseName,
+		Image:           common.ImageName(common.ThirdPartyContainerRepo(ctx.Config.Repository, common.DockerRegistryURL), MySQLImage, MySQLTag),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"/bin/bash", "-c"},
+		Args:            []string{"set -euo pipefail\n" + script},
+		Env: common.MergeEnv(
+			common.DatabaseEnv(&ctx.Config),
+			[]corev1.EnvVar{{
+				// The MySQL clients read the password from the environment
+				Name:  "MYSQL_PWD",
+				Value: "$(DB_PASSWORD)",
+			}},
+		),
+	}
+}
+
+func storageContainer(storage *storageClient, script string) corev1.Container {
+	return corev1.Container{
+		Name:            storageName,
+		Image:           storage.Image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"/bin/sh", "-c"},
+		Args:            []string{fmt.Sprintf("set -eu\n%s\n%s", storage.Setup, script)},
+		Env:             storage.Env,
+	}
+}
+
+// retentionScript removes all but the newest keepLast backups. Backups are named by their
+// UTC timestamp, so the reverse lexical order is newest first. The client images differ,
+// hence only POSIX options of sort and tail are used.
+func retentionScript(storage *storageClient, keepLast int32) string {
+	return fmt.Sprintf(`%s | sort -r | tail -n +%d | while read -r obj; do %s "$obj"; done`, storage.List, keepLast+1, storage.Remove)
+}
+
+func cronjob(ctx *common.RenderContext) ([]runtime.Object, error) {
+	if !enabled(ctx) {
+		return nil, nil
+	}
+
+	storage, err := newStorageClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keepLast := ctx.Config.Database.Backup.KeepLast
+	if keepLast == 0 {
+		keepLast = defaultKeepLast
+	}
+
+	dump := databaseContainer(ctx, fmt.Sprintf(
+		"%s --single-transaction --routines --triggers --databases %s | gzip > %s",
+		mysqlClient("mysqldump"),
+		strings.Join(Databases, " "),
+		dumpFile,
+	))
+
+	upload := storageContainer(storage, fmt.Sprintf(`name="%s-$(date -u +%%Y%%m%%d%%H%%M%%S)%s"
+%s %s "%s/$name"
+%s`,
+		common.AppName, backupSuffix,
+		storage.Copy, dumpFile, storage.Location,
+		retentionScript(storage, keepLast),
+	))
+
+	spec, err := podSpec(ctx, dump, upload)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := common.DefaultLabels(Component)
+	return []runtime.Object{&batchv1.CronJob{
+		TypeMeta: common.TypeMetaBatchCronJob,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Component,
+			Namespace: ctx.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   ctx.Config.Database.Backup.Schedule,
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: pointer.Int32(3),
+			FailedJobsHistoryLimit:     pointer.Int32(1),
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: pointer.Int32(2),
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec:       *spec,
+					},
+				},
+			},
+		},
+	}}, nil
+}
+
+// RestoreJob renders a one-shot job which restores the in-cluster database from
+// the named backup. The backup name is the object name without the prefix, eg
+// bhojpur-20211116203736.sql.gz. The job name is suffixed with the UTC time it
+// was rendered at, so that previous restore jobs don't have to be deleted first.
+func RestoreJob(ctx *common.RenderContext, name string, now time.Time) ([]runtime.Object, error) {
+	if !enabled(ctx) {
+		return nil, fmt.Errorf("database backups are not configured for the in-cluster database")
+	}
+	if name == "" || strings.ContainsAny(name, "/\"'$` ") {
+		return nil, fmt.Errorf("invalid backup name: %q", name)
+	}
+
+	storage, err := newStorageClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	download := storageContainer(storage, fmt.Sprintf(`%s "%s/%s" %s`, storage.Copy, storage.Location, name, dumpFile))
+	restore := databaseContainer(ctx, fmt.Sprintf("gunzip -c %s | %s", dumpFile, mysqlClient("mysql")))
+
+	spec, err := podSpec(ctx, download, restore)
+	if err != nil {
+		return nil, err
+	}
+	spec.RestartPolicy = corev1.RestartPolicyNever
+
+	labels := common.DefaultLabels(RestoreComponent)
+	return []runtime.Object{&batchv1.Job{
+		TypeMeta: common.TypeMetaBatchJob,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", RestoreComponent, now.UTC().Format("20060102150405")),
+			Namespace: ctx.Namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				"bhojpur.net/backup": name,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			// A partially applied restore must not be retried automatically
+			BackoffLimit: pointer.Int32(0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       *spec,
+			},
+		},
+	}}, nil
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package backup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestRetentionScript(t *testing.T) {
+	// the listing is not sorted, like the one of mc find
+	backups := []string{
+		"backup/b/mysql/bhojpur-20211116020000.sql.gz",
+		"backup/b/mysql/bhojpur-20211118020000.sql.gz",
+		"backup/b/mysql/bhojpur-20211115020000.sql.gz",
+		"backup/b/mysql/bhojpur-20211117020000.sql.gz",
+	}
+
+	tests := []struct {
+		KeepLast    int32
+		Expectation []string
+	}{
+		{
+			KeepLast: 1,
+			Expectation: []string{
+				"backup/b/mysql/bhojpur-20211117020000.sql.gz",
+				"backup/b/mysql/bhojpur-20211116020000.sql.gz",
+				"backup/b/mysql/bhojpur-20211115020000.sql.gz",
+			},
+		},
+		{
+			KeepLast:    3,
+			Expectation: []string{"backup/b/mysql/bhojpur-20211115020000.sql.gz"},
+		},
+		{
+			KeepLast: 4,
+		},
+		{
+			KeepLast: 7,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("keep %d", test.KeepLast), func(t *testing.T) {
+			dir := t.TempDir()
+			removed := filepath.Join(dir, "removed")
+			stubs := map[string]string{
+				"list":   "printf '%s\\n' " + strings.Join(backups, " "),
+				"remove": `echo "$1" >> ` + removed,
+			}
+			for name, script := range stubs {
+				err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			script := retentionScript(&storageClient{List: "list", Remove: "remove"}, test.KeepLast)
+			cmd := exec.Command("/bin/sh", "-c", "set -eu\n"+script)
+			cmd.Env = append(os.Environ(), "PATH="+dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("retention script failed: %v: %s", err, out)
+			}
+
+			var act []string
+			if fc, err := os.ReadFile(removed); err == nil {
+				act = strings.Fields(string(fc))
+			}
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("retentionScript() removed unexpected backups (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCronjob(t *testing.T) {
+	type Expectation struct {
+		Image    string
+		Schedule string
+		Script   []string
+	}
+	tests := []struct {
+		Name          string
+		ObjectStorage config.ObjectStorage
+		Backup        config.DatabaseBackup
+		Expectation   Expectation
+	}{
+		{
+			Name:          "in-cluster storage",
+			ObjectStorage: config.ObjectStorage{InCluster: pointer.Bool(true)},
+			Backup:        config.DatabaseBackup{Schedule: "0 2 * * *", Bucket: "db"},
+			Expectation: Expectation{
+				Image:    MinioClientImage,
+				Schedule: "0 2 * * *",
+				Script: []string{
+					`mc cp /dump/backup.sql.gz "backup/db/mysql/$name"`,
+					`mc find "backup/db/mysql/" --name "*.sql.gz" | sort -r | tail -n +8 | while read -r obj; do mc rm "$obj"; done`,
+				},
+			},
+		},
+		{
+			Name: "cloud storage",
+			ObjectStorage: config.ObjectStorage{CloudStorage: &config.ObjectStorageCloudStorage{
+				ServiceAccount: config.ObjectRef{Kind: config.ObjectRefSecret, Name: "gcp"},
+				Project:        "project",
+			}},
+			Backup: config.DatabaseBackup{Schedule: "@daily", Bucket: "db", KeepLast: 2},
+			Expectation: Expectation{
+				Image:    GCloudImage,
+				Schedule: "@daily",
+				Script: []string{
+					`gsutil cp /dump/backup.sql.gz "gs://db/mysql/$name"`,
+					`gsutil ls "gs://db/mysql/*.sql.gz" | sort -r | tail -n +3 | while read -r obj; do gsutil rm "$obj"; done`,
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			backup := test.Backup
+			ctx := &common.RenderContext{
+				Namespace: "default",
+				Config: config.Config{
+					Database:      config.Database{InCluster: pointer.Bool(true), Backup: &backup},
+					ObjectStorage: test.ObjectStorage,
+				},
+			}
+
+			objs, err := cronjob(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(objs) != 1 {
+				t.Fatalf("expected one object, got %d", len(objs))
+			}
+			spec := objs[0].(*batchv1.CronJob).Spec
+			storage := spec.JobTemplate.Spec.Template.Spec.Containers[0]
+
+			act := Expectation{
+				Schedule: spec.Schedule,
+				Script:   strings.Split(storage.Args[0], "\n")[3:],
+			}
+			if strings.Contains(storage.Image, test.Expectation.Image) {
+				act.Image = test.Expectation.Image
+			} else {
+				act.Image = storage.Image
+			}
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("cronjob() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRestoreJob(t *testing.T) {
+	ctx := &common.RenderContext{
+		Namespace: "default",
+		Config: config.Config{
+			Database:      config.Database{InCluster: pointer.Bool(true), Backup: &config.DatabaseBackup{Schedule: "@daily", Bucket: "db"}},
+			ObjectStorage: config.ObjectStorage{InCluster: pointer.Bool(true)},
+		},
+	}
+
+	var act []string
+	for _, now := range []time.Time{
+		time.Date(2021, 11, 16, 20, 37, 36, 0, time.UTC),
+		time.Date(2021, 11, 16, 21, 37, 36, 0, time.FixedZone("CET", 3600)),
+		time.Date(2021, 11, 17, 8, 0, 0, 0, time.UTC),
+	} {
+		objs, err := RestoreJob(ctx, "bhojpur-20211116020000.sql.gz", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		job := objs[0].(*batchv1.Job)
+		if job.Labels["component"] != RestoreComponent {
+			t.Errorf("restore job %s is not labelled as %s", job.Name, RestoreComponent)
+		}
+		act = append(act, job.Name)
+	}
+
+	expectation := []string{"db-restore-20211116203736", "db-restore-20211116203736", "db-restore-20211117080000"}
+	if diff := cmp.Diff(expectation, act); diff != "" {
+		t.Errorf("RestoreJob() names mismatch (-want +got):\n%s", diff)
+	}
+}
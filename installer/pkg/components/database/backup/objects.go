@@ -0,0 +1,21 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package backup
+
+import (
+	"github.com/bhojpur/platform/installer/pkg/common"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var Objects = common.CompositeRenderFunc(
+	cronjob,
+	rolebinding,
+	func(ctx *common.RenderContext) ([]runtime.Object, error) {
+		if !enabled(ctx) {
+			return nil, nil
+		}
+		return common.DefaultServiceAccount(Component)(ctx)
+	},
+)
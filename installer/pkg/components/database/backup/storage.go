@@ -0,0 +1,93 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package backup
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+// storageClient describes how to transfer the backups to and from the object storage
+type storageClient struct {
+	Image string
+	Env   []corev1.EnvVar
+	// Setup is run before any other command to configure the client
+	Setup string
+	// Location is the base URL of the backups
+	Location string
+	Copy     string
+	// List prints the URL of every backup at Location, one per line
+	List   string
+	Remove string
+}
+
+// newStorageClient uses the same precedence as common.AddStorageMounts, so that
+// the client matches the credentials which are mounted
+func newStorageClient(ctx *common.RenderContext) (*storageClient, error) {
+	bucket := ctx.Config.Database.Backup.Bucket
+	repo := common.ThirdPartyContainerRepo(ctx.Config.Repository, common.DockerRegistryURL)
+
+	if ctx.Config.ObjectStorage.CloudStorage != nil {
+		location := fmt.Sprintf("gs://%s/%s", bucket, Prefix)
+		return &storageClient{
+			Image:    common.ImageName(repo, GCloudImage, GCloudTag),
+			Env:      []corev1.EnvVar{{Name: "HOME", Value: "/tmp"}},
+			Setup:    fmt.Sprintf("gcloud auth activate-service-account --key-file=%s", filepath.Join(common.StorageMount, "service-account.json")),
+			Location: location,
+			Copy:     "gsutil cp",
+			List:     fmt.Sprintf(`gsutil ls "%s/*%s"`, location, backupSuffix),
+			Remove:   "gsutil rm",
+		}, nil
+	}
+
+	location := fmt.Sprintf("backup/%s/%s", bucket, Prefix)
+	mc := &storageClient{
+		Image:    common.ImageName(repo, MinioClientImage, MinioClientTag),
+		Env:      []corev1.EnvVar{{Name: "HOME", Value: "/tmp"}},
+		Location: location,
+		Copy:     "mc cp",
+		List:     fmt.Sprintf(`mc find "%s/" --name "*%s"`, location, backupSuffix),
+		Remove:   "mc rm",
+	}
+
+	if ctx.Config.ObjectStorage.S3 != nil {
+		mc.Setup = fmt.Sprintf(`mc alias set backup "https://%s" "$(cat %s)" "$(cat %s)" && mc mb --ignore-existing "backup/%s"`,
+			ctx.Config.ObjectStorage.S3.Endpoint,
+			filepath.Join(common.StorageMount, "accessKeyId"),
+			filepath.Join(common.StorageMount, "secretAccessKey"),
+			bucket,
+		)
+		return mc, nil
+	}
+
+	if pointer.BoolDeref(ctx.Config.ObjectStorage.InCluster, false) || ctx.Config.ObjectStorage.Azure != nil {
+		// The generated storage keys change on every render, so read them from the MinIO secret
+		secretEnv := func(name, key string) corev1.EnvVar {
+			return corev1.EnvVar{
+				Name: name,
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: common.InClusterStorageSecret},
+					Key:                  key,
+				}},
+			}
+		}
+		mc.Env = append(mc.Env,
+			secretEnv("MINIO_ACCESS_KEY", minioAccessKey),
+			secretEnv("MINIO_SECRET_KEY", minioSecretKey),
+		)
+		mc.Setup = fmt.Sprintf(`mc alias set backup "http://minio.%s.svc.cluster.local:%d" "$MINIO_ACCESS_KEY" "$MINIO_SECRET_KEY" && mc mb --ignore-existing "backup/%s"`,
+			ctx.Namespace,
+			common.MinioServiceAPIPort,
+			bucket,
+		)
+		return mc, nil
+	}
+
+	return nil, fmt.Errorf("no valid storage configuration set")
+}
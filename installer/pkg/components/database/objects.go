@@ -6,6 +6,7 @@ package database
 
 import (
 	"github.com/bhojpur/platform/installer/pkg/common"
+	"github.com/bhojpur/platform/installer/pkg/components/database/backup"
 	"github.com/bhojpur/platform/installer/pkg/components/database/cloudsql"
 	"github.com/bhojpur/platform/installer/pkg/components/database/external"
 	"github.com/bhojpur/platform/installer/pkg/components/database/incluster"
@@ -28,7 +29,7 @@ func inClusterEnabled(cfg *common.RenderContext) bool {
 var Objects = common.CompositeRenderFunc(
 	common.CompositeRenderFunc(func(cfg *common.RenderContext) ([]runtime.Object, error) {
 		if inClusterEnabled(cfg) {
-			return incluster.Objects(cfg)
+			return common.CompositeRenderFunc(incluster.Objects, backup.Objects)(cfg)
 		}
 		if cloudSqlEnabled(cfg) {
 			return cloudsql.Objects(cfg)
@@ -47,6 +47,10 @@ type ConfigVersion interface {
 
 	// ClusterValidation introduces configuration specific cluster validation checks
 	ClusterValidation(cfg interface{}) cluster.ValidationChecks
+
+	// Redact replaces the secret values in the config, so that it can be shared.
+	// obj is expected to be the return value of Factory()
+	Redact(obj interface{}) error
 }
 
 // AddVersion adds a new version.
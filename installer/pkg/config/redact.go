@@ -0,0 +1,89 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Redacted replaces secret values
+const Redacted = "[redacted]"
+
+// secretKeySuffixes mark a key of free-form values as a secret. They are matched
+// against the end of the last words of the key, so that "dbPassword" and "API_KEY"
+// are secrets but "tokenUrl" and "secretName" are not.
+var secretKeySuffixes = []string{"password", "secret", "token", "apikey", "privatekey", "accesskey", "secretkey", "credentials"}
+
+// IsSecretKey is true if the key of a free-form value suggests it is a secret
+func IsSecretKey(key string) bool {
+	words := keyWords(key)
+	if len(words) == 0 {
+		return false
+	}
+	candidates := []string{words[len(words)-1]}
+	if len(words) > 1 {
+		candidates = append(candidates, words[len(words)-2]+words[len(words)-1])
+	}
+	for _, c := range candidates {
+		for _, s := range secretKeySuffixes {
+			if strings.HasSuffix(c, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keyWords splits a camelCase, snake_case, kebab-case or dotted key into its lower case words
+func keyWords(key string) []string {
+	var (
+		words []string
+		word  []rune
+	)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = nil
+		}
+	}
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// "apiKey" and "APIKey" both split before the "K"
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
+
+// RedactValues replaces the secrets in free-form values, such as Helm values or
+// JSON config files, in place. A value is a secret if its key is.
+func RedactValues(values interface{}) {
+	switch v := values.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if _, isString := val.(string); isString && IsSecretKey(key) {
+				v[key] = Redacted
+				continue
+			}
+			RedactValues(val)
+		}
+	case []interface{}:
+		for _, val := range v {
+			RedactValues(val)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"testing"
+)
+
+func TestIsSecretKey(t *testing.T) {
+	tests := []struct {
+		Key         string
+		Expectation bool
+	}{
+		{Key: "password", Expectation: true},
+		{Key: "rootPassword", Expectation: true},
+		{Key: "DB_PASSWORD", Expectation: true},
+		{Key: "dbpassword", Expectation: true},
+		{Key: "clientSecret", Expectation: true},
+		{Key: "accessToken", Expectation: true},
+		{Key: "apiKey", Expectation: true},
+		{Key: "APIKey", Expectation: true},
+		{Key: "api_key", Expectation: true},
+		{Key: "private-key", Expectation: true},
+		{Key: "secretAccessKey", Expectation: true},
+		{Key: "credentials", Expectation: true},
+		{Key: "tokenUrl", Expectation: false},
+		{Key: "token_endpoint", Expectation: false},
+		{Key: "secretName", Expectation: false},
+		{Key: "passwordFile", Expectation: false},
+		{Key: "accessKeyId", Expectation: false},
+		{Key: "keyboard", Expectation: false},
+		{Key: "", Expectation: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Key, func(t *testing.T) {
+			if act := IsSecretKey(test.Key); act != test.Expectation {
+				t.Errorf("IsSecretKey(%q) = %v, expected %v", test.Key, act, test.Expectation)
+			}
+		})
+	}
+}
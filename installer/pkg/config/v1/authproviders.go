@@ -0,0 +1,118 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"fmt"
+)
+
+const (
+	// AuthProviderGitHub covers github.com and GitHub Enterprise, whose OAuth endpoints
+	// only differ in the host
+	AuthProviderGitHub = "GitHub"
+	// AuthProviderGitLab covers gitlab.com and self-hosted GitLab, whose OAuth endpoints
+	// only differ in the host
+	AuthProviderGitLab          = "GitLab"
+	AuthProviderBitbucket       = "Bitbucket"
+	AuthProviderBitbucketServer = "BitbucketServer"
+	AuthProviderOIDC            = "OIDC"
+)
+
+type authProviderPreset struct {
+	// Host is the only host the provider is available on, if it can't be self-hosted
+	Host              string
+	AuthorizationPath string
+	TokenPath         string
+	SettingsPath      string
+	Scope             string
+	ScopeSeparator    string
+}
+
+// AuthProviderPresets are the defaults of the auth providers, keyed by their type
+var AuthProviderPresets = map[string]authProviderPreset{
+	AuthProviderGitHub: {
+		AuthorizationPath: "/login/oauth/authorize",
+		TokenPath:         "/login/oauth/access_token",
+		SettingsPath:      "/settings/applications",
+		Scope:             "user:email,public_repo",
+		ScopeSeparator:    ",",
+	},
+	AuthProviderGitLab: {
+		AuthorizationPath: "/oauth/authorize",
+		TokenPath:         "/oauth/token",
+		SettingsPath:      "/-/profile/applications",
+		Scope:             "read_user api",
+		ScopeSeparator:    " ",
+	},
+	AuthProviderBitbucket: {
+		Host:              "bitbucket.org",
+		AuthorizationPath: "/site/oauth2/authorize",
+		TokenPath:         "/site/oauth2/access_token",
+		SettingsPath:      "/account/settings/app-authorizations/",
+		Scope:             "account repository",
+		ScopeSeparator:    " ",
+	},
+	AuthProviderBitbucketServer: {
+		AuthorizationPath: "/rest/oauth2/latest/authorize",
+		TokenPath:         "/rest/oauth2/latest/token",
+		Scope:             "PUBLIC_REPOS REPO_WRITE",
+		ScopeSeparator:    " ",
+	},
+	AuthProviderOIDC: {
+		Scope:          "openid profile email",
+		ScopeSeparator: " ",
+	},
+}
+
+// OIDCDiscoveryPath is where an OIDC provider publishes its discovery document
+const OIDCDiscoveryPath = "/.well-known/openid-configuration"
+
+// AuthProviderCallbackURL is the callback URL of the auth provider for a host
+func AuthProviderCallbackURL(domain string, host string) string {
+	return fmt.Sprintf("https://%s/auth/%s/callback", domain, host)
+}
+
+// WithDefaults returns the auth provider with the callback URL and the defaults of its
+// type filled in. Values which are set are kept.
+func (p AuthProviderConfigs) WithDefaults(domain string) AuthProviderConfigs {
+	setDefault := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+
+	oauth := p.OAuth
+	setDefault(&oauth.CallBackUrl, AuthProviderCallbackURL(domain, p.Host))
+	p.OAuth = oauth
+
+	preset, ok := AuthProviderPresets[p.Type]
+	if !ok {
+		return p
+	}
+
+	base := "https://" + p.Host
+	setDefault(&oauth.Scope, preset.Scope)
+	setDefault(&oauth.ScopeSeparator, preset.ScopeSeparator)
+
+	if p.Type == AuthProviderOIDC {
+		// The endpoints are read from the discovery document, unless they're set
+		if oauth.AuthorizationUrl == "" && oauth.TokenUrl == "" {
+			setDefault(&oauth.ConfigURL, base+OIDCDiscoveryPath)
+		}
+	} else {
+		setDefault(&oauth.AuthorizationUrl, base+preset.AuthorizationPath)
+		setDefault(&oauth.TokenUrl, base+preset.TokenPath)
+		if preset.SettingsPath != "" {
+			settings := base + preset.SettingsPath
+			if p.Type == AuthProviderGitHub && oauth.ClientId != "" {
+				settings = fmt.Sprintf("%s/settings/connections/applications/%s", base, oauth.ClientId)
+			}
+			setDefault(&oauth.SettingsUrl, settings)
+		}
+	}
+
+	p.OAuth = oauth
+	return p
+}
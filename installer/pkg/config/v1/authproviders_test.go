@@ -0,0 +1,71 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAuthProviderWithDefaults(t *testing.T) {
+	tests := []struct {
+		Name         string
+		AuthProvider AuthProviderConfigs
+		Expectation  OAuth
+	}{
+		{
+			Name:         "GitHub Enterprise",
+			AuthProvider: AuthProviderConfigs{Host: "github.example.com", Type: AuthProviderGitHub, OAuth: OAuth{ClientId: "id"}},
+			Expectation: OAuth{
+				ClientId:         "id",
+				CallBackUrl:      "https://bhojpur.example.com/auth/github.example.com/callback",
+				AuthorizationUrl: "https://github.example.com/login/oauth/authorize",
+				TokenUrl:         "https://github.example.com/login/oauth/access_token",
+				Scope:            "user:email,public_repo",
+				ScopeSeparator:   ",",
+				SettingsUrl:      "https://github.example.com/settings/connections/applications/id",
+			},
+		},
+		{
+			Name:         "self-hosted GitLab",
+			AuthProvider: AuthProviderConfigs{Host: "gitlab.example.com", Type: AuthProviderGitLab, OAuth: OAuth{Scope: "read_user"}},
+			Expectation: OAuth{
+				CallBackUrl:      "https://bhojpur.example.com/auth/gitlab.example.com/callback",
+				AuthorizationUrl: "https://gitlab.example.com/oauth/authorize",
+				TokenUrl:         "https://gitlab.example.com/oauth/token",
+				Scope:            "read_user",
+				ScopeSeparator:   " ",
+				SettingsUrl:      "https://gitlab.example.com/-/profile/applications",
+			},
+		},
+		{
+			Name:         "OIDC",
+			AuthProvider: AuthProviderConfigs{Host: "sso.example.com", Type: AuthProviderOIDC},
+			Expectation: OAuth{
+				CallBackUrl:    "https://bhojpur.example.com/auth/sso.example.com/callback",
+				Scope:          "openid profile email",
+				ScopeSeparator: " ",
+				ConfigURL:      "https://sso.example.com/.well-known/openid-configuration",
+			},
+		},
+		{
+			Name:         "unknown type",
+			AuthProvider: AuthProviderConfigs{Host: "gitea.example.com", Type: "Gitea"},
+			Expectation: OAuth{
+				CallBackUrl: "https://bhojpur.example.com/auth/gitea.example.com/callback",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			act := test.AuthProvider.WithDefaults("bhojpur.example.com").OAuth
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("WithDefaults() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
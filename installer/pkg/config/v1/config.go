@@ -8,8 +8,10 @@ import (
 	"github.com/bhojpur/platform/installer/pkg/config"
 	"github.com/bhojpur/platform/ws-daemon/pkg/resources"
 
+	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 )
 
@@ -42,6 +44,7 @@ func (v version) Defaults(in interface{}) error {
 	}
 	cfg.Certificate.Kind = ObjectRefSecret
 	cfg.Certificate.Name = "https-certificates"
+	cfg.Database.Engine = DatabaseEngineMySQL
 	cfg.Database.InCluster = pointer.Bool(true)
 	cfg.Metadata.Region = "local"
 	cfg.ObjectStorage.InCluster = pointer.Bool(true)
@@ -75,12 +78,28 @@ type Config struct {
 
 	Jaeger Jaeger `json:"jaegerOperator" validate:"required"`
 
-	Certificate ObjectRef `json:"certificate" validate:"required"`
+	Certificate       ObjectRef          `json:"certificate" validate:"required"`
+	CertificateIssuer *CertificateIssuer `json:"certificateIssuer,omitempty"`
 
 	ImagePullSecrets []ObjectRef `json:"imagePullSecrets"`
 
 	Application Application `json:"application" validate:"required"`
 
+	PodSecurity PodSecurity `json:"podSecurity"`
+
+	// HelmValues are merged on top of the values the Installer generates for the
+	// embedded Helm charts, keyed by the chart name
+	HelmValues map[string]map[string]interface{} `json:"helmValues,omitempty" validate:"dive,keys,helm_chart,endkeys"`
+
+	// ClusterLink links workspace clusters with their meta cluster
+	ClusterLink *ClusterLink `json:"clusterLink,omitempty"`
+
+	// Components allows individual components to be left out of the render
+	Components Components `json:"components,omitempty" validate:"dive,keys,component_name,endkeys"`
+
+	// Charts are additional Helm charts rendered alongside the Bhojpur.NET Platform
+	Charts []ExternalChart `json:"charts,omitempty" validate:"unique=Name,dive"`
+
 	AuthProviders []AuthProviderConfigs `json:"authProviders" validate:"dive"`
 	BlockNewUsers BlockNewUsers         `json:"blockNewUsers"`
 	License       *ObjectRef            `json:"license,omitempty"`
@@ -105,14 +124,108 @@ type Tracing struct {
 	AgentHost *string `json:"agentHost,omitempty"`
 }
 
+// ClusterLink links workspace clusters with a meta cluster. The workspace clusters
+// register with the bp-manager-bridge of the meta cluster using mTLS.
+type ClusterLink struct {
+	// Name of the workspace cluster, required on workspace clusters
+	Name string `json:"name,omitempty"`
+	// Clusters are the workspace clusters the meta cluster accepts, required on the meta cluster
+	Clusters []LinkedCluster `json:"clusters,omitempty" validate:"unique=Name,dive"`
+	// Bridge is the bp-manager-bridge endpoint of the meta cluster, as host:port
+	Bridge string `json:"bridge" validate:"required,hostname_port"`
+	// CA is a secret with the tls.crt and tls.key of a CA shared by all clusters,
+	// which is used to issue the mTLS certificates with cert-manager
+	CA *ObjectRef `json:"ca,omitempty" validate:"required_without=Certificates,excluded_with=Certificates"`
+	// Certificates is a secret with the ca.crt, tls.crt and tls.key of this side of
+	// the link, as generated by the cluster-link command
+	Certificates *ObjectRef `json:"certificates,omitempty" validate:"required_without=CA"`
+}
+
+// LinkedCluster is a workspace cluster the meta cluster accepts
+type LinkedCluster struct {
+	// Name of the workspace cluster, which is the common name of its certificate
+	Name string `json:"name" validate:"required"`
+	// Score is used by the meta cluster to choose between workspace clusters, the highest wins
+	Score int32 `json:"score,omitempty" validate:"min=0"`
+}
+
+type Component struct {
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+type Components map[string]Component
+
+// Enabled returns if the component is enabled, which is the default
+func (c Components) Enabled(name string) bool {
+	return pointer.BoolDeref(c[name].Enabled, true)
+}
+
+type ExternalChart struct {
+	// Name is the release name of the chart
+	Name string `json:"name" validate:"required"`
+	// Chart is the path to a chart directory or archive, or an oci:// reference
+	Chart string `json:"chart" validate:"required"`
+	// Version of the chart, which is required for OCI references, validation fails otherwise
+	Version string `json:"version,omitempty"`
+	// Namespace defaults to the namespace the Bhojpur.NET Platform is rendered for
+	Namespace string                 `json:"namespace,omitempty"`
+	Values    map[string]interface{} `json:"values,omitempty"`
+}
+
+type DatabaseEngine string
+
+const (
+	DatabaseEngineMySQL DatabaseEngine = "mysql"
+)
+
 type Database struct {
+	// Engine defaults to MySQL, which is the only engine currently supported
+	Engine    DatabaseEngine    `json:"engine,omitempty" validate:"omitempty,database_engine"`
 	InCluster *bool             `json:"inCluster,omitempty"`
 	External  *DatabaseExternal `json:"external,omitempty"`
 	CloudSQL  *DatabaseCloudSQL `json:"cloudSQL,omitempty"`
+	// Backup is only supported for the in-cluster database, validation fails otherwise
+	Backup *DatabaseBackup `json:"backup,omitempty"`
+}
+
+type DatabaseBackup struct {
+	// Schedule is the cron schedule of the backup job
+	Schedule string `json:"schedule" validate:"required,cron"`
+	// Bucket in the object storage the backups are written to
+	Bucket string `json:"bucket" validate:"required"`
+	// KeepLast is the number of backups retained, older backups are deleted
+	KeepLast int32 `json:"keepLast,omitempty" validate:"omitempty,min=1"`
 }
 
 type DatabaseExternal struct {
-	Certificate ObjectRef `json:"certificate"`
+	Certificate ObjectRef         `json:"certificate"`
+	TLS         *DatabaseTLS      `json:"tls,omitempty"`
+	Pool        *DatabasePool     `json:"pool,omitempty"`
+	Timeouts    *DatabaseTimeouts `json:"timeouts,omitempty"`
+}
+
+type DatabaseTLSMode string
+
+const (
+	DatabaseTLSDisable    DatabaseTLSMode = "disable"
+	DatabaseTLSRequire    DatabaseTLSMode = "require"
+	DatabaseTLSVerifyFull DatabaseTLSMode = "verify-full"
+)
+
+type DatabaseTLS struct {
+	Mode DatabaseTLSMode `json:"mode" validate:"required,database_tls_mode"`
+	// CA is a secret with a ca.crt entry, used to verify the server certificate
+	CA *ObjectRef `json:"ca,omitempty" validate:"required_if=Mode verify-full"`
+}
+
+type DatabasePool struct {
+	MaxConnections     int32 `json:"maxConnections,omitempty" validate:"omitempty,min=1"`
+	MaxIdleConnections int32 `json:"maxIdleConnections,omitempty" validate:"omitempty,min=0"`
+}
+
+type DatabaseTimeouts struct {
+	Connect *metav1.Duration `json:"connect,omitempty"`
+	Idle    *metav1.Duration `json:"idle,omitempty"`
 }
 
 type DatabaseCloudSQL struct {
@@ -160,6 +273,34 @@ const (
 	ObjectRefSecret ObjectRefKind = "secret"
 )
 
+type IssuerKind string
+
+const (
+	IssuerKindIssuer        IssuerKind = "Issuer"
+	IssuerKindClusterIssuer IssuerKind = "ClusterIssuer"
+	IssuerKindACME          IssuerKind = "ACME"
+)
+
+// CertificateIssuer makes the installer render the public HTTPS certificate
+// into the secret named by Config.Certificate, rather than expecting that
+// secret to exist already.
+type CertificateIssuer struct {
+	// Kind is either an existing cert-manager Issuer/ClusterIssuer, or ACME
+	// for an issuer which is rendered by the installer
+	Kind IssuerKind             `json:"kind" validate:"required,issuer_kind"`
+	Name string                 `json:"name,omitempty" validate:"required_unless=Kind ACME"`
+	ACME *CertificateIssuerACME `json:"acme,omitempty" validate:"required_if=Kind ACME"`
+}
+
+type CertificateIssuerACME struct {
+	Server string `json:"server" validate:"required,url"`
+	Email  string `json:"email" validate:"required,email"`
+	// DNS01 is required as the certificate contains wildcard names
+	DNS01 *cmacme.ACMEChallengeSolverDNS01 `json:"dns01" validate:"required"`
+	// HTTP01 is optional and, if set, is used to solve the challenge for the bare domain only
+	HTTP01 *cmacme.ACMEChallengeSolverHTTP01 `json:"http01,omitempty"`
+}
+
 type ContainerRegistry struct {
 	InCluster *bool                      `json:"inCluster,omitempty" validate:"required"`
 	External  *ContainerRegistryExternal `json:"external,omitempty" validate:"required_if=InCluster false"`
@@ -207,6 +348,8 @@ type ApplicationRuntime struct {
 	ContainerDSocket     string        `json:"containerdSocket" validate:"required,startswith=/"`
 }
 
+// ApplicationTemplates are merged into the workspace pods. The default template
+// applies to every workspace, the others to the workspaces of their type.
 type ApplicationTemplates struct {
 	Default    *corev1.Pod `json:"default"`
 	Prebuild   *corev1.Pod `json:"prebuild"`
@@ -216,12 +359,58 @@ type ApplicationTemplates struct {
 	Probe      *corev1.Pod `json:"probe"`
 }
 
+// ApplicationTemplateTypes are the workspace types which have their own template
+var ApplicationTemplateTypes = []string{"regular", "prebuild", "ghost", "imagebuild", "probe"}
+
+// Get returns the template of a workspace type, or nil if there is none
+func (t *ApplicationTemplates) Get(workspaceType string) *corev1.Pod {
+	if t == nil {
+		return nil
+	}
+	switch workspaceType {
+	case "default":
+		return t.Default
+	case "regular":
+		return t.Regular
+	case "prebuild":
+		return t.Prebuild
+	case "ghost":
+		return t.Ghost
+	case "imagebuild":
+		return t.ImageBuild
+	case "probe":
+		return t.Probe
+	}
+	return nil
+}
+
 type Application struct {
 	Runtime   ApplicationRuntime    `json:"runtime" validate:"required"`
 	Resources Resources             `json:"resources" validate:"required"`
 	Templates *ApplicationTemplates `json:"templates,omitempty"`
 }
 
+type PodSecurityMode string
+
+const (
+	PodSecurityModePolicy    PodSecurityMode = "PodSecurityPolicy"
+	PodSecurityModeAdmission PodSecurityMode = "PodSecurityAdmission"
+)
+
+type PolicyEngine string
+
+const (
+	PolicyEngineKyverno    PolicyEngine = "kyverno"
+	PolicyEngineGatekeeper PolicyEngine = "gatekeeper"
+)
+
+type PodSecurity struct {
+	// Mode is chosen from the Kubernetes version if left empty
+	Mode PodSecurityMode `json:"mode,omitempty" validate:"omitempty,pod_security_mode"`
+	// PolicyEngine optionally renders policies restricting privileged pods in PodSecurityAdmission mode
+	PolicyEngine PolicyEngine `json:"policyEngine,omitempty" validate:"omitempty,policy_engine"`
+}
+
 type FSShiftMethod string
 
 const (
@@ -229,11 +418,11 @@ const (
 	FSShiftShiftFS FSShiftMethod = "shiftfs"
 )
 
-// todo(sje): I don't know if we want to put this in the config YAML
 type AuthProviderConfigs struct {
-	ID                  string            `json:"id" validate:"required"`
-	Host                string            `json:"host" validate:"required"`
-	Type                string            `json:"type" validate:"required"`
+	ID   string `json:"id" validate:"required"`
+	Host string `json:"host" validate:"required,hostname_rfc1123|hostname_port"`
+	// Type selects the preset which fills in the OAuth defaults
+	Type                string            `json:"type" validate:"required,auth_provider_type"`
 	BuiltIn             string            `json:"builtin"`
 	Verified            string            `json:"verified"`
 	OAuth               OAuth             `json:"oauth" validate:"required"`
@@ -252,15 +441,21 @@ type BlockNewUsers struct {
 }
 
 type OAuth struct {
-	ClientId            string            `json:"clientId" validate:"required"`
-	ClientSecret        string            `json:"clientSecret" validate:"required"`
-	CallBackUrl         string            `json:"callBackUrl" validate:"required"`
-	AuthorizationUrl    string            `json:"authorizationUrl"`
-	TokenUrl            string            `json:"tokenUrl"`
-	Scope               string            `json:"scope"`
-	ScopeSeparator      string            `json:"scopeSeparator"`
-	SettingsUrl         string            `json:"settingsUrl"`
+	// ClientId and ClientSecret are either set here or read from the
+	// clientId and clientSecret keys of the Credentials secret
+	ClientId     string     `json:"clientId,omitempty" validate:"required_without=Credentials,excluded_with=Credentials"`
+	ClientSecret string     `json:"clientSecret,omitempty" validate:"required_without=Credentials,excluded_with=Credentials"`
+	Credentials  *ObjectRef `json:"credentials,omitempty"`
+	// CallBackUrl defaults to https://$DOMAIN/auth/$HOST/callback when rendered, the
+	// other URLs and the scopes to the preset of the auth provider type
+	CallBackUrl         string            `json:"callBackUrl,omitempty" validate:"omitempty,url"`
+	AuthorizationUrl    string            `json:"authorizationUrl,omitempty" validate:"required_with=TokenUrl,omitempty,url"`
+	TokenUrl            string            `json:"tokenUrl,omitempty" validate:"required_with=AuthorizationUrl,omitempty,url"`
+	Scope               string            `json:"scope,omitempty"`
+	ScopeSeparator      string            `json:"scopeSeparator,omitempty"`
+	SettingsUrl         string            `json:"settingsUrl,omitempty" validate:"omitempty,url"`
 	AuthorizationParams map[string]string `json:"authorizationParams"`
-	ConfigURL           string            `json:"configURL"`
-	ConfigFn            string            `json:"configFn"`
+	// ConfigURL is the OIDC discovery document, which replaces the authorization and token URLs
+	ConfigURL string `json:"configURL,omitempty" validate:"omitempty,url,excluded_with=AuthorizationUrl"`
+	ConfigFn  string `json:"configFn"`
 }
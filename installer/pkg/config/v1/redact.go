@@ -0,0 +1,57 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"github.com/bhojpur/platform/installer/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func (v version) Redact(in interface{}) error {
+	cfg, ok := in.(*Config)
+	if !ok {
+		return config.ErrInvalidType
+	}
+
+	if cfg.Analytics != nil && cfg.Analytics.SegmentKey != "" {
+		cfg.Analytics.SegmentKey = config.Redacted
+	}
+
+	for i := range cfg.AuthProviders {
+		if cfg.AuthProviders[i].OAuth.ClientSecret != "" {
+			cfg.AuthProviders[i].OAuth.ClientSecret = config.Redacted
+		}
+	}
+
+	// The template environment variables are free-form, so none of their values are kept
+	if t := cfg.Application.Templates; t != nil {
+		for _, pod := range []*corev1.Pod{t.Default, t.Prebuild, t.Ghost, t.ImageBuild, t.Regular, t.Probe} {
+			if pod == nil {
+				continue
+			}
+			redactContainerEnv(pod.Spec.InitContainers)
+			redactContainerEnv(pod.Spec.Containers)
+		}
+	}
+
+	for _, values := range cfg.HelmValues {
+		config.RedactValues(values)
+	}
+	for _, chart := range cfg.Charts {
+		config.RedactValues(chart.Values)
+	}
+
+	return nil
+}
+
+func redactContainerEnv(containers []corev1.Container) {
+	for i := range containers {
+		for j := range containers[i].Env {
+			if containers[i].Env[j].Value != "" {
+				containers[i].Env[j].Value = config.Redacted
+			}
+		}
+	}
+}
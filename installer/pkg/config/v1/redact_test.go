@@ -0,0 +1,66 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/bhojpur/platform/installer/pkg/config"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRedact(t *testing.T) {
+	cfg := Config{
+		AuthProviders: []AuthProviderConfigs{{OAuth: OAuth{ClientSecret: "hunter2"}}},
+		HelmValues: map[string]map[string]interface{}{
+			"rabbitmq": {"auth": map[string]interface{}{"password": "hunter2", "tokenUrl": "https://example.com/token"}},
+		},
+		Application: Application{
+			Templates: &ApplicationTemplates{
+				Default: &corev1.Pod{Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{Name: "init", Env: []corev1.EnvVar{{Name: "NPM_AUTH", Value: "hunter2"}}}},
+					Containers: []corev1.Container{{Name: "workspace", Env: []corev1.EnvVar{
+						{Name: "REGISTRY_LOGIN", Value: "hunter2"},
+						{Name: "FROM_SECRET", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{Key: "login"}}},
+					}}},
+				}},
+			},
+		},
+	}
+
+	err := version{}.Redact(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type Expectation struct {
+		ClientSecret string
+		HelmValues   interface{}
+		InitEnv      []string
+		Env          []string
+	}
+	pod := cfg.Application.Templates.Default
+	act := Expectation{
+		ClientSecret: cfg.AuthProviders[0].OAuth.ClientSecret,
+		HelmValues:   cfg.HelmValues["rabbitmq"]["auth"],
+	}
+	for _, e := range pod.Spec.InitContainers[0].Env {
+		act.InitEnv = append(act.InitEnv, e.Value)
+	}
+	for _, e := range pod.Spec.Containers[0].Env {
+		act.Env = append(act.Env, e.Value)
+	}
+
+	expectation := Expectation{
+		ClientSecret: config.Redacted,
+		HelmValues:   map[string]interface{}{"password": config.Redacted, "tokenUrl": "https://example.com/token"},
+		InitEnv:      []string{config.Redacted},
+		Env:          []string{config.Redacted, ""},
+	}
+	if diff := cmp.Diff(expectation, act); diff != "" {
+		t.Errorf("Redact() mismatch (-want +got):\n%s", diff)
+	}
+}
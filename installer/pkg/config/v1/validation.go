@@ -5,9 +5,21 @@
 package config
 
 import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/bhojpur/platform/installer/pkg/cluster"
+	"github.com/bhojpur/platform/installer/pkg/license"
 
 	"github.com/go-playground/validator/v10"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
 )
 
 var InstallationKindList = map[InstallationKind]struct{}{
@@ -30,6 +42,191 @@ var ObjectRefKindList = map[ObjectRefKind]struct{}{
 	ObjectRefSecret: {},
 }
 
+var IssuerKindList = map[IssuerKind]struct{}{
+	IssuerKindIssuer:        {},
+	IssuerKindClusterIssuer: {},
+	IssuerKindACME:          {},
+}
+
+var PodSecurityModeList = map[PodSecurityMode]struct{}{
+	PodSecurityModePolicy:    {},
+	PodSecurityModeAdmission: {},
+}
+
+var PolicyEngineList = map[PolicyEngine]struct{}{
+	PolicyEngineKyverno:    {},
+	PolicyEngineGatekeeper: {},
+}
+
+var CronDescriptorList = map[string]struct{}{
+	"@yearly":   {},
+	"@annually": {},
+	"@monthly":  {},
+	"@weekly":   {},
+	"@daily":    {},
+	"@midnight": {},
+	"@hourly":   {},
+}
+
+// cronField is a field of a cron schedule. Names are the values from Min on,
+// eg the months from 1 on.
+type cronField struct {
+	Min, Max int
+	Names    []string
+	// Any allows "?" as a synonym of "*"
+	Any bool
+}
+
+// cronFields are the fields of a schedule as the CronJob controller parses it:
+// minute, hour, day of month, month and day of week
+var cronFields = []cronField{
+	{Min: 0, Max: 59},
+	{Min: 0, Max: 23},
+	{Min: 1, Max: 31, Any: true},
+	{Min: 1, Max: 12, Names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{Min: 0, Max: 6, Names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}, Any: true},
+}
+
+// validCronSchedule returns true if the schedule is a descriptor or has five fields
+// of comma separated values, ranges and steps, eg "0 */6 * * MON-FRI"
+func validCronSchedule(schedule string) bool {
+	if strings.HasPrefix(schedule, "@") {
+		_, ok := CronDescriptorList[schedule]
+		return ok
+	}
+
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFields) {
+		return false
+	}
+	for i, field := range fields {
+		for _, item := range strings.Split(field, ",") {
+			if !cronFields[i].valid(item) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (f cronField) valid(item string) bool {
+	parts := strings.SplitN(item, "/", 2)
+	if len(parts) == 2 {
+		step, err := strconv.Atoi(parts[1])
+		if err != nil || step < 1 {
+			return false
+		}
+	}
+	if parts[0] == "*" || (parts[0] == "?" && f.Any) {
+		return true
+	}
+
+	bounds := strings.SplitN(parts[0], "-", 2)
+	low, ok := f.value(bounds[0])
+	if !ok {
+		return false
+	}
+	if len(bounds) == 1 {
+		return true
+	}
+	high, ok := f.value(bounds[1])
+	return ok && low <= high
+}
+
+func (f cronField) value(s string) (int, bool) {
+	for i, name := range f.Names {
+		if strings.EqualFold(s, name) {
+			return f.Min + i, true
+		}
+	}
+	v, err := strconv.Atoi(s)
+	return v, err == nil && v >= f.Min && v <= f.Max
+}
+
+// ComponentList are the components which can be disabled
+var ComponentList = map[string]struct{}{
+	"agent-smith":       {},
+	"blobserve":         {},
+	"bp-daemon":         {},
+	"bp-manager":        {},
+	"bp-manager-bridge": {},
+	"bp-proxy":          {},
+	"bp-scheduler":      {},
+	"content-service":   {},
+	"dashboard":         {},
+	"database":          {},
+	"docker-registry":   {},
+	"ide-proxy":         {},
+	"image-builder-mk3": {},
+	"jaeger-operator":   {},
+	"migrations":        {},
+	"minio":             {},
+	"openvsx-proxy":     {},
+	"proxy":             {},
+	"rabbitmq":          {},
+	"registry-facade":   {},
+	"server":            {},
+}
+
+// ComponentDependencies lists the components each component needs to be enabled. A
+// dependency is only listed when both are rendered in the same installation kind.
+var ComponentDependencies = map[string][]string{
+	"blobserve":         {"registry-facade"},
+	"bp-manager":        {"bp-daemon", "bp-proxy", "registry-facade"},
+	"bp-manager-bridge": {"database", "rabbitmq"},
+	"bp-proxy":          {"bp-manager", "blobserve"},
+	"bp-scheduler":      {"bp-manager"},
+	"dashboard":         {"proxy"},
+	"ide-proxy":         {"proxy"},
+	"migrations":        {"database"},
+	"server":            {"content-service", "database", "proxy", "rabbitmq"},
+}
+
+// applicationComponents are the components which are only rendered in the Workspace
+// and Full installations. The others are rendered in the Meta and Full installations.
+var applicationComponents = map[string]struct{}{
+	"agent-smith":     {},
+	"blobserve":       {},
+	"bp-daemon":       {},
+	"bp-manager":      {},
+	"bp-proxy":        {},
+	"bp-scheduler":    {},
+	"registry-facade": {},
+}
+
+// componentRendered returns whether the component is part of the installation kind
+func componentRendered(kind InstallationKind, name string) bool {
+	switch kind {
+	case InstallationMeta:
+		_, ok := applicationComponents[name]
+		return !ok
+	case InstallationWorkspace:
+		_, ok := applicationComponents[name]
+		return ok
+	default:
+		return true
+	}
+}
+
+// HelmChartList are the embedded Helm charts which accept values overrides
+var HelmChartList = map[string]struct{}{
+	"docker-registry": {},
+	"jaeger-operator": {},
+	"minio":           {},
+	"mysql":           {},
+	"rabbitmq":        {},
+}
+
+var DatabaseEngineList = map[DatabaseEngine]struct{}{
+	DatabaseEngineMySQL: {},
+}
+
+var DatabaseTLSModeList = map[DatabaseTLSMode]struct{}{
+	DatabaseTLSDisable:    {},
+	DatabaseTLSRequire:    {},
+	DatabaseTLSVerifyFull: {},
+}
+
 var FSShiftMethodList = map[FSShiftMethod]struct{}{
 	FSShiftFuseFS:  {},
 	FSShiftShiftFS: {},
@@ -42,6 +239,37 @@ func (v version) LoadValidationFuncs(validate *validator.Validate) error {
 			_, ok := ObjectRefKindList[ObjectRefKind(fl.Field().String())]
 			return ok
 		},
+		"issuer_kind": func(fl validator.FieldLevel) bool {
+			_, ok := IssuerKindList[IssuerKind(fl.Field().String())]
+			return ok
+		},
+		"pod_security_mode": func(fl validator.FieldLevel) bool {
+			_, ok := PodSecurityModeList[PodSecurityMode(fl.Field().String())]
+			return ok
+		},
+		"policy_engine": func(fl validator.FieldLevel) bool {
+			_, ok := PolicyEngineList[PolicyEngine(fl.Field().String())]
+			return ok
+		},
+		"cron": func(fl validator.FieldLevel) bool {
+			return validCronSchedule(fl.Field().String())
+		},
+		"component_name": func(fl validator.FieldLevel) bool {
+			_, ok := ComponentList[fl.Field().String()]
+			return ok
+		},
+		"helm_chart": func(fl validator.FieldLevel) bool {
+			_, ok := HelmChartList[fl.Field().String()]
+			return ok
+		},
+		"database_engine": func(fl validator.FieldLevel) bool {
+			_, ok := DatabaseEngineList[DatabaseEngine(fl.Field().String())]
+			return ok
+		},
+		"database_tls_mode": func(fl validator.FieldLevel) bool {
+			_, ok := DatabaseTLSModeList[DatabaseTLSMode(fl.Field().String())]
+			return ok
+		},
 		"fs_shift_method": func(fl validator.FieldLevel) bool {
 			_, ok := FSShiftMethodList[FSShiftMethod(fl.Field().String())]
 			return ok
@@ -50,6 +278,10 @@ func (v version) LoadValidationFuncs(validate *validator.Validate) error {
 			_, ok := InstallationKindList[InstallationKind(fl.Field().String())]
 			return ok
 		},
+		"auth_provider_type": func(fl validator.FieldLevel) bool {
+			_, ok := AuthProviderPresets[fl.Field().String()]
+			return ok
+		},
 		"log_level": func(fl validator.FieldLevel) bool {
 			_, ok := LogLevelList[LogLevel(fl.Field().String())]
 			return ok
@@ -62,15 +294,304 @@ func (v version) LoadValidationFuncs(validate *validator.Validate) error {
 		}
 	}
 
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		validateComponents(sl)
+		validateDatabase(sl)
+		validateCharts(sl)
+		validateClusterLink(sl)
+		validateAuthProviders(sl)
+		validateApplicationTemplates(sl)
+	}, Config{})
+
 	return nil
 }
 
+// validateComponents ensures that no disabled component is needed by the rest of the config
+func validateComponents(sl validator.StructLevel) {
+	cfg := sl.Current().Interface().(Config)
+
+	requires := func(field interface{}, fieldName string, component string) {
+		if !cfg.Components.Enabled(component) {
+			sl.ReportError(field, fieldName, fieldName, "requires_component", component)
+		}
+	}
+
+	names := make([]string, 0, len(ComponentDependencies))
+	for name := range ComponentDependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !cfg.Components.Enabled(name) || !componentRendered(cfg.Kind, name) {
+			continue
+		}
+		for _, dep := range ComponentDependencies[name] {
+			requires(cfg.Components, "Components."+name, dep)
+		}
+	}
+
+	if pointer.BoolDeref(cfg.Database.InCluster, false) {
+		requires(cfg.Database, "Database", "database")
+	}
+	if pointer.BoolDeref(cfg.ObjectStorage.InCluster, false) || cfg.ObjectStorage.Azure != nil {
+		// MinIO is also the gateway to Azure
+		requires(cfg.ObjectStorage, "ObjectStorage", "minio")
+	}
+	if pointer.BoolDeref(cfg.ContainerRegistry.InCluster, false) {
+		requires(cfg.ContainerRegistry, "ContainerRegistry", "docker-registry")
+	}
+	if pointer.BoolDeref(cfg.Jaeger.InCluster, false) {
+		requires(cfg.Jaeger, "Jaeger", "jaeger-operator")
+	}
+}
+
+// validateDatabase ensures that backups are only configured for the in-cluster database
+func validateDatabase(sl validator.StructLevel) {
+	cfg := sl.Current().Interface().(Config)
+
+	if cfg.Database.Backup != nil && !pointer.BoolDeref(cfg.Database.InCluster, false) {
+		sl.ReportError(cfg.Database.Backup, "Database.Backup", "Backup", "requires_in_cluster", "Database")
+	}
+}
+
+// validateCharts ensures that OCI charts are pinned, as registries don't resolve the latest version
+func validateCharts(sl validator.StructLevel) {
+	cfg := sl.Current().Interface().(Config)
+
+	for i, c := range cfg.Charts {
+		if strings.HasPrefix(c.Chart, "oci://") && c.Version == "" {
+			sl.ReportError(c.Version, fmt.Sprintf("Charts[%d].Version", i), "Version", "required_for_oci", "")
+		}
+	}
+}
+
+// validateClusterLink ensures that each side of a cluster link knows the other
+func validateClusterLink(sl validator.StructLevel) {
+	cfg := sl.Current().Interface().(Config)
+
+	link := cfg.ClusterLink
+	if link == nil {
+		return
+	}
+	switch cfg.Kind {
+	case InstallationMeta:
+		if len(link.Clusters) == 0 {
+			sl.ReportError(link.Clusters, "ClusterLink.Clusters", "Clusters", "required_if", "Kind Meta")
+		}
+	case InstallationWorkspace:
+		if link.Name == "" {
+			sl.ReportError(link.Name, "ClusterLink.Name", "Name", "required_if", "Kind Workspace")
+		}
+	}
+}
+
+// validateAuthProviders ensures that the auth providers are consistent with their host and the domain
+func validateAuthProviders(sl validator.StructLevel) {
+	cfg := sl.Current().Interface().(Config)
+
+	for i, p := range cfg.AuthProviders {
+		field := fmt.Sprintf("AuthProviders[%d]", i)
+
+		preset, ok := AuthProviderPresets[p.Type]
+		if !ok {
+			// Reported by the field validation
+			continue
+		}
+		if preset.Host != "" && p.Host != preset.Host {
+			sl.ReportError(p.Host, field+".Host", "Host", "auth_provider_host", preset.Host)
+		}
+
+		if callback := AuthProviderCallbackURL(cfg.Domain, p.Host); p.OAuth.CallBackUrl != "" && p.OAuth.CallBackUrl != callback {
+			sl.ReportError(p.OAuth.CallBackUrl, field+".OAuth.CallBackUrl", "CallBackUrl", "auth_provider_callback", callback)
+		}
+
+		if p.Type == AuthProviderOIDC {
+			// The endpoints of an OIDC provider may be hosted elsewhere
+			continue
+		}
+		endpoints := []struct {
+			Name  string
+			Value string
+		}{
+			{"AuthorizationUrl", p.OAuth.AuthorizationUrl},
+			{"TokenUrl", p.OAuth.TokenUrl},
+		}
+		for _, e := range endpoints {
+			if e.Value == "" {
+				continue
+			}
+			u, err := url.Parse(e.Value)
+			if err != nil || u.Host != p.Host {
+				sl.ReportError(e.Value, field+".OAuth."+e.Name, e.Name, "auth_provider_host", p.Host)
+			}
+		}
+	}
+}
+
+const (
+	// ApplicationContainer is the container of a workspace pod which runs the workspace
+	ApplicationContainer = "workspace"
+	// ApplicationVolume is added to every workspace pod by the workspace manager and
+	// holds the content of the workspace
+	ApplicationVolume = "vol-this-workspace"
+	// ApplicationDaemonVolume is added to every workspace pod by the workspace manager
+	// and shared with bp-daemon
+	ApplicationDaemonVolume = "daemon-mount"
+)
+
+// maxApplicationResource is the most of a resource a workspace container may get, ie
+// the configured limit or, without one, the configured request
+func maxApplicationResource(res Resources, name corev1.ResourceName) (resource.Quantity, bool) {
+	if limit, ok := res.Limits[name]; ok {
+		return limit, true
+	}
+	request, ok := res.Requests[name]
+	return request, ok
+}
+
+// validateApplicationTemplates rejects the fields of the workspace pod templates which
+// the workspace manager overrides, resources beyond the configured ones and mounts of
+// volumes which are neither declared nor added by the workspace manager
+func validateApplicationTemplates(sl validator.StructLevel) {
+	cfg := sl.Current().Interface().(Config)
+	templates := cfg.Application.Templates
+	if templates == nil {
+		return
+	}
+
+	for _, tpe := range append([]string{"default"}, ApplicationTemplateTypes...) {
+		tpl := templates.Get(tpe)
+		if tpl == nil {
+			continue
+		}
+		field := "Application.Templates." + tpe
+
+		overridden := func(value interface{}, isSet bool, name string) {
+			if isSet {
+				sl.ReportError(value, field, tpe, "application_template_overridden", name)
+			}
+		}
+		overridden(tpl.Name, tpl.Name != "", "metadata.name")
+		overridden(tpl.GenerateName, tpl.GenerateName != "", "metadata.generateName")
+		overridden(tpl.Namespace, tpl.Namespace != "", "metadata.namespace")
+		overridden(tpl.Spec.NodeName, tpl.Spec.NodeName != "", "spec.nodeName")
+		overridden(tpl.Spec.Hostname, tpl.Spec.Hostname != "", "spec.hostname")
+		overridden(tpl.Spec.ServiceAccountName, tpl.Spec.ServiceAccountName != "", "spec.serviceAccountName")
+		overridden(tpl.Spec.RestartPolicy, tpl.Spec.RestartPolicy != "", "spec.restartPolicy")
+		overridden(tpl.Spec.AutomountServiceAccountToken, tpl.Spec.AutomountServiceAccountToken != nil, "spec.automountServiceAccountToken")
+
+		volumes := map[string]struct{}{
+			ApplicationVolume:       {},
+			ApplicationDaemonVolume: {},
+		}
+		for _, pod := range []*corev1.Pod{templates.Default, tpl} {
+			if pod == nil {
+				continue
+			}
+			for _, v := range pod.Spec.Volumes {
+				volumes[v.Name] = struct{}{}
+			}
+		}
+
+		containers := make([]corev1.Container, 0, len(tpl.Spec.InitContainers)+len(tpl.Spec.Containers))
+		containers = append(containers, tpl.Spec.InitContainers...)
+		containers = append(containers, tpl.Spec.Containers...)
+		for _, c := range containers {
+			if c.Name == ApplicationContainer {
+				overridden(c.Image, c.Image != "", "workspace.image")
+				overridden(c.Command, len(c.Command) > 0, "workspace.command")
+				overridden(c.Args, len(c.Args) > 0, "workspace.args")
+				overridden(c.Ports, len(c.Ports) > 0, "workspace.ports")
+			}
+
+			for _, m := range c.VolumeMounts {
+				if _, ok := volumes[m.Name]; !ok {
+					sl.ReportError(m.Name, field, tpe, "application_template_volume", m.Name)
+				}
+			}
+
+			for _, res := range []corev1.ResourceList{c.Resources.Requests, c.Resources.Limits} {
+				for name, quantity := range res {
+					ceiling, ok := maxApplicationResource(cfg.Application.Resources, name)
+					if ok && quantity.Cmp(ceiling) > 0 {
+						sl.ReportError(quantity, field, tpe, "application_template_resources", fmt.Sprintf("%s of container %s", name, c.Name))
+					}
+				}
+			}
+		}
+	}
+}
+
+// applicationTemplateSecrets are the secrets the workspace pod templates reference
+func applicationTemplateSecrets(templates *ApplicationTemplates) []string {
+	secrets := make(map[string]struct{})
+	for _, tpe := range append([]string{"default"}, ApplicationTemplateTypes...) {
+		tpl := templates.Get(tpe)
+		if tpl == nil {
+			continue
+		}
+
+		for _, s := range tpl.Spec.ImagePullSecrets {
+			secrets[s.Name] = struct{}{}
+		}
+		for _, v := range tpl.Spec.Volumes {
+			if v.Secret != nil {
+				secrets[v.Secret.SecretName] = struct{}{}
+			}
+		}
+		for _, c := range append(append([]corev1.Container{}, tpl.Spec.InitContainers...), tpl.Spec.Containers...) {
+			for _, e := range c.EnvFrom {
+				if e.SecretRef != nil {
+					secrets[e.SecretRef.Name] = struct{}{}
+				}
+			}
+			for _, e := range c.Env {
+				if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+					secrets[e.ValueFrom.SecretKeyRef.Name] = struct{}{}
+				}
+			}
+		}
+	}
+
+	res := make([]string, 0, len(secrets))
+	for s := range secrets {
+		res = append(res, s)
+	}
+	sort.Strings(res)
+	return res
+}
+
 // ClusterValidation introduces configuration specific cluster validation checks
 func (v version) ClusterValidation(rcfg interface{}) cluster.ValidationChecks {
 	cfg := rcfg.(*Config)
 
 	var res cluster.ValidationChecks
-	res = append(res, cluster.CheckSecret(cfg.Certificate.Name, cluster.CheckSecretRequiredData("tls.crt", "tls.key")))
+	if cfg.CertificateIssuer == nil {
+		res = append(res, cluster.CheckSecret(cfg.Certificate.Name, cluster.CheckSecretRequiredData("tls.crt", "tls.key")))
+	} else {
+		switch cfg.CertificateIssuer.Kind {
+		case IssuerKindIssuer:
+			res = append(res, cluster.CheckIssuer(cfg.CertificateIssuer.Name, false))
+		case IssuerKindClusterIssuer:
+			res = append(res, cluster.CheckIssuer(cfg.CertificateIssuer.Name, true))
+		case IssuerKindACME:
+			secrets := acmeSolverSecrets(cfg.CertificateIssuer.ACME)
+			names := make([]string, 0, len(secrets))
+			for name := range secrets {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				res = append(res, cluster.CheckSecret(name, cluster.CheckSecretRequiredData(secrets[name]...)))
+			}
+		}
+	}
+
+	var podSecurityAdmission *bool
+	if cfg.PodSecurity.Mode != "" {
+		podSecurityAdmission = pointer.Bool(cfg.PodSecurity.Mode == PodSecurityModeAdmission)
+	}
+	res = append(res, cluster.CheckPodSecurityMode(podSecurityAdmission))
 
 	if cfg.ObjectStorage.CloudStorage != nil {
 		secretName := cfg.ObjectStorage.CloudStorage.ServiceAccount.Name
@@ -97,14 +618,154 @@ func (v version) ClusterValidation(rcfg interface{}) cluster.ValidationChecks {
 		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("credentials.json", "encryptionKeys", "password", "username")))
 	}
 
+	if link := cfg.ClusterLink; link != nil && (cfg.Kind == InstallationMeta || cfg.Kind == InstallationWorkspace) {
+		if link.CA != nil {
+			res = append(res, cluster.CheckSecret(link.CA.Name, cluster.CheckSecretRequiredData("tls.crt", "tls.key")))
+		}
+		if link.Certificates != nil {
+			res = append(res, cluster.CheckSecret(link.Certificates.Name, cluster.CheckSecretRequiredData("ca.crt", "tls.crt", "tls.key")))
+		}
+	}
+
 	if cfg.Database.External != nil {
 		secretName := cfg.Database.External.Certificate.Name
 		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("encryptionKeys", "host", "password", "port", "username")))
+
+		opts := cluster.DatabaseConnectionOpts{
+			SecretName: secretName,
+		}
+		if tls := cfg.Database.External.TLS; tls != nil {
+			opts.SSLMode = mysqlSSLModes[tls.Mode]
+			if tls.CA != nil && tls.Mode != DatabaseTLSDisable {
+				res = append(res, cluster.CheckSecret(tls.CA.Name, cluster.CheckSecretRequiredData("ca.crt")))
+				opts.CASecretName = tls.CA.Name
+			}
+		}
+		if t := cfg.Database.External.Timeouts; t != nil && t.Connect != nil {
+			opts.ConnectTimeout = t.Connect.Duration
+		}
+		res = append(res, cluster.CheckDatabaseConnection(opts))
+	}
+
+	if cfg.Application.Templates != nil {
+		for _, secretName := range applicationTemplateSecrets(cfg.Application.Templates) {
+			res = append(res, cluster.CheckSecret(secretName))
+		}
+	}
+
+	for _, p := range cfg.AuthProviders {
+		if p.OAuth.Credentials != nil {
+			secretName := p.OAuth.Credentials.Name
+			res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("clientId", "clientSecret")))
+		}
 	}
 
 	if cfg.License != nil {
 		secretName := cfg.License.Name
-		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("license")))
+		res = append(res, cluster.CheckSecret(secretName, cluster.CheckSecretRequiredData("license"), cluster.CheckSecretRule(func(s *corev1.Secret) ([]cluster.ValidationError, error) {
+			lic, ok := s.Data["license"]
+			if !ok {
+				// Reported as missing entry
+				return nil, nil
+			}
+			return validateLicense(lic, cfg.Domain, time.Now()), nil
+		})))
+	}
+
+	return res
+}
+
+// licenseExpiryWarning is how long before the expiry of the license a warning is shown
+const licenseExpiryWarning = 30 * 24 * time.Hour
+
+// validateLicense reports the entitlements of the license and whether it can be used for the domain
+func validateLicense(lic []byte, domain string, now time.Time) []cluster.ValidationError {
+	// A license which cannot be verified, also for lack of public keys, is rejected
+	entitlements, err := license.Verify(lic)
+	if err != nil {
+		return []cluster.ValidationError{{
+			Message: err.Error(),
+			Type:    cluster.ValidationStatusError,
+		}}
+	}
+
+	res := []cluster.ValidationError{{
+		Message: fmt.Sprintf("%s license %s for %d seats, valid until %s", entitlements.Level, entitlements.ID, entitlements.Seats, entitlements.ValidUntil.Format(time.RFC3339)),
+		Type:    cluster.ValidationStatusOk,
+	}}
+	if entitlements.Domain != domain {
+		res = append(res, cluster.ValidationError{
+			Message: fmt.Sprintf("license is issued for the domain %s, not %s", entitlements.Domain, domain),
+			Type:    cluster.ValidationStatusError,
+		})
+	}
+	if entitlements.Expired(now) {
+		res = append(res, cluster.ValidationError{
+			Message: fmt.Sprintf("license expired on %s", entitlements.ValidUntil.Format(time.RFC3339)),
+			Type:    cluster.ValidationStatusError,
+		})
+	} else if entitlements.Expired(now.Add(licenseExpiryWarning)) {
+		res = append(res, cluster.ValidationError{
+			Message: fmt.Sprintf("license expires on %s", entitlements.ValidUntil.Format(time.RFC3339)),
+			Type:    cluster.ValidationStatusWarning,
+		})
+	}
+	return res
+}
+
+// mysqlSSLModes maps the TLS modes to the MySQL client --ssl-mode values
+var mysqlSSLModes = map[DatabaseTLSMode]string{
+	DatabaseTLSDisable:    "DISABLED",
+	DatabaseTLSRequire:    "REQUIRED",
+	DatabaseTLSVerifyFull: "VERIFY_IDENTITY",
+}
+
+// acmeSolverSecrets returns the secrets, and the keys within them, that the
+// DNS01 provider of an ACME issuer needs to exist in the installation namespace
+func acmeSolverSecrets(acme *CertificateIssuerACME) map[string][]string {
+	res := make(map[string][]string)
+	if acme == nil || acme.DNS01 == nil {
+		return res
+	}
+
+	add := func(sel *cmmeta.SecretKeySelector) {
+		if sel == nil || sel.Name == "" {
+			return
+		}
+		keys := res[sel.Name]
+		if sel.Key != "" {
+			keys = append(keys, sel.Key)
+		}
+		res[sel.Name] = keys
+	}
+
+	dns01 := acme.DNS01
+	if p := dns01.Akamai; p != nil {
+		add(&p.ClientToken)
+		add(&p.ClientSecret)
+		add(&p.AccessToken)
+	}
+	if p := dns01.CloudDNS; p != nil {
+		add(p.ServiceAccount)
+	}
+	if p := dns01.Cloudflare; p != nil {
+		add(p.APIKey)
+		add(p.APIToken)
+	}
+	if p := dns01.Route53; p != nil {
+		add(&p.SecretAccessKey)
+	}
+	if p := dns01.AzureDNS; p != nil {
+		add(p.ClientSecret)
+	}
+	if p := dns01.DigitalOcean; p != nil {
+		add(&p.Token)
+	}
+	if p := dns01.AcmeDNS; p != nil {
+		add(&p.AccountSecret)
+	}
+	if p := dns01.RFC2136; p != nil {
+		add(&p.TSIGSecret)
 	}
 
 	return res
@@ -0,0 +1,541 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package config
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/bhojpur/platform/installer/pkg/cluster"
+	"github.com/bhojpur/platform/installer/pkg/license"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+)
+
+func TestValidCronSchedule(t *testing.T) {
+	tests := []struct {
+		Schedule    string
+		Expectation bool
+	}{
+		{Schedule: "0 2 * * *", Expectation: true},
+		{Schedule: "*/15 0-6,22,23 1-31/2 JAN-mar ?", Expectation: true},
+		{Schedule: "30 4 ? * SUN,6", Expectation: true},
+		{Schedule: "@daily", Expectation: true},
+		{Schedule: "@every 1h"},
+		{Schedule: "0 2 * *"},
+		{Schedule: "0 2 * * * *"},
+		{Schedule: "60 2 * * *"},
+		{Schedule: "0 24 * * *"},
+		{Schedule: "0 2 0 * *"},
+		{Schedule: "0 2 * 13 *"},
+		{Schedule: "0 2 * * 7"},
+		{Schedule: "? 2 * * *"},
+		{Schedule: "0 6-2 * * *"},
+		{Schedule: "*/0 2 * * *"},
+		{Schedule: "0 2 * * MONDAY"},
+		{Schedule: "0,,5 2 * * *"},
+		{Schedule: "a b c d e"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Schedule, func(t *testing.T) {
+			if act := validCronSchedule(test.Schedule); act != test.Expectation {
+				t.Errorf("validCronSchedule(%q) = %v, expected %v", test.Schedule, act, test.Expectation)
+			}
+		})
+	}
+}
+
+func TestValidateComponents(t *testing.T) {
+	disabled := Component{Enabled: pointer.Bool(false)}
+	tests := []struct {
+		Name          string
+		Kind          InstallationKind
+		Components    Components
+		ObjectStorage ObjectStorage
+		Expectation   []string
+	}{
+		{
+			Name: "all enabled",
+			Kind: InstallationFull,
+		},
+		{
+			Name:        "disabled dependency",
+			Kind:        InstallationFull,
+			Components:  Components{"proxy": disabled},
+			Expectation: []string{"Config.Components.dashboard", "Config.Components.ide-proxy", "Config.Components.server"},
+		},
+		{
+			Name:       "disabled dependent",
+			Kind:       InstallationFull,
+			Components: Components{"bp-daemon": disabled, "bp-manager": disabled, "bp-proxy": disabled, "bp-scheduler": disabled},
+		},
+		{
+			Name:       "dependency of a component in another cluster",
+			Kind:       InstallationMeta,
+			Components: Components{"bp-daemon": disabled},
+		},
+		{
+			Name:        "dependency of a workspace component",
+			Kind:        InstallationWorkspace,
+			Components:  Components{"bp-daemon": disabled},
+			Expectation: []string{"Config.Components.bp-manager"},
+		},
+		{
+			Name:          "in-cluster storage",
+			Kind:          InstallationFull,
+			Components:    Components{"minio": disabled},
+			ObjectStorage: ObjectStorage{InCluster: pointer.Bool(true)},
+			Expectation:   []string{"Config.ObjectStorage"},
+		},
+		{
+			Name:          "Azure storage",
+			Kind:          InstallationFull,
+			Components:    Components{"minio": disabled},
+			ObjectStorage: ObjectStorage{Azure: &ObjectStorageAzure{}},
+			Expectation:   []string{"Config.ObjectStorage"},
+		},
+		{
+			Name:          "S3 storage",
+			Kind:          InstallationFull,
+			Components:    Components{"minio": disabled},
+			ObjectStorage: ObjectStorage{S3: &ObjectStorageS3{}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			act := structLevelErrors(t, validateComponents, Config{
+				Kind:          test.Kind,
+				Components:    test.Components,
+				ObjectStorage: test.ObjectStorage,
+			})
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("validateComponents() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateDatabase(t *testing.T) {
+	backup := &DatabaseBackup{Schedule: "@daily", Bucket: "db"}
+	tests := []struct {
+		Name        string
+		Database    Database
+		Expectation []string
+	}{
+		{
+			Name:     "in-cluster backup",
+			Database: Database{InCluster: pointer.Bool(true), Backup: backup},
+		},
+		{
+			Name:        "external backup",
+			Database:    Database{InCluster: pointer.Bool(false), Backup: backup},
+			Expectation: []string{"Config.Database.Backup"},
+		},
+		{
+			Name:        "unset in-cluster backup",
+			Database:    Database{Backup: backup},
+			Expectation: []string{"Config.Database.Backup"},
+		},
+		{
+			Name:     "external",
+			Database: Database{InCluster: pointer.Bool(false)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			act := structLevelErrors(t, validateDatabase, Config{Database: test.Database})
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("validateDatabase() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateCharts(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Charts      []ExternalChart
+		Expectation []string
+	}{
+		{
+			Name: "pinned OCI chart",
+			Charts: []ExternalChart{
+				{Name: "a", Chart: "oci://registry.example.com/charts/a", Version: "1.0.0"},
+			},
+		},
+		{
+			Name: "local chart",
+			Charts: []ExternalChart{
+				{Name: "a", Chart: "./charts/a"},
+			},
+		},
+		{
+			Name: "unpinned OCI chart",
+			Charts: []ExternalChart{
+				{Name: "a", Chart: "./charts/a"},
+				{Name: "b", Chart: "oci://registry.example.com/charts/b"},
+			},
+			Expectation: []string{"Config.Charts[1].Version"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			act := structLevelErrors(t, validateCharts, Config{Charts: test.Charts})
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("validateCharts() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateClusterLink(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Kind        InstallationKind
+		ClusterLink *ClusterLink
+		Expectation []string
+	}{
+		{
+			Name: "no link",
+			Kind: InstallationMeta,
+		},
+		{
+			Name:        "meta with clusters",
+			Kind:        InstallationMeta,
+			ClusterLink: &ClusterLink{Clusters: []LinkedCluster{{Name: "eu01"}, {Name: "us01", Score: 50}}},
+		},
+		{
+			Name:        "meta without clusters",
+			Kind:        InstallationMeta,
+			ClusterLink: &ClusterLink{Name: "eu01"},
+			Expectation: []string{"Config.ClusterLink.Clusters"},
+		},
+		{
+			Name:        "workspace with name",
+			Kind:        InstallationWorkspace,
+			ClusterLink: &ClusterLink{Name: "eu01"},
+		},
+		{
+			Name:        "workspace without name",
+			Kind:        InstallationWorkspace,
+			ClusterLink: &ClusterLink{Clusters: []LinkedCluster{{Name: "eu01"}}},
+			Expectation: []string{"Config.ClusterLink.Name"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			act := structLevelErrors(t, validateClusterLink, Config{Kind: test.Kind, ClusterLink: test.ClusterLink})
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("validateClusterLink() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateAuthProviders(t *testing.T) {
+	tests := []struct {
+		Name          string
+		AuthProviders []AuthProviderConfigs
+		Expectation   []string
+	}{
+		{
+			Name: "defaults",
+			AuthProviders: []AuthProviderConfigs{
+				{ID: "Public-GitHub", Host: "github.com", Type: AuthProviderGitHub},
+				{ID: "Bitbucket", Host: "bitbucket.org", Type: AuthProviderBitbucket},
+			},
+		},
+		{
+			Name: "self-hosted with matching URLs",
+			AuthProviders: []AuthProviderConfigs{
+				{ID: "GitLab", Host: "gitlab.example.com", Type: AuthProviderGitLab, OAuth: OAuth{
+					CallBackUrl:      "https://bhojpur.example.com/auth/gitlab.example.com/callback",
+					AuthorizationUrl: "https://gitlab.example.com/oauth/authorize",
+					TokenUrl:         "https://gitlab.example.com/oauth/token",
+				}},
+			},
+		},
+		{
+			Name: "OIDC endpoints on another host",
+			AuthProviders: []AuthProviderConfigs{
+				{ID: "SSO", Host: "sso.example.com", Type: AuthProviderOIDC, OAuth: OAuth{
+					AuthorizationUrl: "https://login.example.com/authorize",
+					TokenUrl:         "https://login.example.com/token",
+				}},
+			},
+		},
+		{
+			Name: "Bitbucket on another host",
+			AuthProviders: []AuthProviderConfigs{
+				{ID: "Bitbucket", Host: "bitbucket.example.com", Type: AuthProviderBitbucket},
+			},
+			Expectation: []string{"Config.AuthProviders[0].Host"},
+		},
+		{
+			Name: "callback URL of another domain",
+			AuthProviders: []AuthProviderConfigs{
+				{ID: "Public-GitHub", Host: "github.com", Type: AuthProviderGitHub},
+				{ID: "GitHub-Enterprise", Host: "github.example.com", Type: AuthProviderGitHub, OAuth: OAuth{
+					CallBackUrl: "https://other.example.com/auth/github.example.com/callback",
+				}},
+			},
+			Expectation: []string{"Config.AuthProviders[1].OAuth.CallBackUrl"},
+		},
+		{
+			Name: "endpoints on another host",
+			AuthProviders: []AuthProviderConfigs{
+				{ID: "GitLab", Host: "gitlab.example.com", Type: AuthProviderGitLab, OAuth: OAuth{
+					AuthorizationUrl: "https://gitlab.com/oauth/authorize",
+					TokenUrl:         "https://gitlab.example.com/oauth/token",
+				}},
+			},
+			Expectation: []string{"Config.AuthProviders[0].OAuth.AuthorizationUrl"},
+		},
+		{
+			Name: "unknown type",
+			AuthProviders: []AuthProviderConfigs{
+				{ID: "Gitea", Host: "gitea.example.com", Type: "Gitea"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			act := structLevelErrors(t, validateAuthProviders, Config{Domain: "bhojpur.example.com", AuthProviders: test.AuthProviders})
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("validateAuthProviders() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateApplicationTemplates(t *testing.T) {
+	resources := Resources{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("2"),
+		},
+	}
+	pod := func(containers ...corev1.Container) *corev1.Pod {
+		return &corev1.Pod{Spec: corev1.PodSpec{Containers: containers}}
+	}
+	requests := func(cpu, memory string) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}}
+	}
+
+	tests := []struct {
+		Name        string
+		Templates   *ApplicationTemplates
+		Expectation []string
+	}{
+		{
+			Name: "no templates",
+		},
+		{
+			Name: "within the configured resources",
+			Templates: &ApplicationTemplates{
+				Regular: pod(corev1.Container{Name: ApplicationContainer, Resources: requests("2", "2Gi")}),
+			},
+		},
+		{
+			Name: "request beyond the configured limit",
+			Templates: &ApplicationTemplates{
+				Regular: pod(corev1.Container{Name: ApplicationContainer, Resources: requests("3", "1Gi")}),
+			},
+			Expectation: []string{"Config.Application.Templates.regular"},
+		},
+		{
+			Name: "request beyond the configured request without a limit",
+			Templates: &ApplicationTemplates{
+				Prebuild: pod(corev1.Container{Name: ApplicationContainer, Resources: requests("1", "4Gi")}),
+			},
+			Expectation: []string{"Config.Application.Templates.prebuild"},
+		},
+		{
+			Name: "overridden fields",
+			Templates: &ApplicationTemplates{
+				Default: func() *corev1.Pod {
+					p := pod(corev1.Container{Name: ApplicationContainer, Image: "ubuntu"})
+					p.Spec.ServiceAccountName = "workspace"
+					return p
+				}(),
+			},
+			Expectation: []string{"Config.Application.Templates.default", "Config.Application.Templates.default"},
+		},
+		{
+			Name: "volumes declared by the default template or added by the workspace manager",
+			Templates: &ApplicationTemplates{
+				Default: &corev1.Pod{Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{{Name: "cache"}},
+				}},
+				Ghost: pod(corev1.Container{Name: "sidecar", VolumeMounts: []corev1.VolumeMount{
+					{Name: "cache", MountPath: "/cache"},
+					{Name: ApplicationVolume, MountPath: "/workspace"},
+					{Name: ApplicationDaemonVolume, MountPath: "/.workspace"},
+				}}),
+			},
+		},
+		{
+			Name: "undeclared volume",
+			Templates: &ApplicationTemplates{
+				Probe: pod(corev1.Container{Name: "sidecar", VolumeMounts: []corev1.VolumeMount{
+					{Name: "cache", MountPath: "/cache"},
+				}}),
+			},
+			Expectation: []string{"Config.Application.Templates.probe"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			cfg := Config{Application: Application{Resources: resources, Templates: test.Templates}}
+			act := structLevelErrors(t, validateApplicationTemplates, cfg)
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("validateApplicationTemplates() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateLicense(t *testing.T) {
+	testKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&testKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testKeys := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	sign := func(key *rsa.PrivateKey, domain string, validUntil time.Time) []byte {
+		payload, err := json.Marshal(license.Entitlements{ID: "test", Domain: domain, Level: license.LevelEnterprise, Seats: 50, ValidUntil: validUntil})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashed := sha256.Sum256(payload)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		fc, err := json.Marshal(map[string]interface{}{"payload": json.RawMessage(payload), "signature": signature})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []byte(base64.StdEncoding.EncodeToString(fc))
+	}
+
+	const domain = "bhojpur.example.com"
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	validUntil := now.AddDate(1, 0, 0)
+	tests := []struct {
+		Name        string
+		PublicKeys  string
+		License     []byte
+		Expectation []cluster.ValidationStatus
+	}{
+		{
+			Name:        "valid",
+			PublicKeys:  testKeys,
+			License:     sign(testKey, domain, validUntil),
+			Expectation: []cluster.ValidationStatus{cluster.ValidationStatusOk},
+		},
+		{
+			Name:        "no public keys",
+			License:     sign(testKey, domain, validUntil),
+			Expectation: []cluster.ValidationStatus{cluster.ValidationStatusError},
+		},
+		{
+			Name:        "signed by another key",
+			PublicKeys:  testKeys,
+			License:     sign(otherKey, domain, validUntil),
+			Expectation: []cluster.ValidationStatus{cluster.ValidationStatusError},
+		},
+		{
+			Name:        "other domain",
+			PublicKeys:  testKeys,
+			License:     sign(testKey, "other.example.com", validUntil),
+			Expectation: []cluster.ValidationStatus{cluster.ValidationStatusOk, cluster.ValidationStatusError},
+		},
+		{
+			Name:        "expires soon",
+			PublicKeys:  testKeys,
+			License:     sign(testKey, domain, now.AddDate(0, 0, 7)),
+			Expectation: []cluster.ValidationStatus{cluster.ValidationStatusOk, cluster.ValidationStatusWarning},
+		},
+		{
+			Name:        "expired",
+			PublicKeys:  testKeys,
+			License:     sign(testKey, domain, now),
+			Expectation: []cluster.ValidationStatus{cluster.ValidationStatusOk, cluster.ValidationStatusError},
+		},
+	}
+
+	defer func(keys string) { license.PublicKeys = keys }(license.PublicKeys)
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			license.PublicKeys = test.PublicKeys
+
+			var act []cluster.ValidationStatus
+			for _, e := range validateLicense(test.License, domain, now) {
+				act = append(act, e.Type)
+			}
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("validateLicense() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// structLevelErrors runs only the struct level validation fn and returns the fields it reported
+func structLevelErrors(t *testing.T, fn validator.StructLevelFunc, cfg Config) []string {
+	validate := validator.New()
+	// The custom tags must be known even though the field validation is filtered out
+	if err := (version{}).LoadValidationFuncs(validate); err != nil {
+		t.Fatal(err)
+	}
+	validate.RegisterStructValidation(fn, Config{})
+
+	err := validate.StructFiltered(cfg, func([]byte) bool { return true })
+	if err == nil {
+		return nil
+	}
+	errs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		t.Fatal(err)
+	}
+
+	var res []string
+	for _, e := range errs {
+		res = append(res, e.Namespace())
+	}
+	return res
+}
@@ -42,9 +42,25 @@ func Validate(version ConfigVersion, cfg interface{}) (r *ValidationResult, err
 				switch v.Tag() {
 				case "required":
 					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' is required", v.Namespace()))
-				case "required_if", "required_unless", "required_with":
+				case "required_if", "required_unless", "required_with", "excluded_with":
 					tag := strings.Replace(v.Tag(), "_", " ", -1)
 					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' is %s '%s'", v.Namespace(), tag, v.Param()))
+				case "requires_component":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' requires the component '%s' to be enabled", v.Namespace(), v.Param()))
+				case "requires_in_cluster":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' is only supported if %s.inCluster is true", v.Namespace(), v.Param()))
+				case "required_for_oci":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' is required for oci:// charts", v.Namespace()))
+				case "auth_provider_host":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' must use the host '%s'", v.Namespace(), v.Param()))
+				case "auth_provider_callback":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' must be '%s' to match the domain", v.Namespace(), v.Param()))
+				case "application_template_overridden":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' must not set %s, which is set by the workspace manager", v.Namespace(), v.Param()))
+				case "application_template_volume":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' mounts the undeclared volume '%s'", v.Namespace(), v.Param()))
+				case "application_template_resources":
+					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' exceeds the application resources for the %s", v.Namespace(), v.Param()))
 				case "startswith":
 					res.Fatal = append(res.Fatal, fmt.Sprintf("Field '%s' must start with '%s'", v.Namespace(), v.Param()))
 				default:
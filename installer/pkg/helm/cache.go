@@ -0,0 +1,92 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package helm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/bhojpur/platform/installer/third_party/charts"
+)
+
+// CacheDir is where the rendered charts are cached. The cache is disabled if empty,
+// which is the default as the cached manifests contain the generated secrets.
+var CacheDir string
+
+// cacheKey addresses a rendered chart by everything which affects its output
+func cacheKey(chart *charts.Chart, namespace string, vals, overrides map[string]interface{}) (string, error) {
+	digest, err := chart.Digest()
+	if err != nil {
+		return "", err
+	}
+
+	// Maps are marshalled with sorted keys, so the key is stable
+	input, err := json.Marshal(struct {
+		Chart     string                 `json:"chart"`
+		Namespace string                 `json:"namespace"`
+		Values    map[string]interface{} `json:"values"`
+		Overrides map[string]interface{} `json:"overrides"`
+	}{
+		Chart:     digest,
+		Namespace: namespace,
+		Values:    vals,
+		Overrides: overrides,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(input)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func readCache(key string) (manifest string, ok bool, err error) {
+	if CacheDir == "" {
+		return "", false, nil
+	}
+
+	fc, err := os.ReadFile(filepath.Join(CacheDir, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+
+	return string(fc), true, nil
+}
+
+func writeCache(key string, manifest string) error {
+	if CacheDir == "" {
+		return nil
+	}
+
+	// The manifests may contain generated secrets, so only the user can read them
+	err := os.MkdirAll(CacheDir, 0700)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that concurrent renders never read a partial entry
+	f, err := os.CreateTemp(CacheDir, key+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(manifest)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), filepath.Join(CacheDir, key))
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/bhojpur/platform/installer/pkg/common"
 	"os"
+	"sync"
 )
 
 // KeyValue ensure that a key/value pair is correctly formatted for Values
@@ -17,7 +18,7 @@ func KeyValue(key string, value string) string {
 
 // KeyFileValue ensure that a key/value pair is correctly formatted for FileValues
 func KeyFileValue(key string, data []byte) (string, error) {
-	dir, err := os.MkdirTemp("", "helm")
+	dir, err := mkdirTemp("helm")
 	if err != nil {
 		return "", err
 	}
@@ -31,4 +32,40 @@ func KeyFileValue(key string, data []byte) (string, error) {
 	return KeyValue(key, filePath), nil
 }
 
+var (
+	tempDirsMu sync.Mutex
+	tempDirs   []string
+)
+
+// mkdirTemp creates a temporary directory which is removed by Cleanup
+func mkdirTemp(pattern string) (string, error) {
+	dir, err := os.MkdirTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+
+	tempDirsMu.Lock()
+	defer tempDirsMu.Unlock()
+	tempDirs = append(tempDirs, dir)
+
+	return dir, nil
+}
+
+// Cleanup removes the temporary directories written while importing the charts
+func Cleanup() error {
+	tempDirsMu.Lock()
+	defer tempDirsMu.Unlock()
+
+	var res error
+	for _, dir := range tempDirs {
+		err := os.RemoveAll(dir)
+		if err != nil && res == nil {
+			res = err
+		}
+	}
+	tempDirs = nil
+
+	return res
+}
+
 type PkgConfig func(cfg *common.RenderContext) (*common.HelmConfig, error)
@@ -5,21 +5,21 @@
 package helm
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"sigs.k8s.io/yaml"
 	"strings"
 	"syscall"
 
 	"github.com/bhojpur/platform/installer/pkg/common"
+	config "github.com/bhojpur/platform/installer/pkg/config/v1"
 	"github.com/bhojpur/platform/installer/third_party/charts"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
-	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/release"
 )
@@ -46,33 +46,57 @@ func getContext(settings Settings) context.Context {
 	return ctx
 }
 
-func installDependencies(settings Settings) error {
-	client := action.NewDependency()
+// checkDependencies ensures that the dependencies of the chart are present at the
+// locked versions
+func checkDependencies(c *chart.Chart) error {
+	if len(c.Metadata.Dependencies) == 0 {
+		return nil
+	}
+	if c.Lock == nil {
+		return fmt.Errorf("chart %s has no Chart.lock, run third_party/charts/vendor.sh --update", c.Name())
+	}
 
-	man := &downloader.Manager{
-		Out:              &bytes.Buffer{},
-		ChartPath:        settings.Chart,
-		Keyring:          client.Keyring,
-		SkipUpdate:       client.SkipRefresh,
-		Verify:           downloader.VerifyNever,
-		RegistryClient:   settings.ActionConfig.RegistryClient,
-		Getters:          getter.All(settings.Env),
-		RepositoryConfig: settings.Env.RepositoryConfig,
-		RepositoryCache:  settings.Env.RepositoryCache,
-		Debug:            false,
+	locked := make(map[string]string, len(c.Lock.Dependencies))
+	for _, d := range c.Lock.Dependencies {
+		locked[d.Name] = d.Version
+	}
+	vendored := make(map[string]string, len(c.Dependencies()))
+	for _, d := range c.Dependencies() {
+		vendored[d.Name()] = d.Metadata.Version
 	}
 
-	err := man.Update()
-	if err != nil {
-		return fmt.Errorf("error pulling Helm dependency for %s: %w", settings.Chart, err)
+	for _, d := range c.Metadata.Dependencies {
+		version, ok := locked[d.Name]
+		if !ok {
+			return fmt.Errorf("dependency %s of chart %s is not locked, run third_party/charts/vendor.sh --update", d.Name, c.Name())
+		}
+		if vendored[d.Name] != version {
+			return fmt.Errorf("dependency %s %s of chart %s is not vendored, run third_party/charts/vendor.sh", d.Name, version, c.Name())
+		}
 	}
 
 	return nil
 }
 
+// loadChart loads the chart at settings.Chart with its vendored dependencies. The
+// dependencies are never downloaded, so that rendering works without network access.
+func loadChart(settings Settings) (*chart.Chart, error) {
+	c, err := loader.Load(settings.Chart)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkDependencies(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
 // runInstall emulates this function in Helm with simplified error handling
 // https://github.com/helm/helm/blob/9fafb4ad6811afb017cc464b630be2ff8390ac63/cmd/helm/install.go#L177
-func runInstall(settings Settings, client *action.Install) (*release.Release, error) {
+func runInstall(settings Settings, client *action.Install, vals map[string]interface{}) (*release.Release, error) {
 	name, _, err := client.NameAndChart([]string{
 		settings.Config.Name,
 		settings.Chart,
@@ -82,22 +106,28 @@ func runInstall(settings Settings, client *action.Install) (*release.Release, er
 	}
 	client.ReleaseName = name
 
-	p := getter.All(settings.Env)
-	vals, err := settings.Values.MergeValues(p)
+	chartRequested, err := loadChart(settings)
 	if err != nil {
 		return nil, err
 	}
 
-	chartRequested, err := loader.Load(settings.Chart)
-	if err != nil {
-		return nil, err
+	if len(settings.Overrides) > 0 {
+		defaults, err := chartutil.CoalesceValues(chartRequested, nil)
+		if err != nil {
+			return nil, err
+		}
+		err = checkValues(defaults, settings.Overrides, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid helmValues override: %w", err)
+		}
+		vals = mergeValues(vals, settings.Overrides)
 	}
 
 	return client.RunWithContext(getContext(settings), chartRequested, vals)
 }
 
 func writeCharts(chart *charts.Chart) (string, error) {
-	dir, err := os.MkdirTemp("", chart.Name)
+	dir, err := mkdirTemp(chart.Name)
 	if err != nil {
 		return "", err
 	}
@@ -146,6 +176,9 @@ func ImportTemplate(chart *charts.Chart, templateCfg TemplateConfig, pkgConfig P
 			}
 		}()
 
+		// The pkgConfig writes the file values to temporary directories
+		defer Cleanup()
+
 		helmConfig, err := pkgConfig(cfg)
 		if err != nil {
 			return nil, err
@@ -155,45 +188,62 @@ func ImportTemplate(chart *charts.Chart, templateCfg TemplateConfig, pkgConfig P
 			return nil, nil
 		}
 
-		dir, err := writeCharts(chart)
-		if err != nil {
-			return nil, err
-		}
-
 		settings := SettingsFactory(
 			&Config{
 				Debug:     false,
 				Name:      chart.Name,
 				Namespace: cfg.Namespace,
 			},
-			dir,
+			"",
 			helmConfig.Values,
 		)
+		settings.Overrides = cfg.Config.HelmValues[chart.Key()]
 
-		if _, err := os.Stat(filepath.Join(dir, "charts")); err != nil {
-			err = installDependencies(settings)
-			if err != nil {
-				return nil, err
-			}
+		vals, err := settings.Values.MergeValues(getter.All(settings.Env))
+		if err != nil {
+			return nil, err
 		}
 
-		client := action.NewInstall(settings.ActionConfig)
-		client.DryRun = true
-		client.ReleaseName = "RELEASE-NAME"
-		client.Replace = true // Skip the name check
-		client.ClientOnly = true
-		if templateCfg.Namespace == "" {
-			client.Namespace = cfg.Namespace
-		} else {
-			client.Namespace = templateCfg.Namespace
+		namespace := templateCfg.Namespace
+		if namespace == "" {
+			namespace = cfg.Namespace
 		}
 
-		rel, err := runInstall(settings, client)
+		key, err := cacheKey(chart, namespace, vals, settings.Overrides)
 		if err != nil {
 			return nil, err
 		}
-		if rel == nil {
-			return nil, fmt.Errorf("release for %s generated an empty value", settings.Config.Name)
+		manifest, ok, err := readCache(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			settings.Chart, err = writeCharts(chart)
+			if err != nil {
+				return nil, err
+			}
+
+			client := action.NewInstall(settings.ActionConfig)
+			client.DryRun = true
+			client.ReleaseName = "RELEASE-NAME"
+			client.Replace = true // Skip the name check
+			client.ClientOnly = true
+			client.Namespace = namespace
+
+			rel, err := runInstall(settings, client, vals)
+			if err != nil {
+				return nil, err
+			}
+			if rel == nil {
+				return nil, fmt.Errorf("release for %s generated an empty value", settings.Config.Name)
+			}
+			manifest = rel.Manifest
+
+			err = writeCache(key, manifest)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		// Fetch any additional Kubernetes files that need applying
@@ -206,6 +256,60 @@ func ImportTemplate(chart *charts.Chart, templateCfg TemplateConfig, pkgConfig P
 			templates = append(templates, string(b))
 		}
 
-		return append(templates, rel.Manifest), nil
+		return append(templates, manifest), nil
+	}
+}
+
+// ExternalCharts renders the additional charts declared in the config. Unlike the
+// embedded charts, OCI references are pulled from their registry.
+func ExternalCharts(cfg *common.RenderContext) ([]string, error) {
+	var res []string
+	for _, c := range cfg.Config.Charts {
+		manifest, err := importExternalChart(cfg, c)
+		if err != nil {
+			return nil, fmt.Errorf("cannot import chart %s: %w", c.Name, err)
+		}
+		res = append(res, manifest)
+	}
+	return res, nil
+}
+
+func importExternalChart(cfg *common.RenderContext, c config.ExternalChart) (string, error) {
+	namespace := c.Namespace
+	if namespace == "" {
+		namespace = cfg.Namespace
 	}
+
+	settings := SettingsFactory(
+		&Config{
+			Debug:     false,
+			Name:      c.Name,
+			Namespace: namespace,
+		},
+		"",
+		nil,
+	)
+
+	pathOpts := action.ChartPathOptions{Version: c.Version}
+	chartPath, err := pathOpts.LocateChart(c.Chart, settings.Env)
+	if err != nil {
+		return "", err
+	}
+	settings.Chart = chartPath
+
+	client := action.NewInstall(settings.ActionConfig)
+	client.DryRun = true
+	client.Replace = true // Skip the name check
+	client.ClientOnly = true
+	client.Namespace = namespace
+
+	rel, err := runInstall(settings, client, c.Values)
+	if err != nil {
+		return "", err
+	}
+	if rel == nil {
+		return "", fmt.Errorf("release for %s generated an empty value", c.Name)
+	}
+
+	return rel.Manifest, nil
 }
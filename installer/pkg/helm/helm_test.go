@@ -0,0 +1,48 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadChartWithoutVendoredDependencies(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Chart.yaml": `apiVersion: v2
+name: mysql
+version: 1.0.0
+dependencies:
+  - name: mysql
+    version: 8.6.2
+    repository: https://charts.bitnami.com/bitnami
+`,
+		"Chart.lock": `dependencies:
+- name: mysql
+  repository: https://charts.bitnami.com/bitnami
+  version: 8.6.2
+digest: sha256:5e56b7ac92a28277dda239b493b839719f246af92a5da5613ebbd34557e5a74b
+generated: "2021-11-16T00:00:00Z"
+`,
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The dependency must not be downloaded
+	_, err := loadChart(Settings{Chart: dir})
+	if err == nil {
+		t.Fatal("expected an error for a chart without vendored dependencies")
+	}
+	if !strings.Contains(err.Error(), "vendor.sh") {
+		t.Errorf("expected the error to point to vendor.sh, got: %v", err)
+	}
+}
@@ -29,6 +29,8 @@ type Settings struct {
 	Config       *Config
 	Env          *cli.EnvSettings
 	Values       *values.Options
+	// Overrides are merged on top of the Values and take precedence over them
+	Overrides map[string]interface{}
 }
 
 func SettingsFactory(config *Config, chart string, vals *values.Options) Settings {
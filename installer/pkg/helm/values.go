@@ -0,0 +1,123 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package helm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bhojpur/platform/installer/third_party/charts"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// CheckValues ensures that the helmValues of the config fit the default values of
+// the embedded charts, without rendering them
+func CheckValues(helmValues map[string]map[string]interface{}) error {
+	for _, chart := range charts.All() {
+		overrides, ok := helmValues[chart.Key()]
+		if !ok || len(overrides) == 0 {
+			continue
+		}
+
+		err := checkChartValues(chart, overrides)
+		if err != nil {
+			return fmt.Errorf("invalid helmValues.%s override: %w", chart.Key(), err)
+		}
+	}
+
+	return nil
+}
+
+func checkChartValues(chart *charts.Chart, overrides map[string]interface{}) error {
+	defer Cleanup()
+
+	dir, err := writeCharts(chart)
+	if err != nil {
+		return err
+	}
+	c, err := loadChart(SettingsFactory(&Config{Name: chart.Name}, dir, nil))
+	if err != nil {
+		return err
+	}
+	defaults, err := chartutil.CoalesceValues(c, nil)
+	if err != nil {
+		return err
+	}
+
+	return checkValues(defaults, overrides, "")
+}
+
+// mergeValues merges the src values into dst. Maps are merged and everything else
+// is replaced, so the values in src take precedence.
+func mergeValues(dst, src map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(dst))
+	for k, v := range dst {
+		res[k] = v
+	}
+
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]interface{}); ok {
+			if dstMap, ok := res[k].(map[string]interface{}); ok {
+				res[k] = mergeValues(dstMap, srcMap)
+				continue
+			}
+		}
+		res[k] = v
+	}
+
+	return res
+}
+
+// checkValues ensures that every key in the overrides exists in the default values
+// of the chart, and that maps are only overridden by maps. Maps which are empty by
+// default, eg annotations, accept any key.
+func checkValues(defaults, overrides map[string]interface{}, path string) error {
+	keys := make([]string, 0, len(overrides))
+	for k := range overrides {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		key := k
+		if path != "" {
+			key = path + "." + k
+		}
+
+		def, ok := defaults[k]
+		if !ok {
+			if path == "" && k == chartutil.GlobalKey {
+				continue
+			}
+			return fmt.Errorf("unknown value %s", key)
+		}
+		override := overrides[k]
+		if def == nil || override == nil {
+			// Values which are null by default accept anything, and null removes a value
+			continue
+		}
+
+		// A map must not replace a scalar or the other way round, the chart's
+		// templates would fail or silently ignore the value
+		defMap, defIsMap := def.(map[string]interface{})
+		overrideMap, overrideIsMap := override.(map[string]interface{})
+		if defIsMap && !overrideIsMap {
+			return fmt.Errorf("value %s must be a map", key)
+		}
+		if !defIsMap && overrideIsMap {
+			return fmt.Errorf("value %s must not be a map", key)
+		}
+		if !defIsMap || len(defMap) == 0 {
+			continue
+		}
+
+		err := checkValues(defMap, overrideMap, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
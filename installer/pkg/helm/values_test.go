@@ -0,0 +1,157 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMergeValues(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Dst         map[string]interface{}
+		Src         map[string]interface{}
+		Expectation map[string]interface{}
+	}{
+		{
+			Name: "nested maps are merged",
+			Dst: map[string]interface{}{
+				"primary": map[string]interface{}{
+					"persistence": map[string]interface{}{"size": "8Gi", "enabled": true},
+				},
+				"image": "mysql",
+			},
+			Src: map[string]interface{}{
+				"primary": map[string]interface{}{
+					"persistence": map[string]interface{}{"size": "20Gi"},
+				},
+			},
+			Expectation: map[string]interface{}{
+				"primary": map[string]interface{}{
+					"persistence": map[string]interface{}{"size": "20Gi", "enabled": true},
+				},
+				"image": "mysql",
+			},
+		},
+		{
+			Name:        "lists are replaced",
+			Dst:         map[string]interface{}{"args": []interface{}{"a", "b"}},
+			Src:         map[string]interface{}{"args": []interface{}{"c"}},
+			Expectation: map[string]interface{}{"args": []interface{}{"c"}},
+		},
+		{
+			Name:        "new keys are added",
+			Dst:         map[string]interface{}{"a": 1},
+			Src:         map[string]interface{}{"b": map[string]interface{}{"c": 2}},
+			Expectation: map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			dst := cloneValues(test.Dst)
+
+			act := mergeValues(test.Dst, test.Src)
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("mergeValues() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(dst, test.Dst); diff != "" {
+				t.Errorf("mergeValues() modified dst (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func cloneValues(vals map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(vals))
+	for k, v := range vals {
+		if m, ok := v.(map[string]interface{}); ok {
+			v = cloneValues(m)
+		}
+		res[k] = v
+	}
+	return res
+}
+
+func TestCheckValues(t *testing.T) {
+	defaults := map[string]interface{}{
+		"primary": map[string]interface{}{
+			"persistence": map[string]interface{}{"size": "8Gi"},
+			"annotations": map[string]interface{}{},
+			"resources":   map[string]interface{}{"limits": map[string]interface{}{"cpu": "1"}},
+			"affinity":    nil,
+		},
+		"replicas": 1,
+	}
+
+	tests := []struct {
+		Name        string
+		Overrides   map[string]interface{}
+		Expectation string
+	}{
+		{
+			Name: "known values",
+			Overrides: map[string]interface{}{
+				"primary":  map[string]interface{}{"persistence": map[string]interface{}{"size": "20Gi"}},
+				"replicas": 2,
+			},
+		},
+		{
+			Name:      "global values",
+			Overrides: map[string]interface{}{"global": map[string]interface{}{"storageClass": "fast"}},
+		},
+		{
+			Name:      "empty maps accept any key",
+			Overrides: map[string]interface{}{"primary": map[string]interface{}{"annotations": map[string]interface{}{"a": "b"}}},
+		},
+		{
+			Name:      "null defaults accept anything",
+			Overrides: map[string]interface{}{"primary": map[string]interface{}{"affinity": map[string]interface{}{"nodeAffinity": "x"}}},
+		},
+		{
+			Name:      "null removes a value",
+			Overrides: map[string]interface{}{"primary": map[string]interface{}{"resources": nil}},
+		},
+		{
+			Name:        "unknown value",
+			Overrides:   map[string]interface{}{"primary": map[string]interface{}{"persistense": map[string]interface{}{}}},
+			Expectation: "unknown value primary.persistense",
+		},
+		{
+			Name:        "unknown nested value",
+			Overrides:   map[string]interface{}{"primary": map[string]interface{}{"resources": map[string]interface{}{"limit": map[string]interface{}{}}}},
+			Expectation: "unknown value primary.resources.limit",
+		},
+		{
+			Name:        "map over scalar",
+			Overrides:   map[string]interface{}{"replicas": map[string]interface{}{"count": 2}},
+			Expectation: "value replicas must not be a map",
+		},
+		{
+			Name:        "scalar over map",
+			Overrides:   map[string]interface{}{"primary": map[string]interface{}{"persistence": "20Gi"}},
+			Expectation: "value primary.persistence must be a map",
+		},
+		{
+			Name:        "scalar over empty map",
+			Overrides:   map[string]interface{}{"primary": map[string]interface{}{"annotations": "a=b"}},
+			Expectation: "value primary.annotations must be a map",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var act string
+			if err := checkValues(defaults, test.Overrides, ""); err != nil {
+				act = err.Error()
+			}
+			if act != test.Expectation {
+				t.Errorf("checkValues() = %q, expected %q", act, test.Expectation)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package license
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+)
+
+var (
+	ErrMalformed        = errors.New("license is malformed")
+	ErrInvalidSignature = errors.New("license signature is invalid")
+	ErrNoPublicKeys     = errors.New("license signature cannot be verified, this build contains no license public keys")
+)
+
+// keys are the public keys licenses are signed with, see keys/README.md. All keys
+// are trusted, so that the signing key can be rotated.
+//
+//go:embed keys
+var keys embed.FS
+
+// PublicKeys are further trusted public keys, set at build time so that they need
+// not be committed. They are the base64 encoded PEM blocks of the keys:
+//
+//	go build -ldflags "-X 'github.com/bhojpur/platform/installer/pkg/license.PublicKeys=$(base64 -w0 keys.pem)'"
+var PublicKeys string
+
+// Level is the kind of subscription a license grants
+type Level string
+
+const (
+	LevelProfessional Level = "professional"
+	LevelEnterprise   Level = "enterprise"
+)
+
+// Entitlements are the signed contents of a license
+type Entitlements struct {
+	ID         string    `json:"id"`
+	Domain     string    `json:"domain"`
+	Level      Level     `json:"level"`
+	Seats      int       `json:"seats"`
+	ValidUntil time.Time `json:"validUntil"`
+}
+
+// Expired is true if the license is no longer valid at the given time
+func (e *Entitlements) Expired(now time.Time) bool {
+	return !now.Before(e.ValidUntil)
+}
+
+// signedLicense is the decoded license file. The payload is kept as it is
+// signed, so that fields unknown to this version don't break the signature.
+type signedLicense struct {
+	Payload   json.RawMessage `json:"payload"`
+	Signature []byte          `json:"signature"`
+}
+
+// Verify decodes a license and verifies its signature against the embedded public keys
+// and PublicKeys. It returns ErrNoPublicKeys if there are none to verify the license with.
+func Verify(license []byte) (*Entitlements, error) {
+	publicKeys, err := embeddedKeys()
+	if err != nil {
+		return nil, err
+	}
+	buildKeys, err := buildTimeKeys()
+	if err != nil {
+		return nil, err
+	}
+	return verify(license, append(publicKeys, buildKeys...))
+}
+
+func verify(license []byte, publicKeys []*rsa.PublicKey) (*Entitlements, error) {
+	signed, res, err := decode(license)
+	if err != nil {
+		return nil, err
+	}
+	if len(publicKeys) == 0 {
+		return nil, ErrNoPublicKeys
+	}
+
+	hashed := sha256.Sum256(signed.Payload)
+	for _, k := range publicKeys {
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, hashed[:], signed.Signature) == nil {
+			return res, nil
+		}
+	}
+	return nil, ErrInvalidSignature
+}
+
+func decode(license []byte) (*signedLicense, *Entitlements, error) {
+	raw, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(license)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	var signed signedLicense
+	err = json.Unmarshal(raw, &signed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if len(signed.Payload) == 0 || len(signed.Signature) == 0 {
+		return nil, nil, fmt.Errorf("%w: payload or signature missing", ErrMalformed)
+	}
+
+	var res Entitlements
+	err = json.Unmarshal(signed.Payload, &res)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if res.Domain == "" || res.ValidUntil.IsZero() {
+		return nil, nil, fmt.Errorf("%w: domain or validUntil missing", ErrMalformed)
+	}
+
+	return &signed, &res, nil
+}
+
+func embeddedKeys() ([]*rsa.PublicKey, error) {
+	files, err := keys.ReadDir("keys")
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*rsa.PublicKey, 0, len(files))
+	for _, f := range files {
+		if path.Ext(f.Name()) != ".pem" {
+			continue
+		}
+		fc, err := keys.ReadFile(path.Join("keys", f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		key, err := parsePublicKey(fc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s: %w", f.Name(), err)
+		}
+		res = append(res, key)
+	}
+	return res, nil
+}
+
+func buildTimeKeys() ([]*rsa.PublicKey, error) {
+	if PublicKeys == "" {
+		return nil, nil
+	}
+	fc, err := base64.StdEncoding.DecodeString(PublicKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid build time public keys: %w", err)
+	}
+
+	var res []*rsa.PublicKey
+	for {
+		var block *pem.Block
+		block, fc = pem.Decode(fc)
+		if block == nil {
+			break
+		}
+		key, err := parsePublicKeyBlock(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid build time public key: %w", err)
+		}
+		res = append(res, key)
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("invalid build time public keys: no PEM data found")
+	}
+	return res, nil
+}
+
+func parsePublicKey(fc []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(fc)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+	return parsePublicKeyBlock(block)
+}
+
+func parsePublicKeyBlock(block *pem.Block) (*rsa.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA public key")
+	}
+	return rsaKey, nil
+}
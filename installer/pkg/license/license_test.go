@@ -0,0 +1,193 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package license
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func sign(t *testing.T, key *rsa.PrivateKey, payload []byte) []byte {
+	hashed := sha256.Sum256(payload)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fc, err := json.Marshal(signedLicense{Payload: payload, Signature: signature})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(base64.StdEncoding.EncodeToString(fc))
+}
+
+func TestVerify(t *testing.T) {
+	testKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validUntil := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	entitlements := Entitlements{
+		ID:         "test",
+		Domain:     "bhojpur.example.com",
+		Level:      LevelEnterprise,
+		Seats:      50,
+		ValidUntil: validUntil,
+	}
+	payload, err := json.Marshal(entitlements)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Name        string
+		License     []byte
+		Expectation *Entitlements
+		Err         error
+	}{
+		{
+			Name:        "valid",
+			License:     sign(t, testKey, payload),
+			Expectation: &entitlements,
+		},
+		{
+			Name:        "unknown fields",
+			License:     sign(t, testKey, []byte(`{"id":"test","domain":"bhojpur.example.com","validUntil":"2030-01-01T00:00:00Z","feature":"x"}`)),
+			Expectation: &Entitlements{ID: "test", Domain: "bhojpur.example.com", ValidUntil: validUntil},
+		},
+		{
+			Name:    "signed by another key",
+			License: sign(t, otherKey, payload),
+			Err:     ErrInvalidSignature,
+		},
+		{
+			Name: "tampered payload",
+			License: func() []byte {
+				raw, _ := base64.StdEncoding.DecodeString(string(sign(t, testKey, payload)))
+				var signed signedLicense
+				_ = json.Unmarshal(raw, &signed)
+				signed.Payload = []byte(`{"id":"test","domain":"bhojpur.example.com","seats":5000,"validUntil":"2030-01-01T00:00:00Z"}`)
+				fc, _ := json.Marshal(signed)
+				return []byte(base64.StdEncoding.EncodeToString(fc))
+			}(),
+			Err: ErrInvalidSignature,
+		},
+		{
+			Name:    "not base64",
+			License: []byte("not a license"),
+			Err:     ErrMalformed,
+		},
+		{
+			Name:    "no signature",
+			License: []byte(base64.StdEncoding.EncodeToString([]byte(`{"payload":{}}`))),
+			Err:     ErrMalformed,
+		},
+		{
+			Name:    "no domain",
+			License: sign(t, testKey, []byte(`{"id":"test","validUntil":"2030-01-01T00:00:00Z"}`)),
+			Err:     ErrMalformed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			act, err := verify(test.License, []*rsa.PublicKey{&testKey.PublicKey})
+			if !errors.Is(err, test.Err) {
+				t.Fatalf("unexpected error: got %v, expected %v", err, test.Err)
+			}
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected entitlements (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExpired(t *testing.T) {
+	validUntil := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := Entitlements{ValidUntil: validUntil}
+
+	if e.Expired(validUntil.Add(-time.Second)) {
+		t.Error("license expired before validUntil")
+	}
+	if !e.Expired(validUntil) {
+		t.Error("license not expired at validUntil")
+	}
+}
+
+func TestVerifyWithoutPublicKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lic := sign(t, key, []byte(`{"id":"test","domain":"bhojpur.example.com","validUntil":"2030-01-01T00:00:00Z"}`))
+
+	act, err := verify(lic, nil)
+	if !errors.Is(err, ErrNoPublicKeys) {
+		t.Fatalf("unexpected error: got %v, expected %v", err, ErrNoPublicKeys)
+	}
+	if act != nil {
+		t.Errorf("unverified license returned entitlements: %v", act)
+	}
+}
+
+func TestBuildTimeKeys(t *testing.T) {
+	testKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lic := sign(t, testKey, []byte(`{"id":"test","domain":"bhojpur.example.com","validUntil":"2030-01-01T00:00:00Z"}`))
+
+	defer func(keys string) { PublicKeys = keys }(PublicKeys)
+	PublicKeys = base64.StdEncoding.EncodeToString(append(publicKeyPEM(t, otherKey), publicKeyPEM(t, testKey)...))
+
+	act, err := Verify(lic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectation := &Entitlements{ID: "test", Domain: "bhojpur.example.com", ValidUntil: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if diff := cmp.Diff(expectation, act); diff != "" {
+		t.Errorf("unexpected entitlements (-want +got):\n%s", diff)
+	}
+
+	PublicKeys = "not base64"
+	if _, err := Verify(lic); err == nil {
+		t.Error("expected an error for invalid build time keys")
+	}
+}
+
+func publicKeyPEM(t *testing.T, key *rsa.PrivateKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestEmbeddedKeys(t *testing.T) {
+	// The keys are only added by their owner, see keys/README.md, so there may be none
+	if _, err := embeddedKeys(); err != nil {
+		t.Fatal(err)
+	}
+}
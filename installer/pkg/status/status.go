@@ -0,0 +1,412 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	agentsmith "github.com/bhojpur/platform/installer/pkg/components/agent-smith"
+	"github.com/bhojpur/platform/installer/pkg/components/bhojpur"
+	"github.com/bhojpur/platform/installer/pkg/components/blobserve"
+	contentservice "github.com/bhojpur/platform/installer/pkg/components/content-service"
+	"github.com/bhojpur/platform/installer/pkg/components/dashboard"
+	"github.com/bhojpur/platform/installer/pkg/config/versions"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Options configure how much detail is collected
+type Options struct {
+	// LogLines is the number of log lines collected from each failing pod
+	LogLines int64
+	// RestartWindow is how long ago a restart counts as recent
+	RestartWindow time.Duration
+}
+
+type Report struct {
+	Namespace  string            `json:"namespace"`
+	Version    string            `json:"version,omitempty"`
+	Healthy    bool              `json:"healthy"`
+	Components []ComponentStatus `json:"components"`
+}
+
+type ComponentStatus struct {
+	Component string `json:"component"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	// Missing is set if the installed workload no longer exists in the cluster
+	Missing        bool          `json:"missing,omitempty"`
+	Desired        int32         `json:"desired"`
+	Ready          int32         `json:"ready"`
+	Images         []ImageStatus `json:"images"`
+	RecentRestarts int32         `json:"recentRestarts"`
+	FailingPods    []PodStatus   `json:"failingPods,omitempty"`
+}
+
+type ImageStatus struct {
+	Container string `json:"container"`
+	// Expected is the image of the installed version
+	Expected string `json:"expected"`
+	// Running are the images of the workload and its pods, if they differ from the expected one
+	Running []string `json:"running,omitempty"`
+}
+
+type PodStatus struct {
+	Name      string   `json:"name"`
+	Container string   `json:"container,omitempty"`
+	Reason    string   `json:"reason"`
+	Logs      []string `json:"logs,omitempty"`
+}
+
+// Healthy is true if all replicas are ready, run the installed images and no pod is failing
+func (c *ComponentStatus) Healthy() bool {
+	if c.Missing || c.Ready < c.Desired || len(c.FailingPods) > 0 {
+		return false
+	}
+	for _, i := range c.Images {
+		if len(i.Running) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Get reports the status of every workload listed in the installation ConfigMap. The
+// ConfigMap only lists the objects, so the workloads are read from the cluster.
+func Get(ctx context.Context, client kubernetes.Interface, namespace string, opts Options) (*Report, error) {
+	cfgMap, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, common.InstallationConfigMap, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the installation config map %s - is the Bhojpur.NET Platform installed in namespace %s? %w", common.InstallationConfigMap, namespace, err)
+	}
+
+	objects, err := common.YamlToRuntimeObject([]string{cfgMap.Data["app.yaml"]})
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the installation config map: %w", err)
+	}
+
+	manifest, err := installedManifest(ctx, client, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Namespace: namespace,
+		Healthy:   true,
+	}
+	if manifest != nil {
+		report.Version = manifest.Version
+	}
+	images := manifestImages(manifest)
+
+	for _, o := range objects {
+		if o.Metadata.Namespace != "" && o.Metadata.Namespace != namespace {
+			continue
+		}
+		switch o.Kind {
+		case common.TypeMetaDeployment.Kind, common.TypeMetaStatefulSet.Kind, common.TypeMetaDaemonset.Kind:
+		default:
+			continue
+		}
+
+		status, err := workloadStatus(ctx, client, namespace, o.Kind, o.Metadata.Name, images, opts)
+		if err != nil {
+			return nil, err
+		}
+		if !status.Healthy() {
+			report.Healthy = false
+		}
+		report.Components = append(report.Components, *status)
+	}
+
+	sort.SliceStable(report.Components, func(i, j int) bool {
+		return report.Components[i].Component < report.Components[j].Component
+	})
+
+	return report, nil
+}
+
+// installedManifest reads the version manifest from the bhojpur ConfigMap. It's nil if
+// there is none, eg on a meta cluster.
+func installedManifest(ctx context.Context, client kubernetes.Interface, namespace string) (*versions.Manifest, error) {
+	cfgMap, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, bhojpur.Component, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content := cfgMap.Data["config.json"]
+	if strings.TrimSpace(content) == "" {
+		return nil, nil
+	}
+	var cfg bhojpur.Bhojpur
+	if err := json.Unmarshal([]byte(content), &cfg); err != nil {
+		return nil, fmt.Errorf("cannot parse the installed version manifest: %w", err)
+	}
+	return &cfg.VersionManifest, nil
+}
+
+// manifestImages are the versions of the manifest by the name of the image they apply to
+func manifestImages(manifest *versions.Manifest) map[string]string {
+	if manifest == nil {
+		return nil
+	}
+
+	c := manifest.Components
+	return map[string]string{
+		agentsmith.Component:            c.AgentSmith.Version,
+		blobserve.Component:             c.Blobserve.Version,
+		"ca-updater":                    c.CAUpdater.Version,
+		contentservice.Component:        c.ContentService.Version,
+		dashboard.Component:             c.Dashboard.Version,
+		"ide-proxy":                     c.IDEProxy.Version,
+		"image-builder-mk3":             c.ImageBuilderMk3.Version,
+		"openvsx-proxy":                 c.OpenVSXProxy.Version,
+		common.ProxyComponent:           c.Proxy.Version,
+		common.RegistryFacadeComponent:  c.RegistryFacade.Version,
+		common.ServerComponent:          c.Server.Version,
+		"service-waiter":                c.ServiceWaiter.Version,
+		"bp-daemon":                     c.WSDaemon.Version,
+		common.WSManagerComponent:       c.WSManager.Version,
+		common.WSManagerBridgeComponent: c.WSManagerBridge.Version,
+		common.WSProxyComponent:         c.WSProxy.Version,
+		common.WSSchedulerComponent:     c.WSScheduler.Version,
+	}
+}
+
+func workloadStatus(ctx context.Context, client kubernetes.Interface, namespace, kind, name string, images map[string]string, opts Options) (*ComponentStatus, error) {
+	status := &ComponentStatus{
+		Kind: kind,
+		Name: name,
+	}
+
+	var (
+		meta     metav1.ObjectMeta
+		template corev1.PodTemplateSpec
+		selector *metav1.LabelSelector
+		err      error
+	)
+	switch kind {
+	case common.TypeMetaDeployment.Kind:
+		var obj *appsv1.Deployment
+		obj, err = client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			status.Desired = 1
+			if obj.Spec.Replicas != nil {
+				status.Desired = *obj.Spec.Replicas
+			}
+			status.Ready = obj.Status.ReadyReplicas
+			meta, template, selector = obj.ObjectMeta, obj.Spec.Template, obj.Spec.Selector
+		}
+	case common.TypeMetaStatefulSet.Kind:
+		var obj *appsv1.StatefulSet
+		obj, err = client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			status.Desired = 1
+			if obj.Spec.Replicas != nil {
+				status.Desired = *obj.Spec.Replicas
+			}
+			status.Ready = obj.Status.ReadyReplicas
+			meta, template, selector = obj.ObjectMeta, obj.Spec.Template, obj.Spec.Selector
+		}
+	case common.TypeMetaDaemonset.Kind:
+		var obj *appsv1.DaemonSet
+		obj, err = client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			status.Desired = obj.Status.DesiredNumberScheduled
+			status.Ready = obj.Status.NumberReady
+			meta, template, selector = obj.ObjectMeta, obj.Spec.Template, obj.Spec.Selector
+		}
+	}
+	status.Component = meta.Labels["component"]
+	if status.Component == "" {
+		// Workloads from third-party Helm charts don't carry our labels
+		status.Component = name
+	}
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			status.Missing = true
+			return status, nil
+		}
+		return nil, err
+	}
+
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	status.Images = imageStatus(template.Spec, pods.Items, images)
+	status.RecentRestarts = recentRestarts(pods.Items, time.Now().Add(-opts.RestartWindow))
+
+	for _, pod := range pods.Items {
+		failing, ok := failingPod(&pod)
+		if !ok {
+			continue
+		}
+		if opts.LogLines > 0 && failing.Container != "" {
+			failing.Logs = lastLogLines(ctx, client, &pod, failing.Container, opts.LogLines)
+		}
+		status.FailingPods = append(status.FailingPods, failing)
+	}
+
+	return status, nil
+}
+
+func allContainers(spec corev1.PodSpec) []corev1.Container {
+	res := make([]corev1.Container, 0, len(spec.InitContainers)+len(spec.Containers))
+	res = append(res, spec.InitContainers...)
+	return append(res, spec.Containers...)
+}
+
+func allContainerStatuses(pod *corev1.Pod) []corev1.ContainerStatus {
+	res := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	res = append(res, pod.Status.InitContainerStatuses...)
+	return append(res, pod.Status.ContainerStatuses...)
+}
+
+// imageStatus compares the images of the installed version with the images of the
+// workload and its pods. Images which are not part of the version manifest, eg of
+// third-party charts, are expected to be the ones of the workload.
+func imageStatus(spec corev1.PodSpec, pods []corev1.Pod, images map[string]string) []ImageStatus {
+	var res []ImageStatus
+	for _, c := range allContainers(spec) {
+		img := ImageStatus{
+			Container: c.Name,
+			Expected:  expectedImage(c.Image, images),
+		}
+
+		running := make(map[string]struct{})
+		if c.Image != img.Expected {
+			// The workload itself was not updated
+			running[c.Image] = struct{}{}
+		}
+		for _, pod := range pods {
+			for _, pc := range allContainers(pod.Spec) {
+				if pc.Name == c.Name && pc.Image != img.Expected {
+					running[pc.Image] = struct{}{}
+				}
+			}
+		}
+		for i := range running {
+			img.Running = append(img.Running, i)
+		}
+		sort.Strings(img.Running)
+
+		res = append(res, img)
+	}
+	return res
+}
+
+// expectedImage replaces the tag of the image with its version in the manifest. The
+// repository is kept, as it's configurable.
+func expectedImage(image string, images map[string]string) string {
+	repo := image
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo = repo[:i]
+	}
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+
+	version := images[repo[strings.LastIndex(repo, "/")+1:]]
+	if version == "" {
+		return image
+	}
+	return repo + ":" + version
+}
+
+// recentRestarts counts the containers which were last restarted after since
+func recentRestarts(pods []corev1.Pod, since time.Time) (res int32) {
+	for _, pod := range pods {
+		for _, cs := range allContainerStatuses(&pod) {
+			term := cs.LastTerminationState.Terminated
+			if cs.RestartCount > 0 && term != nil && term.FinishedAt.After(since) {
+				res++
+			}
+		}
+	}
+	return
+}
+
+// failingPod returns the reason a pod is failing and the container at fault, if any
+func failingPod(pod *corev1.Pod) (PodStatus, bool) {
+	res := PodStatus{Name: pod.Name}
+
+	if pod.Status.Phase == corev1.PodFailed {
+		res.Reason = pod.Status.Reason
+		if res.Reason == "" {
+			res.Reason = string(corev1.PodFailed)
+		}
+		return res, true
+	}
+
+	for _, cs := range allContainerStatuses(pod) {
+		if cs.State.Waiting != nil {
+			switch cs.State.Waiting.Reason {
+			case "", "ContainerCreating", "PodInitializing":
+				// The pod is starting up
+				continue
+			}
+			res.Container = cs.Name
+			res.Reason = cs.State.Waiting.Reason
+			return res, true
+		}
+		if cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0 {
+			res.Container = cs.Name
+			res.Reason = fmt.Sprintf("%s (exit code %d)", cs.State.Terminated.Reason, cs.State.Terminated.ExitCode)
+			return res, true
+		}
+	}
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
+			res.Reason = cond.Reason
+			return res, true
+		}
+	}
+
+	return res, false
+}
+
+// lastLogLines reads the logs of the previous container if it crashed, as the
+// current one may not have logged anything yet
+func lastLogLines(ctx context.Context, client kubernetes.Interface, pod *corev1.Pod, container string, lines int64) []string {
+	previous := false
+	for _, cs := range allContainerStatuses(pod) {
+		if cs.Name == container && cs.RestartCount > 0 && cs.State.Running == nil {
+			previous = true
+		}
+	}
+
+	raw, err := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &lines,
+		Previous:  previous,
+	}).DoRaw(ctx)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read logs: %v", err)}
+	}
+
+	out := strings.TrimRight(string(raw), "\n")
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
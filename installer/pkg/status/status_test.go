@@ -0,0 +1,256 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	"github.com/bhojpur/platform/installer/pkg/components/bhojpur"
+	"github.com/bhojpur/platform/installer/pkg/components/dashboard"
+	"github.com/bhojpur/platform/installer/pkg/config/versions"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	namespace = "bhojpur"
+	repo      = "registry.example.com/bhojpur"
+)
+
+func deployment(component, image string) *appsv1.Deployment {
+	labels := common.DefaultLabels(component)
+	return &appsv1.Deployment{
+		TypeMeta: common.TypeMetaDeployment,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      component,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(1),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: component, Image: image}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: 1},
+	}
+}
+
+func pod(d *appsv1.Deployment, image string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      d.Name + "-abc",
+			Namespace: namespace,
+			Labels:    d.Spec.Template.Labels,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: d.Spec.Template.Spec.Containers[0].Name, Image: image}},
+		},
+	}
+}
+
+// installationConfigMap renders the installation config map like the render command does
+func installationConfigMap(t *testing.T, objs ...runtime.Object) *corev1.ConfigMap {
+	var docs []string
+	for _, o := range objs {
+		fc, err := yaml.Marshal(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs = append(docs, fmt.Sprintf("---\n%s\n", string(fc)))
+	}
+	runtimeObjs, err := common.YamlToRuntimeObject(docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := common.GenerateInstallationConfigMap(&common.RenderContext{Namespace: namespace}, runtimeObjs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfgMap corev1.ConfigMap
+	if err := yaml.Unmarshal([]byte(rendered[len(rendered)-1].Content), &cfgMap); err != nil {
+		t.Fatal(err)
+	}
+	return &cfgMap
+}
+
+func versionConfigMap(content string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: bhojpur.Component, Namespace: namespace},
+		Data:       map[string]string{"config.json": content},
+	}
+}
+
+func versionManifest(t *testing.T, version string) string {
+	var manifest versions.Manifest
+	manifest.Version = version
+	manifest.Components.Dashboard.Version = version
+	manifest.Components.Server.Version = version
+
+	fc, err := json.Marshal(bhojpur.Bhojpur{VersionManifest: manifest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(fc)
+}
+
+func TestGet(t *testing.T) {
+	var (
+		dashboardNew = repo + "/" + dashboard.Component + ":v2"
+		dashboardOld = repo + "/" + dashboard.Component + ":v1"
+		redis        = "docker.io/library/redis:6.2"
+	)
+
+	type Expectation struct {
+		Version    string
+		Healthy    bool
+		Components map[string][]ImageStatus
+		Missing    []string
+	}
+	tests := []struct {
+		Name        string
+		Installed   []runtime.Object
+		Live        []runtime.Object
+		Expectation Expectation
+	}{
+		{
+			Name:      "up to date",
+			Installed: []runtime.Object{deployment(dashboard.Component, dashboardNew), deployment("redis", redis)},
+			Live: []runtime.Object{
+				versionConfigMap(versionManifest(t, "v2")),
+				deployment(dashboard.Component, dashboardNew),
+				pod(deployment(dashboard.Component, dashboardNew), dashboardNew),
+				deployment("redis", redis),
+				pod(deployment("redis", redis), redis),
+			},
+			Expectation: Expectation{
+				Version: "v2",
+				Healthy: true,
+				Components: map[string][]ImageStatus{
+					dashboard.Component: {{Container: dashboard.Component, Expected: dashboardNew}},
+					"redis":             {{Container: "redis", Expected: redis}},
+				},
+			},
+		},
+		{
+			Name:      "pod runs an old version",
+			Installed: []runtime.Object{deployment(dashboard.Component, dashboardNew)},
+			Live: []runtime.Object{
+				versionConfigMap(versionManifest(t, "v2")),
+				deployment(dashboard.Component, dashboardNew),
+				pod(deployment(dashboard.Component, dashboardNew), dashboardOld),
+			},
+			Expectation: Expectation{
+				Version: "v2",
+				Components: map[string][]ImageStatus{
+					dashboard.Component: {{Container: dashboard.Component, Expected: dashboardNew, Running: []string{dashboardOld}}},
+				},
+			},
+		},
+		{
+			Name:      "workload was not updated",
+			Installed: []runtime.Object{deployment(dashboard.Component, dashboardNew)},
+			Live: []runtime.Object{
+				versionConfigMap(versionManifest(t, "v2")),
+				deployment(dashboard.Component, dashboardOld),
+				pod(deployment(dashboard.Component, dashboardOld), dashboardOld),
+			},
+			Expectation: Expectation{
+				Version: "v2",
+				Components: map[string][]ImageStatus{
+					dashboard.Component: {{Container: dashboard.Component, Expected: dashboardNew, Running: []string{dashboardOld}}},
+				},
+			},
+		},
+		{
+			Name:      "missing workload",
+			Installed: []runtime.Object{deployment(dashboard.Component, dashboardNew)},
+			Live:      []runtime.Object{versionConfigMap(versionManifest(t, "v2"))},
+			Expectation: Expectation{
+				Version:    "v2",
+				Components: map[string][]ImageStatus{dashboard.Component: nil},
+				Missing:    []string{dashboard.Component},
+			},
+		},
+		{
+			Name:      "no version manifest",
+			Installed: []runtime.Object{deployment(dashboard.Component, dashboardOld)},
+			Live: []runtime.Object{
+				versionConfigMap(""),
+				deployment(dashboard.Component, dashboardOld),
+				pod(deployment(dashboard.Component, dashboardOld), dashboardOld),
+			},
+			Expectation: Expectation{
+				Healthy: true,
+				Components: map[string][]ImageStatus{
+					dashboard.Component: {{Container: dashboard.Component, Expected: dashboardOld}},
+				},
+			},
+		},
+		{
+			Name:      "no version config map",
+			Installed: []runtime.Object{deployment(dashboard.Component, dashboardOld)},
+			Live: []runtime.Object{
+				deployment(dashboard.Component, dashboardOld),
+				pod(deployment(dashboard.Component, dashboardNew), dashboardNew),
+			},
+			Expectation: Expectation{
+				Components: map[string][]ImageStatus{
+					dashboard.Component: {{Container: dashboard.Component, Expected: dashboardOld, Running: []string{dashboardNew}}},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(append(test.Live, installationConfigMap(t, test.Installed...))...)
+
+			report, err := Get(context.Background(), client, namespace, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			act := Expectation{
+				Version:    report.Version,
+				Healthy:    report.Healthy,
+				Components: make(map[string][]ImageStatus),
+			}
+			for _, c := range report.Components {
+				act.Components[c.Name] = c.Images
+				if c.Missing {
+					act.Missing = append(act.Missing, c.Name)
+				}
+			}
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("Get() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetInvalidVersionManifest(t *testing.T) {
+	client := fake.NewSimpleClientset(installationConfigMap(t), versionConfigMap("{"))
+
+	_, err := Get(context.Background(), client, namespace, Options{})
+	if err == nil {
+		t.Fatal("expected an error for an invalid version manifest")
+	}
+}
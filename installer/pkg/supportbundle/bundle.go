@@ -0,0 +1,82 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supportbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Writer writes the files of a support bundle to a gzipped tar archive. Failing
+// to collect a part does not fail the bundle, it is listed in errors.txt instead.
+type Writer struct {
+	root   string
+	gz     *gzip.Writer
+	tw     *tar.Writer
+	now    time.Time
+	errors []string
+}
+
+// NewWriter creates a bundle whose files are placed in the root directory
+func NewWriter(out io.Writer, root string) *Writer {
+	gz := gzip.NewWriter(out)
+	return &Writer{
+		root: root,
+		gz:   gz,
+		tw:   tar.NewWriter(gz),
+		now:  time.Now(),
+	}
+}
+
+// Add adds a file to the bundle
+func (w *Writer) Add(name string, content []byte) error {
+	err := w.tw.WriteHeader(&tar.Header{
+		Name:    path.Join(w.root, name),
+		Mode:    0600,
+		Size:    int64(len(content)),
+		ModTime: w.now,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.tw.Write(content)
+	return err
+}
+
+// AddYAML adds obj to the bundle as YAML
+func (w *Writer) AddYAML(name string, obj interface{}) error {
+	fc, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return w.Add(name, fc)
+}
+
+// AddError records that a part of the bundle could not be collected
+func (w *Writer) AddError(part string, err error) {
+	w.errors = append(w.errors, fmt.Sprintf("%s: %v", part, err))
+}
+
+// Close writes errors.txt and flushes the archive
+func (w *Writer) Close() error {
+	if len(w.errors) > 0 {
+		err := w.Add("errors.txt", []byte(strings.Join(w.errors, "\n")+"\n"))
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
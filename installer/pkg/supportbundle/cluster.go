@@ -0,0 +1,219 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supportbundle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	"github.com/bhojpur/platform/installer/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// podStatus leaves out the pod spec, as the environment may contain secrets
+type podStatus struct {
+	Name     string            `json:"name"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	NodeName string            `json:"nodeName,omitempty"`
+	Status   corev1.PodStatus  `json:"status"`
+}
+
+// CollectCluster adds the installed objects, the pods, the events and the last log
+// lines of every container of the Bhojpur.NET Platform to the bundle
+func CollectCluster(ctx context.Context, client kubernetes.Interface, dyn dynamic.Interface, mapper meta.RESTMapper, namespace string, logLines int64, w *Writer) error {
+	cfgMap, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, common.InstallationConfigMap, metav1.GetOptions{})
+	if err != nil {
+		w.AddError("installation", err)
+	} else {
+		installation, err := redactInstallation(ctx, dyn, mapper, namespace, cfgMap.Data["app.yaml"], w)
+		if err != nil {
+			w.AddError("installation", err)
+		} else if err := w.Add("installation.yaml", []byte(installation)); err != nil {
+			return err
+		}
+	}
+
+	events, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		w.AddError("events", err)
+	} else if err := w.AddYAML("events.yaml", events.Items); err != nil {
+		return err
+	}
+
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s", common.AppName),
+	})
+	if err != nil {
+		w.AddError("pods", err)
+		return nil
+	}
+
+	statuses := make([]podStatus, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		statuses = append(statuses, podStatus{
+			Name:     pod.Name,
+			Labels:   pod.Labels,
+			NodeName: pod.Spec.NodeName,
+			Status:   pod.Status,
+		})
+	}
+	if err := w.AddYAML("pods.yaml", statuses); err != nil {
+		return err
+	}
+
+	for _, pod := range pods.Items {
+		containers := make([]corev1.Container, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+		containers = append(containers, pod.Spec.InitContainers...)
+		containers = append(containers, pod.Spec.Containers...)
+
+		for _, c := range containers {
+			part := path.Join("logs", pod.Name, c.Name+".log")
+			logs, err := client.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+				Container: c.Name,
+				TailLines: &logLines,
+			}).DoRaw(ctx)
+			if err != nil {
+				w.AddError(part, err)
+				continue
+			}
+			if err := w.Add(part, logs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// redactEnv replaces the values of environment variables whose name suggests a secret
+func redactEnv(values interface{}) {
+	switch v := values.(type) {
+	case map[string]interface{}:
+		name, _ := v["name"].(string)
+		if _, ok := v["value"].(string); ok && config.IsSecretKey(name) {
+			v["value"] = config.Redacted
+		}
+		for _, val := range v {
+			redactEnv(val)
+		}
+	case []interface{}:
+		for _, val := range v {
+			redactEnv(val)
+		}
+	}
+}
+
+// redactInstallation reads the objects listed in the installation config map from the
+// cluster, as the config map only stores their metadata. It replaces the values of every
+// secret, the secret values of the JSON files in config maps and secret environment
+// variables. Objects which cannot be read are recorded as errors.
+func redactInstallation(ctx context.Context, dyn dynamic.Interface, mapper meta.RESTMapper, namespace string, installation string, w *Writer) (string, error) {
+	objects, err := common.YamlToRuntimeObject([]string{installation})
+	if err != nil {
+		return "", err
+	}
+
+	res := make([]string, 0, len(objects))
+	for _, o := range objects {
+		if o.Kind == "" {
+			continue
+		}
+		part := path.Join("installation", strings.ToLower(o.Kind), o.Metadata.Name)
+
+		obj, err := getObject(ctx, dyn, mapper, namespace, o)
+		if err != nil {
+			w.AddError(part, err)
+			continue
+		}
+		if err := redactObject(obj); err != nil {
+			w.AddError(part, err)
+			continue
+		}
+
+		fc, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return "", err
+		}
+		res = append(res, strings.TrimSpace(string(fc)))
+	}
+
+	return strings.Join(res, "\n---\n") + "\n", nil
+}
+
+func getObject(ctx context.Context, dyn dynamic.Interface, mapper meta.RESTMapper, namespace string, o common.RuntimeObject) (*unstructured.Unstructured, error) {
+	gvk := schema.FromAPIVersionAndKind(o.APIVersion, o.Kind)
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return dyn.Resource(mapping.Resource).Get(ctx, o.Metadata.Name, metav1.GetOptions{})
+	}
+	if o.Metadata.Namespace != "" {
+		namespace = o.Metadata.Namespace
+	}
+	return dyn.Resource(mapping.Resource).Namespace(namespace).Get(ctx, o.Metadata.Name, metav1.GetOptions{})
+}
+
+// redactObject redacts obj in place. The annotation kubectl keeps the last applied
+// object in and the managed fields are removed, as they'd contain the secret values.
+func redactObject(obj *unstructured.Unstructured) error {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; ok {
+		delete(annotations, corev1.LastAppliedConfigAnnotation)
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		obj.SetAnnotations(annotations)
+	}
+	obj.SetManagedFields(nil)
+
+	switch obj.GetKind() {
+	case common.TypeMetaSecret.Kind:
+		for _, field := range []string{"data", "stringData"} {
+			data, ok := obj.Object[field].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for k := range data {
+				data[k] = config.Redacted
+			}
+		}
+	case common.TypeMetaConfigmap.Kind:
+		data, ok := obj.Object["data"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for k, v := range data {
+			var values interface{}
+			if str, ok := v.(string); !ok || json.Unmarshal([]byte(str), &values) != nil {
+				// Only JSON files are redacted
+				continue
+			}
+			config.RedactValues(values)
+			fc, err := json.MarshalIndent(values, "", " ")
+			if err != nil {
+				return err
+			}
+			data[k] = string(fc)
+		}
+	default:
+		redactEnv(obj.Object)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supportbundle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bhojpur/platform/installer/pkg/common"
+	"github.com/bhojpur/platform/installer/pkg/config"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/yaml"
+)
+
+const secretValue = "hunter2"
+
+func TestRedactInstallation(t *testing.T) {
+	installed := []runtime.Object{
+		&corev1.Secret{
+			TypeMeta: common.TypeMetaSecret,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "database",
+				Namespace: "default",
+				Annotations: map[string]string{
+					corev1.LastAppliedConfigAnnotation: fmt.Sprintf(`{"stringData":{"password":%q}}`, secretValue),
+				},
+			},
+			StringData: map[string]string{"password": secretValue},
+		},
+		&corev1.ConfigMap{
+			TypeMeta:   common.TypeMetaConfigmap,
+			ObjectMeta: metav1.ObjectMeta{Name: "server", Namespace: "default"},
+			Data: map[string]string{
+				"config.json": fmt.Sprintf(`{"hostUrl":"https://example.com","session":{"secret":%q}}`, secretValue),
+				"motd":        "hello",
+			},
+		},
+		&appsv1.Deployment{
+			TypeMeta:   common.TypeMetaDeployment,
+			ObjectMeta: metav1.ObjectMeta{Name: "server", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name: "server",
+							Env: []corev1.EnvVar{
+								{Name: "DB_PASSWORD", Value: secretValue},
+								{Name: "LOG_LEVEL", Value: "info"},
+							},
+						}},
+					},
+				},
+			},
+		},
+	}
+	missing := &corev1.Service{
+		TypeMeta:   common.TypeMetaService,
+		ObjectMeta: metav1.ObjectMeta{Name: "proxy", Namespace: "default"},
+	}
+
+	// The installation config map only stores the metadata of the objects
+	var installation []string
+	var live []runtime.Object
+	for _, o := range append(installed, missing) {
+		fc, err := yaml.Marshal(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		objs, err := common.YamlToRuntimeObject([]string{string(fc)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fc, err = yaml.Marshal(objs[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		installation = append(installation, string(fc))
+
+		if o == runtime.Object(missing) {
+			continue
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		live = append(live, &unstructured.Unstructured{Object: content})
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, tm := range []metav1.TypeMeta{common.TypeMetaSecret, common.TypeMetaConfigmap, common.TypeMetaDeployment, common.TypeMetaService} {
+		mapper.Add(tm.GroupVersionKind(), meta.RESTScopeNamespace)
+	}
+	dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), live...)
+	w := NewWriter(io.Discard, "bundle")
+
+	res, err := redactInstallation(context.Background(), dyn, mapper, "default", strings.Join(installation, "---\n"), w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(res, secretValue) {
+		t.Errorf("redactInstallation() leaks a secret value:\n%s", res)
+	}
+	for _, expected := range []string{"LOG_LEVEL", "https://example.com", "motd: hello", "password: '" + config.Redacted + "'"} {
+		if !strings.Contains(res, expected) {
+			t.Errorf("redactInstallation() is missing %q:\n%s", expected, res)
+		}
+	}
+	if len(strings.Split(res, "\n---\n")) != len(installed) {
+		t.Errorf("redactInstallation() returned unexpected objects:\n%s", res)
+	}
+
+	var errs []string
+	for _, e := range w.errors {
+		errs = append(errs, strings.SplitN(e, ":", 2)[0])
+	}
+	if diff := cmp.Diff([]string{"installation/service/proxy"}, errs); diff != "" {
+		t.Errorf("redactInstallation() errors mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -5,7 +5,10 @@
 package charts
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
+	"fmt"
 	"io/fs"
 	"io/ioutil"
 	"os"
@@ -28,6 +31,49 @@ type Chart struct {
 	AdditionalFiles []string
 }
 
+// All returns the embedded charts
+func All() []*Chart {
+	return []*Chart{
+		DockerRegistry(),
+		JaegerOperator(),
+		Minio(),
+		MySQL(),
+		RabbitMQ(),
+	}
+}
+
+// Key is the name of the chart in the installer config
+func (c *Chart) Key() string {
+	return strings.TrimSuffix(c.Location, "/")
+}
+
+// Digest is the sha256 of the chart content, including the vendored dependencies
+func (c *Chart) Digest() (string, error) {
+	h := sha256.New()
+	err := fs.WalkDir(c.Content, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasPrefix(path, c.Location) {
+			return nil
+		}
+
+		fc, err := c.Content.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", path, len(fc))
+		h.Write(fc)
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // Export writes the content of the chart to the dest location
 func (c *Chart) Export(dest string) error {
 	return fs.WalkDir(c.Content, ".", func(path string, d fs.DirEntry, err error) error {
@@ -0,0 +1,25 @@
+#!/bin/bash
+# Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+# Licensed under the GNU Affero General Public License (AGPL).
+# See License-AGPL.txt in the project root for license information.
+
+# Vendors the dependencies of the embedded charts into their charts/ directory at
+# the versions pinned in Chart.lock, so that rendering never needs network access.
+# Run with --update after changing a dependency in Chart.yaml to rewrite the lock.
+
+set -euo pipefail
+
+cd "$(dirname "$0")"
+
+cmd=build
+if [ "${1:-}" = "--update" ]; then
+  cmd=update
+fi
+
+for chart in */; do
+  # Skip the charts which already have every dependency vendored
+  if [ "$cmd" = build ] && [ -z "$(helm dependency list "$chart" | awk 'NR > 1 && NF && $NF != "ok"')" ]; then
+    continue
+  fi
+  helm dependency "$cmd" "$chart"
+done